@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"encoding/base64"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// A Session is a wallet that has been unlocked with its password. The session
+// is identified by the id of the wallet, and does not expire when the expiry
+// is zero.
+type Session struct {
+	ID         string `json:"id"`
+	UnlockedAt int64  `json:"unlockedAt"`
+	Expiry     int64  `json:"expiry,omitempty"`
+
+	password string
+}
+
+// Expired returns true if the session has expired at the given unix time.
+func (session Session) Expired(now int64) bool {
+	return session.Expiry != 0 && now >= session.Expiry
+}
+
+// Sessions hold the passwords of the unlocked wallets in memory, and the
+// wallets that the API tokens were created for. It is safe for concurrent
+// use.
+type Sessions struct {
+	mu         *sync.RWMutex
+	sessions   map[string]Session
+	tokens     map[string]string
+	bootloaded map[string]bool
+}
+
+// NewSessions returns an empty session store.
+func NewSessions() *Sessions {
+	return &Sessions{
+		mu:         new(sync.RWMutex),
+		sessions:   map[string]Session{},
+		tokens:     map[string]string{},
+		bootloaded: map[string]bool{},
+	}
+}
+
+// Unlock starts, or renews, the session of the wallet of the password.
+func (sessions *Sessions) Unlock(id, password string, unlockedAt, expiry int64) Session {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	key := sessionKey(password)
+	session := Session{
+		ID:         id,
+		UnlockedAt: unlockedAt,
+		Expiry:     expiry,
+		password:   password,
+	}
+	sessions.sessions[key] = session
+	sessions.bootloaded[key] = true
+	return session
+}
+
+// Bootloaded returns true if the wallet of the password has been unlocked
+// since the store was created, even if it has been locked since.
+func (sessions *Sessions) Bootloaded(password string) bool {
+	sessions.mu.RLock()
+	defer sessions.mu.RUnlock()
+
+	return sessions.bootloaded[sessionKey(password)]
+}
+
+// Lock ends the session of the wallet of the password. It returns false if
+// the wallet was not unlocked.
+func (sessions *Sessions) Lock(password string) bool {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	key := sessionKey(password)
+	if _, ok := sessions.sessions[key]; !ok {
+		return false
+	}
+	delete(sessions.sessions, key)
+	return true
+}
+
+// Unlocked returns true if the wallet of the password has a session that has
+// not expired at the given unix time.
+func (sessions *Sessions) Unlocked(password string, now int64) bool {
+	_, ok := sessions.session(sessionKey(password), now)
+	return ok
+}
+
+// AddToken links the API token with the given hash to the wallet of the
+// password.
+func (sessions *Sessions) AddToken(hash, password string) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	sessions.tokens[hash] = sessionKey(password)
+}
+
+// RemoveToken unlinks the API token with the given hash from its wallet.
+func (sessions *Sessions) RemoveToken(hash string) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	delete(sessions.tokens, hash)
+}
+
+// TokenPassword returns the password of the wallet of the API token with the
+// given hash, if the wallet is unlocked at the given unix time.
+func (sessions *Sessions) TokenPassword(hash string, now int64) (string, bool) {
+	sessions.mu.RLock()
+	key, ok := sessions.tokens[hash]
+	sessions.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	session, ok := sessions.session(key, now)
+	if !ok {
+		return "", false
+	}
+	return session.password, true
+}
+
+// Sessions returns the sessions that have not expired at the given unix time,
+// ordered by the time they were unlocked.
+func (sessions *Sessions) Sessions(now int64) []Session {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	unlocked := []Session{}
+	for key, session := range sessions.sessions {
+		if session.Expired(now) {
+			delete(sessions.sessions, key)
+			continue
+		}
+		unlocked = append(unlocked, session)
+	}
+	sort.Slice(unlocked, func(i, j int) bool {
+		return unlocked[i].UnlockedAt < unlocked[j].UnlockedAt
+	})
+	return unlocked
+}
+
+func (sessions *Sessions) session(key string, now int64) (Session, bool) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	session, ok := sessions.sessions[key]
+	if !ok {
+		return Session{}, false
+	}
+	if session.Expired(now) {
+		delete(sessions.sessions, key)
+		return Session{}, false
+	}
+	return session, true
+}
+
+func sessionKey(password string) string {
+	hash := sha3.Sum256([]byte(password))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
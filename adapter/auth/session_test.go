@@ -0,0 +1,54 @@
+package auth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/auth"
+)
+
+var _ = Describe("Sessions", func() {
+	It("should only return the password of a token while its wallet is unlocked", func() {
+		sessions := NewSessions()
+		sessions.AddToken("hash", "password")
+		_, ok := sessions.TokenPassword("hash", 100)
+		Expect(ok).Should(BeFalse())
+
+		sessions.Unlock("id", "password", 100, 0)
+		password, ok := sessions.TokenPassword("hash", 100)
+		Expect(ok).Should(BeTrue())
+		Expect(password).Should(Equal("password"))
+
+		Expect(sessions.Lock("password")).Should(BeTrue())
+		_, ok = sessions.TokenPassword("hash", 100)
+		Expect(ok).Should(BeFalse())
+		Expect(sessions.Lock("password")).Should(BeFalse())
+	})
+
+	It("should expire sessions", func() {
+		sessions := NewSessions()
+		sessions.Unlock("id", "password", 100, 160)
+		Expect(sessions.Unlocked("password", 159)).Should(BeTrue())
+		Expect(sessions.Sessions(159)).Should(HaveLen(1))
+		Expect(sessions.Unlocked("password", 160)).Should(BeFalse())
+		Expect(sessions.Sessions(160)).Should(BeEmpty())
+	})
+
+	It("should remember the wallets that were bootloaded after they are locked", func() {
+		sessions := NewSessions()
+		Expect(sessions.Bootloaded("password")).Should(BeFalse())
+		sessions.Unlock("id", "password", 100, 0)
+		sessions.Lock("password")
+		Expect(sessions.Bootloaded("password")).Should(BeTrue())
+		Expect(sessions.Bootloaded("other")).Should(BeFalse())
+	})
+
+	It("should list the sessions in the order they were unlocked", func() {
+		sessions := NewSessions()
+		sessions.Unlock("b", "second", 200, 0)
+		sessions.Unlock("a", "first", 100, 0)
+		unlocked := sessions.Sessions(300)
+		Expect(unlocked).Should(HaveLen(2))
+		Expect(unlocked[0].ID).Should(Equal("a"))
+		Expect(unlocked[1].ID).Should(Equal("b"))
+	})
+})
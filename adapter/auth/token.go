@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+// A Scope is a group of requests that an API token can be allowed to make.
+type Scope string
+
+const (
+	ScopeRead     = Scope("read")
+	ScopeSwap     = Scope("swap")
+	ScopeTransfer = Scope("transfer")
+	ScopeSign     = Scope("sign")
+)
+
+var ErrInvalidToken = fmt.Errorf("invalid api token")
+var ErrWalletLocked = fmt.Errorf("wallet of the api token is locked, use the wallet password to unlock it")
+
+// ErrScopeNotAllowed is returned when an API token is used for a request that
+// its scopes do not allow.
+type ErrScopeNotAllowed Scope
+
+func (err ErrScopeNotAllowed) Error() string {
+	return fmt.Sprintf("api token is not allowed the %s scope", string(err))
+}
+
+// A Token grants access to the wallet of the password it was created with,
+// without knowing the password. Only the hash of the token's secret, and the
+// bcrypt hash of the password, are stored.
+type Token struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Scopes       []Scope `json:"scopes"`
+	CreatedAt    int64   `json:"createdAt"`
+	Hash         string  `json:"hash,omitempty"`
+	PasswordHash string  `json:"passwordHash,omitempty"`
+}
+
+// NewToken returns the secret of a new API token, and the token that is stored
+// for it.
+func NewToken(name string, scopes []Scope, password string) (string, Token, error) {
+	if err := VerifyScopes(scopes); err != nil {
+		return "", Token{}, err
+	}
+	secret := [32]byte{}
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", Token{}, err
+	}
+	id := [8]byte{}
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", Token{}, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", Token{}, err
+	}
+	secretString := base64.RawURLEncoding.EncodeToString(secret[:])
+	return secretString, Token{
+		ID:           hex.EncodeToString(id[:]),
+		Name:         name,
+		Scopes:       scopes,
+		CreatedAt:    time.Now().Unix(),
+		Hash:         HashSecret(secretString),
+		PasswordHash: base64.StdEncoding.EncodeToString(passwordHash),
+	}, nil
+}
+
+// HashSecret returns the base64 encoded sha3 hash of the secret of an API
+// token, which is used to look up the token.
+func HashSecret(secret string) string {
+	hash := sha3.Sum256([]byte(secret))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// VerifyScopes returns an error if any of the scopes is unknown.
+func VerifyScopes(scopes []Scope) error {
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeRead, ScopeSwap, ScopeTransfer, ScopeSign:
+		default:
+			return fmt.Errorf("unknown api token scope: %s", scope)
+		}
+	}
+	return nil
+}
+
+// Allows returns true if the token is allowed to make requests of the scope.
+// Every token can make read-only requests.
+func (token Token) Allows(scope Scope) bool {
+	if scope == ScopeRead {
+		return true
+	}
+	for _, allowed := range token.Scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnedBy returns true if the token was created with the password.
+func (token Token) OwnedBy(password string) bool {
+	hash, err := base64.StdEncoding.DecodeString(token.PasswordHash)
+	if err != nil {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
+
+// Public returns the token without its hashes.
+func (token Token) Public() Token {
+	token.Hash = ""
+	token.PasswordHash = ""
+	return token
+}
@@ -0,0 +1,39 @@
+package auth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/auth"
+)
+
+var _ = Describe("API tokens", func() {
+	It("should only store the hash of the secret", func() {
+		secret, token, err := NewToken("bot", []Scope{ScopeSwap}, "password")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(token.Hash).Should(Equal(HashSecret(secret)))
+		Expect(token.Hash).ShouldNot(ContainSubstring(secret))
+		Expect(token.Public().Hash).Should(BeEmpty())
+		Expect(token.Public().PasswordHash).Should(BeEmpty())
+	})
+
+	It("should be owned by the password it was created with", func() {
+		_, token, err := NewToken("bot", nil, "password")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(token.OwnedBy("password")).Should(BeTrue())
+		Expect(token.OwnedBy("other")).Should(BeFalse())
+	})
+
+	It("should allow read-only requests and the requests of its scopes", func() {
+		_, token, err := NewToken("bot", []Scope{ScopeSwap}, "password")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(token.Allows(ScopeRead)).Should(BeTrue())
+		Expect(token.Allows(ScopeSwap)).Should(BeTrue())
+		Expect(token.Allows(ScopeTransfer)).Should(BeFalse())
+		Expect(token.Allows(ScopeSign)).Should(BeFalse())
+	})
+
+	It("should reject unknown scopes", func() {
+		_, _, err := NewToken("bot", []Scope{"admin"}, "password")
+		Expect(err).Should(HaveOccurred())
+	})
+})
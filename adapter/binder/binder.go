@@ -8,6 +8,7 @@ import (
 	"github.com/renproject/swapperd/adapter/binder/btc"
 	"github.com/renproject/swapperd/adapter/binder/erc20"
 	"github.com/renproject/swapperd/adapter/binder/eth"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
 	"github.com/renproject/swapperd/adapter/wallet"
 	"github.com/renproject/swapperd/core/wallet/swapper/immediate"
 	"github.com/renproject/swapperd/foundation/blockchain"
@@ -17,40 +18,52 @@ import (
 
 type builder struct {
 	wallet.Wallet
+	timeLocks swap.TimeLockPolicies
 	logrus.FieldLogger
 }
 
-func NewBuilder(wallet wallet.Wallet, logger logrus.FieldLogger) immediate.ContractBuilder {
+func NewBuilder(wallet wallet.Wallet, timeLocks swap.TimeLockPolicies, logger logrus.FieldLogger) immediate.ContractBuilder {
 	return &builder{
 		wallet,
+		timeLocks,
 		logger,
 	}
 }
 
 func (builder *builder) BuildSwapContracts(req immediate.SwapRequest) (immediate.Contract, immediate.Contract, error) {
-	native, foreign, err := builder.buildComplementarySwaps(req.Blob)
+	blob := builder.setTimeLocks(req.Blob)
+	native, foreign, err := builder.buildComplementarySwaps(blob)
 	if err != nil {
 		return nil, nil, err
 	}
-	nativeBinder, err := builder.buildBinder(native, req.SendCost, req.Blob.Password)
+	native.FeeBump, native.Fee = req.SendBump, req.SendBump.Apply(native.Fee)
+	foreign.FeeBump, foreign.Fee = req.ReceiveBump, req.ReceiveBump.Apply(foreign.Fee)
+	nativeBinder, err := builder.buildBinder(native, req.SendCost, req.Blob.Password, 0)
 	if err != nil {
 		return nil, nil, err
 	}
-	foreignBinder, err := builder.buildBinder(foreign, req.ReceiveCost, req.Blob.Password)
+	foreignBinder, err := builder.buildBinder(foreign, req.ReceiveCost, req.Blob.Password, req.Blob.AddressIndex)
 	if err != nil {
 		return nil, nil, err
 	}
-	return nativeBinder, foreignBinder, nil
+	return newTimeLockGuard(nativeBinder, blob.SafetyMargin, minInt64(blob.NativeTimeLock, blob.ForeignTimeLock)), foreignBinder, nil
 }
 
-func (builder *builder) buildBinder(swap swap.Swap, cost blockchain.Cost, password string) (immediate.Contract, error) {
+// buildBinder builds the binder of the swap with the keys of the address index.
+// We fund our swaps from the first address, and receive the funds of the swaps
+// of our peers at the address index of the swap.
+func (builder *builder) buildBinder(swap swap.Swap, cost blockchain.Cost, password string, index uint32) (immediate.Contract, error) {
 	switch swap.Token.Blockchain {
 	case blockchain.Bitcoin:
-		btcAccount, err := builder.BitcoinAccount(password)
+		btcAccount, err := builder.BitcoinAccountAt(password, index)
 		if err != nil {
 			return nil, err
 		}
-		return btc.NewBTCSwapContractBinder(btcAccount, swap, cost, builder.FieldLogger)
+		witnessAccount, err := builder.BitcoinWitnessAccountAt(password, index)
+		if err != nil {
+			return nil, err
+		}
+		return btc.NewBTCSwapContractBinder(btcAccount, witnessAccount, swap, cost, builder.FieldLogger)
 	case blockchain.Ethereum:
 		ethAccount, err := builder.EthereumAccount(password)
 		if err != nil {
@@ -62,7 +75,17 @@ func (builder *builder) buildBinder(swap swap.Swap, cost blockchain.Cost, passwo
 		if err != nil {
 			return nil, err
 		}
-		return erc20.NewERC20SwapContractBinder(ethAccount, swap, cost, builder.FieldLogger)
+		tokenAddress, swapperAddress, err := builder.ERC20Addresses(ethAccount, swap.Token)
+		if err != nil {
+			return nil, err
+		}
+		return erc20.NewERC20SwapContractBinder(ethAccount, tokenAddress, swapperAddress, swap, cost, builder.FieldLogger)
+	case blockchain.Litecoin, blockchain.BitcoinCash:
+		utxoAccount, err := builder.UTXOAccountAt(password, swap.Token.Blockchain, index)
+		if err != nil {
+			return nil, err
+		}
+		return utxo.NewSwapContractBinder(utxoAccount, swap, cost, builder.FieldLogger)
 	default:
 		return nil, blockchain.NewErrUnsupportedToken(swap.Token.Name)
 	}
@@ -73,13 +96,12 @@ func (builder *builder) buildComplementarySwaps(blob swap.SwapBlob) (swap.Swap,
 	if err != nil {
 		return swap.Swap{}, swap.Swap{}, err
 	}
-	nativeExpiry, foreignExpiry := builder.calculateTimeLocks(blob)
 
-	nativeSwap, err := builder.buildNativeSwap(blob, nativeExpiry, fundingAddr)
+	nativeSwap, err := builder.buildNativeSwap(blob, blob.NativeTimeLock, fundingAddr)
 	if err != nil {
 		return swap.Swap{}, swap.Swap{}, err
 	}
-	foreignSwap, err := builder.buildForeignSwap(blob, foreignExpiry, spendingAddr)
+	foreignSwap, err := builder.buildForeignSwap(blob, blob.ForeignTimeLock, spendingAddr)
 	if err != nil {
 		return swap.Swap{}, swap.Swap{}, err
 	}
@@ -98,7 +120,7 @@ func (builder *builder) buildNativeSwap(blob swap.SwapBlob, timelock int64, fund
 
 	fee, ok := new(big.Int).SetString(blob.SendFee, 10)
 	if !ok {
-		fee, err = token.BlockchainTxFees()
+		fee, err = builder.SwapTxFee(token)
 		if err != nil {
 			return swap.Swap{}, fmt.Errorf("failed to get default fee: %v", err)
 		}
@@ -123,11 +145,13 @@ func (builder *builder) buildNativeSwap(blob swap.SwapBlob, timelock int64, fund
 		Value:           value,
 		Fee:             fee,
 		SecretHash:      secretHash,
-		TimeLock:        blob.TimeLock,
+		TimeLock:        timelock,
 		SpendingAddress: blob.SendTo,
 		FundingAddress:  fundingAddress,
 		BrokerAddress:   blob.BrokerSendTokenAddr,
 		BrokerFee:       brokerFee,
+		BitcoinScript:   blob.BitcoinScript,
+		Confirmations:   builder.Confirmations(token.Blockchain),
 	}, nil
 }
 
@@ -144,7 +168,7 @@ func (builder *builder) buildForeignSwap(blob swap.SwapBlob, timelock int64, spe
 
 	fee, ok := new(big.Int).SetString(blob.ReceiveFee, 10)
 	if !ok {
-		fee, err = token.BlockchainTxFees()
+		fee, err = builder.SwapTxFee(token)
 		if err != nil {
 			return swap.Swap{}, fmt.Errorf("failed to get default fee: %v", err)
 		}
@@ -174,24 +198,32 @@ func (builder *builder) buildForeignSwap(blob swap.SwapBlob, timelock int64, spe
 		Value:           value,
 		Fee:             fee,
 		SecretHash:      secretHash,
-		TimeLock:        blob.TimeLock,
+		TimeLock:        timelock,
 		SpendingAddress: spendingAddress,
 		FundingAddress:  blob.ReceiveFrom,
 		WithdrawAddress: withdrawAddress,
 		BrokerAddress:   blob.BrokerReceiveTokenAddr,
 		BrokerFee:       brokerFee,
+		BitcoinScript:   blob.BitcoinScript,
+		Confirmations:   builder.Confirmations(token.Blockchain),
 	}, nil
 }
 
-func (builder *builder) calculateTimeLocks(swap swap.SwapBlob) (native, foreign int64) {
-	if swap.ShouldInitiateFirst {
-		native = swap.TimeLock
-		foreign = swap.TimeLock - 24*60*60
-		return
+// setTimeLocks returns the swap with the timelocks that were stored when it was
+// created. Swaps that were created before the timelocks were stored use the
+// current policy of their token pair.
+func (builder *builder) setTimeLocks(blob swap.SwapBlob) swap.SwapBlob {
+	if blob.NativeTimeLock != 0 && blob.ForeignTimeLock != 0 {
+		return blob
+	}
+	return builder.timeLocks.Pair(blob.SendToken, blob.ReceiveToken).SetTimeLocks(blob)
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
 	}
-	native = swap.TimeLock - 24*60*60
-	foreign = swap.TimeLock
-	return
+	return b
 }
 
 func (builder *builder) calculateAddresses(swap swap.SwapBlob) (string, string, error) {
@@ -207,7 +239,7 @@ func (builder *builder) calculateAddresses(swap swap.SwapBlob) (string, string,
 	if err != nil {
 		return "", "", err
 	}
-	receiveAddress, err := builder.Wallet.GetAddress(swap.Password, receiveToken.Blockchain)
+	receiveAddress, err := builder.Wallet.GetAddressAt(swap.Password, receiveToken.Blockchain, swap.AddressIndex)
 	if err != nil {
 		return "", "", err
 	}
@@ -8,13 +8,14 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
-	"github.com/btcsuite/btcutil"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
+	"github.com/renproject/swapperd/adapter/wallet"
 	"github.com/renproject/swapperd/core/wallet/swapper/immediate"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
-	"github.com/republicprotocol/libbtc-go"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,15 +25,24 @@ type btcSwapContractBinder struct {
 	swap       swap.Swap
 	txVersion  int32
 	fee        int64
-	verify     bool
+	chain      utxo.Chain
 	cost       blockchain.Cost
+	witness    wallet.WitnessAccount
 	logrus.FieldLogger
-	libbtc.Account
+	utxo.Account
 }
 
-// NewBTCSwapContractBinder returns a new Bitcoin Atom instance
-func NewBTCSwapContractBinder(account libbtc.Account, swap swap.Swap, cost blockchain.Cost, logger logrus.FieldLogger) (immediate.Contract, error) {
-	script, scriptAddr, err := buildInitiateScript(swap, account.NetworkParams())
+// NewBTCSwapContractBinder returns a new Bitcoin Atom instance. Swaps that use
+// a P2SH script are the same as the swaps of other UTXO blockchains, and are
+// bound by the UTXO binder. The witness account is used to spend P2WSH swap
+// scripts.
+func NewBTCSwapContractBinder(account utxo.Account, witness wallet.WitnessAccount, swap swap.Swap, cost blockchain.Cost, logger logrus.FieldLogger) (immediate.Contract, error) {
+	if !isWitnessSwap(swap) {
+		return utxo.NewSwapContractBinder(account, swap, cost, logger)
+	}
+
+	chain := account.Chain()
+	script, scriptAddr, err := buildInitiateScript(swap, chain.Params)
 	if err != nil {
 		return nil, err
 	}
@@ -47,8 +57,8 @@ func NewBTCSwapContractBinder(account libbtc.Account, swap swap.Swap, cost block
 		cost[blockchain.BTC] = big.NewInt(0)
 	}
 
-	if swap.BrokerFee.Int64() != 0 && swap.BrokerFee.Int64() < 600 {
-		swap.BrokerFee = big.NewInt(600)
+	if swap.BrokerFee.Int64() != 0 && swap.BrokerFee.Int64() < chain.Dust {
+		swap.BrokerFee = big.NewInt(chain.Dust)
 	}
 
 	swap.Value = new(big.Int).Add(swap.Value, swap.BrokerFee)
@@ -60,7 +70,8 @@ func NewBTCSwapContractBinder(account libbtc.Account, swap swap.Swap, cost block
 		swap:        swap,
 		txVersion:   2,
 		fee:         swap.Fee.Int64(),
-		verify:      true,
+		chain:       chain,
+		witness:     witness,
 		FieldLogger: logger,
 		Account:     account,
 		cost:        cost,
@@ -70,69 +81,55 @@ func NewBTCSwapContractBinder(account libbtc.Account, swap swap.Swap, cost block
 // Initiate the atomic swap by funding a HTLC on the Bitcoin blockchain.
 func (atom *btcSwapContractBinder) Initiate() error {
 	atom.Info("Initiating on Bitcoin blockchain for BTC")
-	scriptAddr, err := btcutil.DecodeAddress(atom.scriptAddr, atom.NetworkParams())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// An unconfirmed funding transaction that pays less than the bumped fee is
+	// replaced by a transaction that spends its inputs again.
+	bump := atom.swap.FeeBump
+	replaced := utxo.Replaced{}
+	if utxo.Replaceable(ctx, atom.Account, bump, atom.fee) {
+		replaced = utxo.NewReplaced(bump)
+	}
+	funded, value, err := atom.scriptFunded(ctx, atom.swap.Value.Int64(), replaced)
 	if err != nil {
 		return NewErrInitiate(err)
 	}
-	initiateScriptP2SHPKScript, err := txscript.PayToAddrScript(scriptAddr)
-	if err != nil {
-		return NewErrInitiate(NewErrBuildScript(err))
+	if funded {
+		atom.Info(fmt.Sprintf("Send value on Bitcoin blockchain = %d", atom.swap.Value.Int64()))
+		return nil
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
-
-	// signing a transaction with the given private key
-	if err := atom.SendTransaction(
-		ctx,
-		nil,
-		atom.fee,
-		nil,
-		func(tx *wire.MsgTx) bool {
-			// checks whether the contract is funded, with given value
-			funded, value, err := atom.ScriptFunded(ctx, atom.scriptAddr, atom.swap.Value.Int64())
-			if err != nil {
-				return false
-			}
-			if funded {
-				atom.Info(fmt.Sprintf("Send value on Bitcoin blockchain = %d", atom.swap.Value.Int64()))
-				return false
-			}
-
-			if atom.swap.Value.Int64()-value < 600 {
-				return false
-			}
-
-			// creating unsigned transaction and adding transaction outputs
-			tx.AddTxOut(wire.NewTxOut(atom.swap.Value.Int64()-value, initiateScriptP2SHPKScript))
-			return !funded
-		},
-		nil,
-		func(tx *wire.MsgTx) bool {
-			funded, _, err := atom.ScriptFunded(ctx, atom.scriptAddr, atom.swap.Value.Int64())
-			if err != nil {
-				return false
-			}
-			if funded {
-				atom.cost[blockchain.BTC] = new(big.Int).Add(big.NewInt(atom.fee), atom.cost[blockchain.BTC])
-				atom.cost[blockchain.BTC] = new(big.Int).Add(atom.swap.BrokerFee, atom.cost[blockchain.BTC])
-				atom.Info(atom.FormatTransactionView("Initiated on Bitcoin blockchain", tx.TxHash().String()))
-			}
-			return funded
-		},
-	); err != nil && err != libbtc.ErrPreConditionCheckFailed {
-		return err
+	if atom.swap.Value.Int64()-value < atom.chain.Dust {
+		return nil
 	}
 
+	tx, spent, err := atom.TransferReplacing(ctx, []utxo.Output{{To: atom.scriptAddr, Value: atom.swap.Value.Int64() - value}}, atom.fee, replaced)
+	if err != nil {
+		return NewErrInitiate(err)
+	}
+	if len(replaced.TxHashes) == 0 {
+		bump.Reset()
+		atom.addCost(atom.swap.BrokerFee.Int64())
+	}
+	atom.record(tx, spent)
+	atom.Info(atom.chain.FormatTransactionView("Initiated on Bitcoin blockchain", tx.TxHash().String()))
 	return nil
 }
 
 func (atom *btcSwapContractBinder) Audit() error {
-	if funded, amount, err := atom.ScriptFunded(context.Background(), atom.scriptAddr, atom.swap.Value.Int64()); funded && err == nil {
+	ctx := context.Background()
+	if funded, amount, err := atom.scriptFunded(ctx, atom.swap.Value.Int64(), utxo.Replaced{}); funded && err == nil {
 		value := new(big.Int).Sub(atom.swap.Value, atom.swap.BrokerFee)
 		if amount < value.Int64() {
 			return fmt.Errorf("Audit Failed")
 		}
+		confirmations, err := atom.scriptConfirmations(ctx, value.Int64())
+		if err != nil {
+			return err
+		}
+		if confirmations < atom.swap.Confirmations {
+			return immediate.NewErrConfirmationsPending(confirmations, atom.swap.Confirmations)
+		}
 		return nil
 	}
 
@@ -149,84 +146,35 @@ func (atom *btcSwapContractBinder) Redeem(secret [32]byte) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	address, err := btcutil.DecodeAddress(atom.swap.WithdrawAddress, atom.Account.NetworkParams())
-	if err != nil {
-		return NewErrRedeem(err)
-	}
-
-	payToAddrScript, err := txscript.PayToAddrScript(address)
+	payToAddrScript, err := atom.chain.PayToAddrScript(atom.swap.WithdrawAddress)
 	if err != nil {
-		return NewErrRedeem(err)
+		return NewErrRedeem(NewErrDecodeAddress(atom.swap.WithdrawAddress, err))
 	}
 
 	var feeAddrScript []byte
 	if atom.swap.BrokerFee.Int64() != 0 {
-		feeAddress, err := btcutil.DecodeAddress(atom.swap.BrokerAddress, atom.NetworkParams())
+		feeAddrScript, err = atom.chain.PayToAddrScript(atom.swap.BrokerAddress)
 		if err != nil {
-			return NewErrRedeem(err)
-		}
-
-		feeAddrScript, err = txscript.PayToAddrScript(feeAddress)
-		if err != nil {
-			return NewErrRedeem(err)
+			return NewErrRedeem(NewErrDecodeAddress(atom.swap.BrokerAddress, err))
 		}
 	}
 
-	if err := atom.SendTransaction(
-		ctx,
-		atom.script,
-		atom.fee,
-		nil,
-		func(tx *wire.MsgTx) bool {
-			redeemed, val, err := atom.ScriptRedeemed(ctx, atom.scriptAddr, 0)
-			if err != nil {
-				return false
-			}
-			if !redeemed {
-				if val-atom.swap.BrokerFee.Int64()-atom.fee < 600 {
-					return false
-				}
-				tx.AddTxOut(wire.NewTxOut(atom.swap.BrokerFee.Int64(), feeAddrScript))
-				tx.AddTxOut(wire.NewTxOut(val-atom.swap.BrokerFee.Int64()-atom.fee, payToAddrScript))
-			}
-			return !redeemed
-		},
-		func(builder *txscript.ScriptBuilder) {
-			builder.AddData(secret[:])
-			builder.AddInt64(1)
-		},
-		func(tx *wire.MsgTx) bool {
-			spent, err := atom.ScriptSpent(ctx, atom.scriptAddr)
-			if err != nil {
-				return false
-			}
-			if spent {
-				atom.cost[blockchain.BTC] = new(big.Int).Add(big.NewInt(atom.fee), atom.cost[blockchain.BTC])
-				atom.Info(atom.FormatTransactionView("Redeemed on Bitcoin blockchain", tx.TxHash().String()))
-			}
-			return spent
-		},
-	); err != nil && err != libbtc.ErrPreConditionCheckFailed {
-		return err
+	if err := atom.redeemWitness(ctx, secret, payToAddrScript, feeAddrScript); err != nil {
+		return NewErrRedeem(err)
 	}
 	return nil
 }
 
 func (atom *btcSwapContractBinder) AuditSecret() ([32]byte, error) {
 	atom.Info("Auditing secret on Bitcoin blockchain")
-	if spent, err := atom.ScriptSpent(context.Background(), atom.scriptAddr); !spent || err != nil {
+	if spent, err := atom.scriptSpent(context.Background()); !spent || err != nil {
 		if time.Now().Unix() > atom.swap.TimeLock {
 			return [32]byte{}, immediate.ErrSwapExpired
 		}
 		return [32]byte{}, immediate.ErrAuditPending
 	}
 
-	sigScript, err := atom.GetScriptFromSpentP2SH(context.Background(), atom.scriptAddr)
-	if err != nil {
-		return [32]byte{}, NewErrAuditSecret(err)
-	}
-
-	pushes, err := txscript.PushedData(sigScript)
+	pushes, err := atom.spendingPushes(context.Background())
 	if err != nil {
 		return [32]byte{}, NewErrAuditSecret(err)
 	}
@@ -244,51 +192,14 @@ func (atom *btcSwapContractBinder) AuditSecret() ([32]byte, error) {
 // Refund the Atomic Swap after expiry and withdraw funds from the HTLC.
 func (atom *btcSwapContractBinder) Refund() error {
 	atom.Info("Refunding on Bitcoin blockchain")
-	address, err := atom.Address()
-	if err != nil {
-		return NewErrRefund(err)
-	}
-	payToAddrScript, err := txscript.PayToAddrScript(address)
+	payToAddrScript, err := atom.chain.PayToAddrScript(atom.Address())
 	if err != nil {
 		return NewErrRefund(err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
-	if err := atom.SendTransaction(
-		ctx,
-		atom.script,
-		atom.fee,
-		func(txIn *wire.TxIn) {
-			txIn.Sequence = 0
-		},
-		func(tx *wire.MsgTx) bool {
-			funded, val, err := atom.ScriptFunded(ctx, atom.scriptAddr, 0)
-			if err != nil {
-				return false
-			}
-			if funded {
-				tx.AddTxOut(wire.NewTxOut(val-atom.fee, payToAddrScript))
-			}
-			tx.LockTime = uint32(atom.swap.TimeLock)
-			return funded
-		},
-		func(builder *txscript.ScriptBuilder) {
-			builder.AddInt64(0)
-		},
-		func(tx *wire.MsgTx) bool {
-			spent, err := atom.ScriptSpent(ctx, atom.scriptAddr)
-			if err != nil {
-				return false
-			}
-			if spent {
-				atom.cost[blockchain.BTC] = new(big.Int).Add(big.NewInt(atom.fee), atom.cost[blockchain.BTC])
-				atom.cost[blockchain.BTC] = new(big.Int).Sub(atom.cost[blockchain.BTC], atom.swap.BrokerFee)
-				atom.Info(atom.FormatTransactionView("Refunded on Bitcoin blockchain", tx.TxHash().String()))
-			}
-			return spent
-		},
-	); err != nil && err != libbtc.ErrPreConditionCheckFailed {
+	if err := atom.refundWitness(ctx, payToAddrScript); err != nil {
 		return NewErrRefund(err)
 	}
 	return nil
@@ -297,3 +208,200 @@ func (atom *btcSwapContractBinder) Refund() error {
 func (atom *btcSwapContractBinder) Cost() blockchain.Cost {
 	return atom.cost
 }
+
+// scriptFunded returns true if the swap script holds at least the value, and
+// the value that it holds. Outputs created by the replaced transactions are not
+// counted.
+func (atom *btcSwapContractBinder) scriptFunded(ctx context.Context, value int64, replaced utxo.Replaced) (bool, int64, error) {
+	utxos, err := atom.witness.UnspentOutputs(ctx, atom.scriptAddr)
+	if err != nil {
+		return false, 0, err
+	}
+	balance := int64(0)
+	for _, output := range utxos {
+		if !replaced.Creates(output) {
+			balance += output.Amount
+		}
+	}
+	return balance >= value, balance, nil
+}
+
+// scriptConfirmations returns the number of confirmations of the value funding
+// the swap script, up to the number of confirmations required by the swap.
+func (atom *btcSwapContractBinder) scriptConfirmations(ctx context.Context, value int64) (int64, error) {
+	utxos, err := atom.witness.UnspentOutputs(ctx, atom.scriptAddr)
+	if err != nil {
+		return 0, err
+	}
+	for confirmations := int64(1); confirmations <= atom.swap.Confirmations; confirmations++ {
+		balance := int64(0)
+		for _, utxo := range utxos {
+			if utxo.Confirmations >= confirmations {
+				balance += utxo.Amount
+			}
+		}
+		if balance < value {
+			return confirmations - 1, nil
+		}
+	}
+	return atom.swap.Confirmations, nil
+}
+
+// scriptSpent returns true if the swap script has been redeemed or refunded.
+func (atom *btcSwapContractBinder) scriptSpent(ctx context.Context) (bool, error) {
+	_, spent, err := atom.witness.SpendingWitness(ctx, atom.scriptAddr)
+	return spent, err
+}
+
+// spendingPushes returns the witness of the transaction that spent the swap
+// script.
+func (atom *btcSwapContractBinder) spendingPushes(ctx context.Context) ([][]byte, error) {
+	witness, spent, err := atom.witness.SpendingWitness(ctx, atom.scriptAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !spent {
+		return nil, ErrMalformedRedeemTx
+	}
+	return witness, nil
+}
+
+func (atom *btcSwapContractBinder) addCost(value int64) {
+	atom.cost[blockchain.BTC] = new(big.Int).Add(big.NewInt(value), atom.cost[blockchain.BTC])
+}
+
+func (atom *btcSwapContractBinder) redeemWitness(ctx context.Context, secret [32]byte, payToAddrScript, feeAddrScript []byte) error {
+	utxos, err := atom.spendableOutputs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(utxos) == 0 {
+		atom.Info("Skipping redeem on Bitcoin blockchain")
+		return nil
+	}
+
+	tx, err := atom.spendWitness(ctx, utxos, utxo.Sequence, 0, func(value int64) ([]*wire.TxOut, error) {
+		if value-atom.swap.BrokerFee.Int64()-atom.fee < atom.chain.Dust {
+			return nil, fmt.Errorf("swap value %d is too low to redeem", value)
+		}
+		outputs := []*wire.TxOut{wire.NewTxOut(value-atom.swap.BrokerFee.Int64()-atom.fee, payToAddrScript)}
+		if atom.swap.BrokerFee.Int64() != 0 {
+			outputs = append(outputs, wire.NewTxOut(atom.swap.BrokerFee.Int64(), feeAddrScript))
+		}
+		return outputs, nil
+	}, func(sig, pubKey []byte) wire.TxWitness {
+		return newRedeemWitness(atom.script, sig, pubKey, secret)
+	})
+	if err != nil {
+		return err
+	}
+	atom.record(tx, utxos)
+	atom.Info(atom.chain.FormatTransactionView("Redeemed on Bitcoin blockchain", tx.TxHash().String()))
+	return nil
+}
+
+func (atom *btcSwapContractBinder) refundWitness(ctx context.Context, payToAddrScript []byte) error {
+	utxos, err := atom.spendableOutputs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(utxos) == 0 {
+		atom.Info("Skipping refund on Bitcoin blockchain")
+		return nil
+	}
+
+	tx, err := atom.spendWitness(ctx, utxos, 0, uint32(atom.swap.TimeLock), func(value int64) ([]*wire.TxOut, error) {
+		if value-atom.fee < atom.chain.Dust {
+			return nil, fmt.Errorf("swap value %d is too low to refund", value)
+		}
+		return []*wire.TxOut{wire.NewTxOut(value-atom.fee, payToAddrScript)}, nil
+	}, func(sig, pubKey []byte) wire.TxWitness {
+		return newRefundWitness(atom.script, sig, pubKey)
+	})
+	if err != nil {
+		return err
+	}
+	if !atom.swap.FeeBump.Pending() {
+		atom.addCost(-atom.swap.BrokerFee.Int64())
+	}
+	atom.record(tx, utxos)
+	atom.Info(atom.chain.FormatTransactionView("Refunded on Bitcoin blockchain", tx.TxHash().String()))
+	return nil
+}
+
+// spendableOutputs returns the outputs of the P2WSH swap script that are spent
+// by the redeem or refund transaction. They are the unspent outputs of the
+// script, or the outputs spent by an unconfirmed transaction that pays less
+// than the bumped fee. No outputs are returned once the script has been spent.
+func (atom *btcSwapContractBinder) spendableOutputs(ctx context.Context) ([]utxo.UTXO, error) {
+	bump := atom.swap.FeeBump
+	if utxo.Replaceable(ctx, atom.Account, bump, atom.fee) {
+		return utxo.NewReplaced(bump).Spent, nil
+	}
+	spent, err := atom.scriptSpent(ctx)
+	if err != nil || spent {
+		return nil, err
+	}
+	bump.Reset()
+	return atom.witness.UnspentOutputs(ctx, atom.scriptAddr)
+}
+
+// record adds the fee of the transaction to the cost of the swap, and records
+// it in the fee bump. Only one of the replacements of a transaction is
+// confirmed, so replacements only add the increase in fee.
+func (atom *btcSwapContractBinder) record(tx *wire.MsgTx, spent []utxo.UTXO) {
+	fee := atom.fee
+	if atom.swap.FeeBump.Pending() {
+		fee -= atom.swap.FeeBump.Fee.Int64()
+	}
+	atom.addCost(fee)
+	utxo.RecordBroadcast(atom.swap.FeeBump, tx, spent, atom.fee)
+}
+
+// spendWitness publishes a transaction that spends the outputs of the P2WSH
+// swap script. Each input is signed, and its witness is built by the witness
+// function.
+func (atom *btcSwapContractBinder) spendWitness(ctx context.Context, utxos []utxo.UTXO, sequence, lockTime uint32, outputs func(value int64) ([]*wire.TxOut, error), witness func(sig, pubKey []byte) wire.TxWitness) (*wire.MsgTx, error) {
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("swap script %s is not funded", atom.scriptAddr)
+	}
+
+	tx := wire.NewMsgTx(atom.txVersion)
+	value := int64(0)
+	for _, utxo := range utxos {
+		hash, err := chainhash.NewHashFromStr(utxo.TxHash)
+		if err != nil {
+			return nil, err
+		}
+		txIn := wire.NewTxIn(wire.NewOutPoint(hash, utxo.Vout), nil, nil)
+		txIn.Sequence = sequence
+		tx.AddTxIn(txIn)
+		value += utxo.Amount
+	}
+	txOuts, err := outputs(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, txOut := range txOuts {
+		tx.AddTxOut(txOut)
+	}
+	tx.LockTime = lockTime
+
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i, utxo := range utxos {
+		hash, err := txscript.CalcWitnessSigHash(atom.script, sigHashes, txscript.SigHashAll, tx, i, utxo.Amount)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := atom.witness.Sign(hash)
+		if err != nil {
+			return nil, err
+		}
+		tx.TxIn[i].Witness = witness(append(sig, byte(txscript.SigHashAll)), atom.witness.SerializedPubKey())
+	}
+
+	if err := atom.witness.PublishTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
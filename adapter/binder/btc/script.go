@@ -1,12 +1,13 @@
 package btc
 
 import (
-	"errors"
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
 	"github.com/renproject/swapperd/foundation/swap"
 	"golang.org/x/crypto/ripemd160"
 )
@@ -19,61 +20,8 @@ const AtomicSwapRefundScriptSize = 1 + 73 + 1 + 33 + 1
 // RedeemScript
 const AtomicSwapRedeemScriptSize = 1 + 73 + 1 + 33 + 1 + 32 + 1
 
-// newInitiateScript creates a Bitcoin Atomic Swap initiate script.
-//
-//			OP_IF
-//				OP_SIZE
-// 				32
-//				OP_EQUALVERIFY
-//				OP_SHA256
-//				<secret_hash>
-//				OP_EQUALVERIFY
-//				OP_DUP
-//				OP_HASH160
-//				<foreign_address>
-//			OP_ELSE
-//				<lock_time>
-//				OP_CHECKLOCKTIMEVERIFY
-//				OP_DROP
-//				OP_DUP
-//				OP_HASH160
-//				<personal_address>
-//			OP_ENDIF
-//			OP_EQUALVERIFY
-//			OP_CHECKSIG
-//
-func newInitiateScript(pkhMe, pkhThem *[ripemd160.Size]byte, locktime int64, secretHash []byte) ([]byte, error) {
-	b := txscript.NewScriptBuilder()
-
-	b.AddOp(txscript.OP_IF)
-	{
-		b.AddOp(txscript.OP_SIZE)
-		b.AddData([]byte{32})
-		b.AddOp(txscript.OP_EQUALVERIFY)
-		b.AddOp(txscript.OP_SHA256)
-		b.AddData(secretHash)
-		b.AddOp(txscript.OP_EQUALVERIFY)
-		b.AddOp(txscript.OP_DUP)
-		b.AddOp(txscript.OP_HASH160)
-		b.AddData(pkhThem[:])
-	}
-	b.AddOp(txscript.OP_ELSE)
-	{
-		b.AddInt64(locktime)
-		b.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
-		b.AddOp(txscript.OP_DROP)
-		b.AddOp(txscript.OP_DUP)
-		b.AddOp(txscript.OP_HASH160)
-		b.AddData(pkhMe[:])
-	}
-	b.AddOp(txscript.OP_ENDIF)
-	b.AddOp(txscript.OP_EQUALVERIFY)
-	b.AddOp(txscript.OP_CHECKSIG)
-
-	return b.Script()
-}
-
-// newRedeemScript creates a Redeem Script for the Bitcoin Atomic Swap.
+// newRedeemWitness creates the witness that redeems a P2WSH Bitcoin Atomic
+// Swap.
 //
 //			<Signature>
 //			<PublicKey>
@@ -81,72 +29,74 @@ func newInitiateScript(pkhMe, pkhThem *[ripemd160.Size]byte, locktime int64, sec
 //			1 (True)
 //			<InitiateScript>
 //
-func newRedeemScript(initiateScript, sig, pubkey []byte, secret [32]byte) ([]byte, error) {
-	b := txscript.NewScriptBuilder()
-	b.AddData(sig)
-	b.AddData(pubkey)
-	b.AddData(secret[:])
-	b.AddInt64(1)
-	b.AddData(initiateScript)
-	return b.Script()
+func newRedeemWitness(initiateScript, sig, pubkey []byte, secret [32]byte) wire.TxWitness {
+	return wire.TxWitness{sig, pubkey, secret[:], []byte{1}, initiateScript}
 }
 
-// newRefundScript creates a Bitcoin Refund Atomic Swap.
+// newRefundWitness creates the witness that refunds a P2WSH Bitcoin Atomic
+// Swap.
 //
 //			<Signature>
 //			<PublicKey>
-//			0 (False)
+//			<> (False)
 //			<InitiateScript>
 //
-func newRefundScript(initiateScript, sig, pubkey []byte) ([]byte, error) {
-	b := txscript.NewScriptBuilder()
-	b.AddData(sig)
-	b.AddData(pubkey)
-	b.AddInt64(0)
-	b.AddData(initiateScript)
-	return b.Script()
+func newRefundWitness(initiateScript, sig, pubkey []byte) wire.TxWitness {
+	return wire.TxWitness{sig, pubkey, []byte{}, initiateScript}
 }
 
-func addressToPubKeyHash(addrString string, chainParams *chaincfg.Params) (*btcutil.AddressPubKeyHash, error) {
+// addressToPubKeyHash returns the public key hash of a P2PKH or P2WPKH
+// address.
+func addressToPubKeyHash(addrString string, chainParams *chaincfg.Params) (*[ripemd160.Size]byte, error) {
 	btcAddr, err := btcutil.DecodeAddress(addrString, chainParams)
 	if err != nil {
 		return nil, fmt.Errorf("address %s is not "+
 			"intended for use on %v", addrString, chainParams.Name)
 	}
-	addr, ok := btcAddr.(*btcutil.AddressPubKeyHash)
-	if !ok {
-		return nil, errors.New("%s is not p2pkh address")
+	switch addr := btcAddr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return addr.Hash160(), nil
+	case *btcutil.AddressWitnessPubKeyHash:
+		return addr.Hash160(), nil
+	default:
+		return nil, fmt.Errorf("%s is not a p2pkh or p2wpkh address", addrString)
 	}
-	return addr, nil
 }
 
-func buildInitiateScript(swap swap.Swap, Net *chaincfg.Params) ([]byte, string, error) {
+// buildInitiateScript returns the initiate script of the swap, and the address
+// of its P2WSH output.
+func buildInitiateScript(htlc swap.Swap, Net *chaincfg.Params) ([]byte, string, error) {
 	// decoding bitcoin addresses
-	FundingAddr, err := addressToPubKeyHash(swap.FundingAddress, Net)
+	FundingAddr, err := addressToPubKeyHash(htlc.FundingAddress, Net)
 	if err != nil {
-		return nil, "", NewErrDecodeAddress(swap.FundingAddress, err)
+		return nil, "", NewErrDecodeAddress(htlc.FundingAddress, err)
 	}
 
-	SpendingAddr, err := addressToPubKeyHash(swap.SpendingAddress, Net)
+	SpendingAddr, err := addressToPubKeyHash(htlc.SpendingAddress, Net)
 	if err != nil {
-		return nil, "", NewErrDecodeAddress(swap.SpendingAddress, err)
+		return nil, "", NewErrDecodeAddress(htlc.SpendingAddress, err)
 	}
 
 	// creating atomic swap initiate script, addressScriptHash and script to
 	// deposit bitcoin tokens.
-	initiateScript, err := newInitiateScript(
-		FundingAddr.Hash160(),
-		SpendingAddr.Hash160(),
-		swap.TimeLock,
-		swap.SecretHash[:],
+	initiateScript, err := utxo.NewInitiateScript(
+		FundingAddr,
+		SpendingAddr,
+		htlc.TimeLock,
+		htlc.SecretHash[:],
 	)
 	if err != nil {
 		return nil, "", NewErrBuildScript(err)
 	}
-	initiateScriptP2SH, err := btcutil.NewAddressScriptHash(initiateScript, Net)
+	scriptHash := sha256.Sum256(initiateScript)
+	initiateScriptP2WSH, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], Net)
 	if err != nil {
 		return nil, "", NewErrBuildScript(err)
 	}
+	return initiateScript, initiateScriptP2WSH.EncodeAddress(), nil
+}
 
-	return initiateScript, initiateScriptP2SH.EncodeAddress(), nil
+// isWitnessSwap returns true if the swap uses a P2WSH script.
+func isWitnessSwap(htlc swap.Swap) bool {
+	return htlc.BitcoinScript == swap.BitcoinScriptP2WSH
 }
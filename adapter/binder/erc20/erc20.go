@@ -29,18 +29,9 @@ type erc20SwapContractBinder struct {
 	cost           blockchain.Cost
 }
 
-// NewERC20SwapContractBinder returns a new ERC20 Atom instance
-func NewERC20SwapContractBinder(account beth.Account, swap swap.Swap, cost blockchain.Cost, logger logrus.FieldLogger) (immediate.Contract, error) {
-	tokenAddress, err := account.ReadAddress(fmt.Sprintf("%s", swap.Token.Name))
-	if err != nil {
-		return nil, err
-	}
-
-	swapperAddress, err := account.ReadAddress(fmt.Sprintf("%sSwapContract", swap.Token.Name))
-	if err != nil {
-		return nil, err
-	}
-
+// NewERC20SwapContractBinder returns a new ERC20 Atom instance, for the token
+// and swap contracts at the given addresses.
+func NewERC20SwapContractBinder(account beth.Account, tokenAddress, swapperAddress common.Address, swap swap.Swap, cost blockchain.Cost, logger logrus.FieldLogger) (immediate.Contract, error) {
 	tokenBinder, err := NewCompatibleERC20(tokenAddress, bind.ContractBackend(account.EthClient()))
 	if err != nil {
 		return nil, err
@@ -223,6 +214,9 @@ func (atom *erc20SwapContractBinder) AuditSecret() ([32]byte, error) {
 		}
 		return [32]byte{}, immediate.ErrAuditPending
 	}
+	if err := atom.verifyConfirmations(atom.settled); err != nil {
+		return [32]byte{}, err
+	}
 
 	secret, err := atom.swapperBinder.AuditSecret(&bind.CallOpts{}, atom.id)
 	if err != nil {
@@ -249,6 +243,9 @@ func (atom *erc20SwapContractBinder) Audit() error {
 		}
 		return immediate.ErrAuditPending
 	}
+	if err := atom.verifyConfirmations(atom.initiated); err != nil {
+		return err
+	}
 
 	auditReport, err := atom.swapperBinder.Audit(&bind.CallOpts{}, atom.id)
 	if err != nil {
@@ -326,3 +323,50 @@ func (atom *erc20SwapContractBinder) sendValue() *big.Int {
 	}
 	return atom.swap.Value
 }
+
+// verifyConfirmations returns an ErrConfirmationsPending if the swap has not
+// been in a state accepted by the confirmed function for the number of
+// confirmations required by the swap.
+func (atom *erc20SwapContractBinder) verifyConfirmations(confirmed func(opts *bind.CallOpts) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	currentBlock, err := atom.account.CurrentBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The latest block gives one confirmation, so the swap has been confirmed
+	// by depth+1 blocks if it was in the accepted state depth blocks ago.
+	for depth := int64(1); depth < atom.swap.Confirmations; depth++ {
+		blockNumber := new(big.Int).Sub(currentBlock, big.NewInt(depth))
+		if blockNumber.Sign() < 0 {
+			return immediate.NewErrConfirmationsPending(depth, atom.swap.Confirmations)
+		}
+		ok, err := confirmed(&bind.CallOpts{BlockNumber: blockNumber, Context: ctx})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return immediate.NewErrConfirmationsPending(depth, atom.swap.Confirmations)
+		}
+	}
+	return nil
+}
+
+// initiated returns true if the swap had been initiated at the block of the
+// call options.
+func (atom *erc20SwapContractBinder) initiated(opts *bind.CallOpts) (bool, error) {
+	initiatable, err := atom.swapperBinder.Initiatable(opts, atom.id)
+	return !initiatable, err
+}
+
+// settled returns true if the swap had been initiated and could no longer be
+// redeemed at the block of the call options.
+func (atom *erc20SwapContractBinder) settled(opts *bind.CallOpts) (bool, error) {
+	initiated, err := atom.initiated(opts)
+	if err != nil || !initiated {
+		return false, err
+	}
+	redeemable, err := atom.swapperBinder.Redeemable(opts, atom.id)
+	return !redeemable, err
+}
@@ -81,10 +81,13 @@ func (atom *ethSwapContractBinder) Initiate() error {
 			if err != nil {
 				return false
 			}
+			if !initiatable {
+				atom.swap.FeeBump.Confirmed()
+			}
 			return initiatable
 		},
 		func(tops *bind.TransactOpts) (*types.Transaction, error) {
-			tops.GasPrice = atom.swap.Fee
+			atom.prepare(tops)
 			tops.Value = atom.swap.Value
 			var tx *types.Transaction
 			var err error
@@ -94,7 +97,9 @@ func (atom *ethSwapContractBinder) Initiate() error {
 					return tx, err
 				}
 
-				atom.cost[blockchain.ETH] = new(big.Int).Add(atom.cost[blockchain.ETH], atom.swap.BrokerFee)
+				if !atom.swap.FeeBump.Pending() {
+					atom.cost[blockchain.ETH] = new(big.Int).Add(atom.cost[blockchain.ETH], atom.swap.BrokerFee)
+				}
 			} else {
 				tx, err = atom.binder.Initiate(tops, atom.id, common.HexToAddress(atom.swap.SpendingAddress), atom.swap.SecretHash, big.NewInt(atom.swap.TimeLock), atom.swap.Value)
 				if err != nil {
@@ -102,8 +107,7 @@ func (atom *ethSwapContractBinder) Initiate() error {
 				}
 			}
 
-			txFee := new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(tx.Gas())))
-			atom.cost[blockchain.ETH] = new(big.Int).Add(atom.cost[blockchain.ETH], txFee)
+			atom.record(tx)
 
 			tops.Value = big.NewInt(0)
 			msg, _ := atom.account.FormatTransactionView("Initiated the atomic swap", tx.Hash().String())
@@ -115,6 +119,9 @@ func (atom *ethSwapContractBinder) Initiate() error {
 			if err != nil {
 				return false
 			}
+			if !initiatable {
+				atom.swap.FeeBump.Confirmed()
+			}
 			return !initiatable
 		},
 		0,
@@ -138,17 +145,19 @@ func (atom *ethSwapContractBinder) Refund() error {
 			if err != nil {
 				return false
 			}
+			if !refundable {
+				atom.swap.FeeBump.Confirmed()
+			}
 			return refundable
 		},
 		func(tops *bind.TransactOpts) (*types.Transaction, error) {
-			tops.GasPrice = atom.swap.Fee
+			atom.prepare(tops)
 			tx, err := atom.binder.Refund(tops, atom.id)
 			if err != nil {
 				return nil, err
 			}
 
-			txFee := new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(tx.Gas())))
-			atom.cost[blockchain.ETH] = new(big.Int).Add(atom.cost[blockchain.ETH], txFee)
+			atom.record(tx)
 
 			if _, ok := atom.cost[atom.swap.Token.Name]; ok {
 				atom.cost[atom.swap.Token.Name] = new(big.Int).Sub(atom.cost[atom.swap.Token.Name], atom.swap.BrokerFee)
@@ -163,6 +172,9 @@ func (atom *ethSwapContractBinder) Refund() error {
 			if err != nil {
 				return false
 			}
+			if !refundable {
+				atom.swap.FeeBump.Confirmed()
+			}
 			return !refundable
 		},
 		0,
@@ -187,6 +199,9 @@ func (atom *ethSwapContractBinder) AuditSecret() ([32]byte, error) {
 		}
 		return [32]byte{}, immediate.ErrAuditPending
 	}
+	if err := atom.verifyConfirmations(atom.settled); err != nil {
+		return [32]byte{}, err
+	}
 
 	secret, err := atom.binder.AuditSecret(&bind.CallOpts{}, atom.id)
 	if err != nil {
@@ -212,6 +227,9 @@ func (atom *ethSwapContractBinder) Audit() error {
 		}
 		return immediate.ErrAuditPending
 	}
+	if err := atom.verifyConfirmations(atom.initiated); err != nil {
+		return err
+	}
 	auditReport, err := atom.binder.Audit(&bind.CallOpts{}, atom.id)
 	if err != nil {
 		atom.logger.Error(err)
@@ -247,17 +265,19 @@ func (atom *ethSwapContractBinder) Redeem(secret [32]byte) error {
 			if err != nil {
 				return false
 			}
+			if !redeemable {
+				atom.swap.FeeBump.Confirmed()
+			}
 			return redeemable
 		},
 		func(tops *bind.TransactOpts) (*types.Transaction, error) {
-			tops.GasPrice = atom.swap.Fee
+			atom.prepare(tops)
 			tx, err := atom.binder.Redeem(tops, atom.id, common.HexToAddress(atom.swap.WithdrawAddress), secret)
 			if err != nil {
 				return nil, err
 			}
 
-			txFee := new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(tx.Gas())))
-			atom.cost[blockchain.ETH] = new(big.Int).Add(atom.cost[blockchain.ETH], txFee)
+			atom.record(tx)
 
 			msg, _ := atom.account.FormatTransactionView("Redeemed the atomic swap on Ethereum blockchain", tx.Hash().String())
 			atom.logger.Info(msg)
@@ -268,6 +288,9 @@ func (atom *ethSwapContractBinder) Redeem(secret [32]byte) error {
 			if err != nil {
 				return false
 			}
+			if !refundable {
+				atom.swap.FeeBump.Confirmed()
+			}
 			return !refundable
 		},
 		0,
@@ -283,3 +306,75 @@ func (atom *ethSwapContractBinder) Redeem(secret [32]byte) error {
 func (atom *ethSwapContractBinder) Cost() blockchain.Cost {
 	return atom.cost
 }
+
+// prepare sets the gas price of the transaction. If the swap has an
+// unconfirmed transaction, its nonce is reused so that it is replaced.
+func (atom *ethSwapContractBinder) prepare(tops *bind.TransactOpts) {
+	tops.GasPrice = atom.swap.Fee
+	if atom.swap.FeeBump.Pending() {
+		tops.Nonce = new(big.Int).SetUint64(atom.swap.FeeBump.Nonce)
+	}
+}
+
+// record adds the fee of the broadcast transaction to the cost of the swap.
+// Only one of the replacements of a transaction is confirmed, so replacements
+// only add the increase in fee.
+func (atom *ethSwapContractBinder) record(tx *types.Transaction) {
+	gasPrice := tx.GasPrice()
+	if atom.swap.FeeBump.Pending() {
+		gasPrice = new(big.Int).Sub(gasPrice, atom.swap.FeeBump.Fee)
+		if gasPrice.Sign() < 0 {
+			gasPrice = big.NewInt(0)
+		}
+	}
+	txFee := new(big.Int).Mul(gasPrice, big.NewInt(int64(tx.Gas())))
+	atom.cost[blockchain.ETH] = new(big.Int).Add(atom.cost[blockchain.ETH], txFee)
+	atom.swap.FeeBump.Broadcast(tx.Hash().String(), tx.GasPrice(), tx.Nonce(), time.Now().Unix())
+}
+
+// verifyConfirmations returns an ErrConfirmationsPending if the swap has not
+// been in a state accepted by the confirmed function for the number of
+// confirmations required by the swap.
+func (atom *ethSwapContractBinder) verifyConfirmations(confirmed func(opts *bind.CallOpts) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	currentBlock, err := atom.account.CurrentBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The latest block gives one confirmation, so the swap has been confirmed
+	// by depth+1 blocks if it was in the accepted state depth blocks ago.
+	for depth := int64(1); depth < atom.swap.Confirmations; depth++ {
+		blockNumber := new(big.Int).Sub(currentBlock, big.NewInt(depth))
+		if blockNumber.Sign() < 0 {
+			return immediate.NewErrConfirmationsPending(depth, atom.swap.Confirmations)
+		}
+		ok, err := confirmed(&bind.CallOpts{BlockNumber: blockNumber, Context: ctx})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return immediate.NewErrConfirmationsPending(depth, atom.swap.Confirmations)
+		}
+	}
+	return nil
+}
+
+// initiated returns true if the swap had been initiated at the block of the
+// call options.
+func (atom *ethSwapContractBinder) initiated(opts *bind.CallOpts) (bool, error) {
+	initiatable, err := atom.binder.Initiatable(opts, atom.id)
+	return !initiatable, err
+}
+
+// settled returns true if the swap had been initiated and could no longer be
+// redeemed at the block of the call options.
+func (atom *ethSwapContractBinder) settled(opts *bind.CallOpts) (bool, error) {
+	initiated, err := atom.initiated(opts)
+	if err != nil || !initiated {
+		return false, err
+	}
+	redeemable, err := atom.binder.Redeemable(opts, atom.id)
+	return !redeemable, err
+}
@@ -0,0 +1,34 @@
+package binder
+
+import (
+	"time"
+
+	"github.com/renproject/swapperd/core/wallet/swapper/immediate"
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+// timeLockGuard refuses to fund the native contract of a swap when its
+// timelock is no longer safe. Contracts that have already been funded are not
+// affected, so that they can still be redeemed or refunded.
+type timeLockGuard struct {
+	immediate.Contract
+	policy   swap.TimeLockPolicy
+	timeLock int64
+}
+
+// newTimeLockGuard returns a guard that refuses to fund the contract when less
+// than the safety margin is left before the earliest timelock of the swap.
+func newTimeLockGuard(contract immediate.Contract, safetyMargin, earliestTimeLock int64) immediate.Contract {
+	return &timeLockGuard{contract, swap.TimeLockPolicy{SafetyMargin: safetyMargin}, earliestTimeLock}
+}
+
+func (guard *timeLockGuard) Initiate() error {
+	err := guard.Contract.Audit()
+	if _, ok := err.(immediate.ErrConfirmationsPending); err == nil || ok {
+		return guard.Contract.Initiate()
+	}
+	if err := guard.policy.VerifyTimeLock(time.Now().Unix(), guard.timeLock); err != nil {
+		return err
+	}
+	return guard.Contract.Initiate()
+}
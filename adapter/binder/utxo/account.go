@@ -0,0 +1,389 @@
+package utxo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Sequence is the sequence number of the inputs of transactions that are not
+// time locked. It signals opt-in replace-by-fee (BIP 125) on chains that
+// support it.
+const Sequence = wire.MaxTxInSequenceNum - 2
+
+// An Account signs and publishes the transactions of a P2PKH key on a UTXO
+// blockchain.
+type Account interface {
+	Client
+
+	Chain() Chain
+	Address() string
+	SerializedPubKey() []byte
+
+	// Balance returns the value of the unspent outputs of the account.
+	Balance(ctx context.Context) (int64, error)
+
+	// Transfer sends the value to the address, and returns the hash of the
+	// transaction. The change is sent back to the account.
+	Transfer(ctx context.Context, to string, value, fee int64) (string, error)
+
+	// TransferMany sends the value of each output to its address in one
+	// transaction, and returns the hash of the transaction.
+	TransferMany(ctx context.Context, outputs []Output, fee int64) (string, error)
+
+	// TransferReplacing sends the outputs like TransferMany, but spends the
+	// outputs that are spent by the replaced transactions first, and never
+	// spends the outputs that they created. It replaces them if it pays a
+	// higher fee. It returns the transaction, and the outputs that it spends.
+	TransferReplacing(ctx context.Context, outputs []Output, fee int64, replaced Replaced) (*wire.MsgTx, []UTXO, error)
+
+	// TransferFee returns the fee, at the fee rate (in satoshi per vbyte), of
+	// the transaction that TransferMany builds to pay the outputs.
+	TransferFee(ctx context.Context, outputs []Output, feeRate int64) (int64, error)
+
+	// SubtractFee returns the value that is sent to the address when the fee,
+	// at the fee rate, is subtracted from the value, and that fee. A value of
+	// zero spends every unspent output of the account.
+	SubtractFee(ctx context.Context, to string, value, feeRate int64) (int64, int64, error)
+
+	// SpendScript spends the outputs of the P2SH script. The outputs function
+	// returns the outputs of the transaction given the value of the spent
+	// outputs, and the sigScript function returns the signature script of
+	// each input given its signature.
+	SpendScript(ctx context.Context, utxos []UTXO, script []byte, sequence, lockTime uint32, outputs func(value int64) ([]*wire.TxOut, error), sigScript func(sig, pubKey []byte) ([]byte, error)) (*wire.MsgTx, error)
+}
+
+// An Output is the value sent to an address by a transfer.
+type Output struct {
+	To    string
+	Value int64
+}
+
+// A Signer signs the transactions of an Account. It has the signing methods of
+// a btcec.PrivateKey, so that the key can be held by another process.
+type Signer interface {
+	PubKey() *btcec.PublicKey
+	Sign(hash []byte) (*btcec.Signature, error)
+}
+
+type account struct {
+	Client
+	chain   Chain
+	key     Signer
+	address string
+}
+
+// NewAccount returns the Account of the private key on the chain.
+func NewAccount(chain Chain, client Client, privKey *ecdsa.PrivateKey) (Account, error) {
+	return NewSignerAccount(chain, client, (*btcec.PrivateKey)(privKey))
+}
+
+// NewSignerAccount returns the Account of the key of the signer on the chain.
+func NewSignerAccount(chain Chain, client Client, key Signer) (Account, error) {
+	address, err := chain.Format.EncodeAddress(btcutil.Hash160(key.PubKey().SerializeCompressed()), false)
+	if err != nil {
+		return nil, err
+	}
+	return &account{client, chain, key, address}, nil
+}
+
+func (account *account) Chain() Chain {
+	return account.chain
+}
+
+func (account *account) Address() string {
+	return account.address
+}
+
+func (account *account) SerializedPubKey() []byte {
+	return account.key.PubKey().SerializeCompressed()
+}
+
+func (account *account) Balance(ctx context.Context) (int64, error) {
+	utxos, err := account.UnspentOutputs(ctx, account.address)
+	if err != nil {
+		return 0, err
+	}
+	balance := int64(0)
+	for _, utxo := range utxos {
+		balance += utxo.Amount
+	}
+	return balance, nil
+}
+
+func (account *account) Transfer(ctx context.Context, to string, value, fee int64) (string, error) {
+	return account.TransferMany(ctx, []Output{{to, value}}, fee)
+}
+
+func (account *account) TransferMany(ctx context.Context, outputs []Output, fee int64) (string, error) {
+	tx, _, err := account.TransferReplacing(ctx, outputs, fee, Replaced{})
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+func (account *account) TransferReplacing(ctx context.Context, outputs []Output, fee int64, replaced Replaced) (*wire.MsgTx, []UTXO, error) {
+	txOuts, value, err := account.transferOutputs(outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	unspent, err := account.UnspentOutputs(ctx, account.address)
+	if err != nil {
+		return nil, nil, err
+	}
+	utxos := append([]UTXO{}, replaced.Spent...)
+	for _, utxo := range unspent {
+		if !replaced.spends(utxo) && !replaced.Creates(utxo) {
+			utxos = append(utxos, utxo)
+		}
+	}
+	tx, amounts, err := account.buildTransfer(utxos, txOuts, value, fee)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkScript, err := account.chain.PayToAddrScript(account.address)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, amount := range amounts {
+		sig, err := account.sign(tx, i, pkScript, amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		builder := txscript.NewScriptBuilder()
+		builder.AddData(sig).AddData(account.SerializedPubKey())
+		if tx.TxIn[i].SignatureScript, err = builder.Script(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := account.PublishTransaction(ctx, tx); err != nil {
+		return nil, nil, err
+	}
+	return tx, utxos[:len(amounts)], nil
+}
+
+func (account *account) TransferFee(ctx context.Context, outputs []Output, feeRate int64) (int64, error) {
+	txOuts, value, err := account.transferOutputs(outputs)
+	if err != nil {
+		return 0, err
+	}
+	utxos, err := account.UnspentOutputs(ctx, account.address)
+	if err != nil {
+		return 0, err
+	}
+
+	// A higher fee can select more inputs, so the transaction is rebuilt until
+	// its fee covers its size. The fee never decreases, so this terminates
+	// once every input is selected.
+	fee := int64(0)
+	for {
+		tx, _, err := account.buildTransfer(utxos, txOuts, value, fee)
+		if err != nil {
+			return 0, err
+		}
+		size := SignedSize(tx, P2PKHSigScriptSize)
+		if size*feeRate <= fee {
+			return fee, nil
+		}
+		fee = size * feeRate
+	}
+}
+
+func (account *account) SubtractFee(ctx context.Context, to string, value, feeRate int64) (int64, int64, error) {
+	utxos, err := account.UnspentOutputs(ctx, account.address)
+	if err != nil {
+		return 0, 0, err
+	}
+	balance := int64(0)
+	for _, utxo := range utxos {
+		balance += utxo.Amount
+	}
+	if value == 0 {
+		value = balance
+	}
+	if value > balance {
+		return 0, 0, fmt.Errorf("cannot transfer %d: the balance is %d", value, balance)
+	}
+
+	// The transfer and its fee spend exactly the value, so the inputs that
+	// cover the value are always selected, and the fee only grows.
+	fee := int64(0)
+	for {
+		if value-fee < account.chain.Dust {
+			return 0, 0, fmt.Errorf("cannot transfer %d: it does not cover the fee %d", value, fee)
+		}
+		txOuts, _, err := account.transferOutputs([]Output{{to, value - fee}})
+		if err != nil {
+			return 0, 0, err
+		}
+		tx, _, err := account.buildTransfer(utxos, txOuts, value-fee, fee)
+		if err != nil {
+			return 0, 0, err
+		}
+		size := SignedSize(tx, P2PKHSigScriptSize)
+		if size*feeRate <= fee {
+			return value - fee, fee, nil
+		}
+		fee = size * feeRate
+	}
+}
+
+// transferOutputs returns the outputs of a transfer, and their total value.
+func (account *account) transferOutputs(outputs []Output) ([]*wire.TxOut, int64, error) {
+	if len(outputs) == 0 {
+		return nil, 0, fmt.Errorf("cannot transfer to no outputs")
+	}
+	value, txOuts := int64(0), make([]*wire.TxOut, len(outputs))
+	for i, output := range outputs {
+		if output.Value < account.chain.Dust {
+			return nil, 0, fmt.Errorf("cannot transfer %d: less than the dust limit %d", output.Value, account.chain.Dust)
+		}
+		toScript, err := account.chain.PayToAddrScript(output.To)
+		if err != nil {
+			return nil, 0, err
+		}
+		value += output.Value
+		txOuts[i] = wire.NewTxOut(output.Value, toScript)
+	}
+	return txOuts, value, nil
+}
+
+// buildTransfer returns the unsigned transaction that pays the outputs and the
+// fee from the first unspent outputs that cover them, and the value of each of
+// its inputs. The change is sent back to the account if it is not dust.
+func (account *account) buildTransfer(utxos []UTXO, txOuts []*wire.TxOut, value, fee int64) (*wire.MsgTx, []int64, error) {
+	pkScript, err := account.chain.PayToAddrScript(account.address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx := wire.NewMsgTx(2)
+	selected, amounts := int64(0), []int64{}
+	for _, utxo := range utxos {
+		if selected >= value+fee {
+			break
+		}
+		if err := addInput(tx, utxo, Sequence); err != nil {
+			return nil, nil, err
+		}
+		selected += utxo.Amount
+		amounts = append(amounts, utxo.Amount)
+	}
+	if selected < value+fee {
+		return nil, nil, fmt.Errorf("insufficient balance: need %d, have %d", value+fee, selected)
+	}
+	for _, txOut := range txOuts {
+		tx.AddTxOut(txOut)
+	}
+	if change := selected - value - fee; change >= account.chain.Dust {
+		tx.AddTxOut(wire.NewTxOut(change, pkScript))
+	}
+	return tx, amounts, nil
+}
+
+func (account *account) SpendScript(ctx context.Context, utxos []UTXO, script []byte, sequence, lockTime uint32, outputs func(value int64) ([]*wire.TxOut, error), sigScript func(sig, pubKey []byte) ([]byte, error)) (*wire.MsgTx, error) {
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("script is not funded")
+	}
+
+	tx := wire.NewMsgTx(2)
+	value := int64(0)
+	for _, utxo := range utxos {
+		if err := addInput(tx, utxo, sequence); err != nil {
+			return nil, err
+		}
+		value += utxo.Amount
+	}
+	txOuts, err := outputs(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, txOut := range txOuts {
+		tx.AddTxOut(txOut)
+	}
+	tx.LockTime = lockTime
+
+	for i, utxo := range utxos {
+		sig, err := account.sign(tx, i, script, utxo.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if tx.TxIn[i].SignatureScript, err = sigScript(sig, account.SerializedPubKey()); err != nil {
+			return nil, err
+		}
+	}
+	if err := account.PublishTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// sign returns the signature of the input, followed by the sighash type.
+func (account *account) sign(tx *wire.MsgTx, idx int, subScript []byte, value int64) ([]byte, error) {
+	hash, err := account.chain.SigHash(tx, idx, subScript, value)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := account.key.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig.Serialize(), byte(account.chain.HashType())), nil
+}
+
+// P2PKHSigScriptSize is the largest size of the signature script that spends a
+// P2PKH output; a DER signature with its sighash type, and a compressed public
+// key.
+const P2PKHSigScriptSize = 1 + 73 + 1 + 33
+
+// SignedSize returns the size of the transaction once every input has a
+// signature script of the size.
+func SignedSize(tx *wire.MsgTx, sigScriptSize int) int64 {
+	signed := tx.Copy()
+	for _, txIn := range signed.TxIn {
+		txIn.SignatureScript = make([]byte, sigScriptSize)
+	}
+	return int64(signed.SerializeSize())
+}
+
+// SwapTxSize returns the size of the largest transaction of a P2SH swap;
+// redeeming the swap script to a withdraw and a broker address, which can both
+// be P2WSH addresses. It also bounds the size of P2WSH swaps, whose witnesses
+// are discounted.
+func SwapTxSize() (int64, error) {
+	script, err := NewInitiateScript(&[ripemd160.Size]byte{}, &[ripemd160.Size]byte{}, math.MaxUint32, make([]byte, sha256.Size))
+	if err != nil {
+		return 0, err
+	}
+	sigScript, err := NewRedeemScript(script, make([]byte, 73), make([]byte, 33), [32]byte{})
+	if err != nil {
+		return 0, err
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, sigScript, nil))
+	tx.AddTxOut(wire.NewTxOut(0, make([]byte, 34)))
+	tx.AddTxOut(wire.NewTxOut(0, make([]byte, 34)))
+	return int64(tx.SerializeSize()), nil
+}
+
+func addInput(tx *wire.MsgTx, utxo UTXO, sequence uint32) error {
+	hash, err := chainhash.NewHashFromStr(utxo.TxHash)
+	if err != nil {
+		return err
+	}
+	txIn := wire.NewTxIn(wire.NewOutPoint(hash, utxo.Vout), nil, nil)
+	txIn.Sequence = sequence
+	tx.AddTxIn(txIn)
+	return nil
+}
@@ -0,0 +1,186 @@
+package utxo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// An AddressFormat encodes and decodes the P2PKH and P2SH addresses of a chain.
+type AddressFormat interface {
+	EncodeAddress(hash []byte, scriptHash bool) (string, error)
+	DecodeAddress(address string) (hash []byte, scriptHash bool, err error)
+}
+
+type base58Format struct {
+	params *chaincfg.Params
+}
+
+// NewBase58Format returns the legacy base58check AddressFormat, using the
+// address version bytes of the chain params.
+func NewBase58Format(params *chaincfg.Params) AddressFormat {
+	return base58Format{params}
+}
+
+func (format base58Format) EncodeAddress(hash []byte, scriptHash bool) (string, error) {
+	if len(hash) != ripemd160.Size {
+		return "", fmt.Errorf("invalid hash length: %d", len(hash))
+	}
+	if scriptHash {
+		return base58.CheckEncode(hash, format.params.ScriptHashAddrID), nil
+	}
+	return base58.CheckEncode(hash, format.params.PubKeyHashAddrID), nil
+}
+
+func (format base58Format) DecodeAddress(address string) ([]byte, bool, error) {
+	hash, version, err := base58.CheckDecode(address)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s address %s: %v", format.params.Name, address, err)
+	}
+	if len(hash) != ripemd160.Size {
+		return nil, false, fmt.Errorf("invalid %s address %s: invalid length", format.params.Name, address)
+	}
+	switch version {
+	case format.params.PubKeyHashAddrID:
+		return hash, false, nil
+	case format.params.ScriptHashAddrID:
+		return hash, true, nil
+	default:
+		return nil, false, fmt.Errorf("address %s is not intended for use on %s", address, format.params.Name)
+	}
+}
+
+const cashAddrCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+type cashAddrFormat struct {
+	prefix string
+	legacy AddressFormat
+}
+
+// NewCashAddrFormat returns the CashAddr AddressFormat of bitcoin cash, with
+// the prefix of the network. Legacy base58check addresses are decoded using
+// the chain params.
+func NewCashAddrFormat(prefix string, params *chaincfg.Params) AddressFormat {
+	return cashAddrFormat{prefix, NewBase58Format(params)}
+}
+
+func (format cashAddrFormat) EncodeAddress(hash []byte, scriptHash bool) (string, error) {
+	if len(hash) != ripemd160.Size {
+		return "", fmt.Errorf("invalid hash length: %d", len(hash))
+	}
+
+	// The version byte holds the address type and the size of the hash, which
+	// is always 0 (160 bits).
+	version := byte(0)
+	if scriptHash {
+		version = 8
+	}
+	payload := convertBits(append([]byte{version}, hash...), 8, 5, true)
+	checksum := cashAddrPolymod(append(append(cashAddrPrefix(format.prefix), payload...), make([]byte, 8)...))
+	for i := 0; i < 8; i++ {
+		payload = append(payload, byte(checksum>>uint(5*(7-i)))&0x1F)
+	}
+
+	encoded := make([]byte, len(payload))
+	for i, b := range payload {
+		encoded[i] = cashAddrCharset[b]
+	}
+	return format.prefix + ":" + string(encoded), nil
+}
+
+func (format cashAddrFormat) DecodeAddress(address string) ([]byte, bool, error) {
+	if !strings.Contains(address, ":") {
+		if hash, scriptHash, err := format.legacy.DecodeAddress(address); err == nil {
+			return hash, scriptHash, nil
+		}
+	}
+	lower := strings.ToLower(address)
+	if lower != address && strings.ToUpper(address) != address {
+		return nil, false, fmt.Errorf("invalid cashaddr %s: mixed case", address)
+	}
+	prefix, data := format.prefix, lower
+	if i := strings.LastIndexByte(lower, ':'); i >= 0 {
+		prefix, data = lower[:i], lower[i+1:]
+	}
+	if prefix != format.prefix {
+		return nil, false, fmt.Errorf("address %s is not intended for use on %s", address, format.prefix)
+	}
+
+	payload := make([]byte, len(data))
+	for i := range data {
+		index := strings.IndexByte(cashAddrCharset, data[i])
+		if index < 0 {
+			return nil, false, fmt.Errorf("invalid cashaddr %s: invalid character %q", address, data[i])
+		}
+		payload[i] = byte(index)
+	}
+	if len(payload) < 8 || cashAddrPolymod(append(cashAddrPrefix(prefix), payload...)) != 0 {
+		return nil, false, fmt.Errorf("invalid cashaddr %s: invalid checksum", address)
+	}
+
+	decoded := convertBits(payload[:len(payload)-8], 5, 8, false)
+	if decoded == nil || len(decoded) != ripemd160.Size+1 {
+		return nil, false, fmt.Errorf("invalid cashaddr %s: invalid length", address)
+	}
+	switch decoded[0] {
+	case 0:
+		return decoded[1:], false, nil
+	case 8:
+		return decoded[1:], true, nil
+	default:
+		return nil, false, fmt.Errorf("invalid cashaddr %s: unknown version %d", address, decoded[0])
+	}
+}
+
+// cashAddrPrefix returns the lower 5 bits of each character of the prefix,
+// followed by the separator, as they are used by the checksum.
+func cashAddrPrefix(prefix string) []byte {
+	data := make([]byte, len(prefix)+1)
+	for i := range prefix {
+		data[i] = prefix[i] & 0x1F
+	}
+	return data
+}
+
+// cashAddrPolymod is the BCH code checksum of the CashAddr specification.
+func cashAddrPolymod(values []byte) uint64 {
+	generators := [5]uint64{0x98F2BC8E61, 0x79B76D99E2, 0xF33E5FB3C4, 0xAE2EABE2A8, 0x1E4F43E470}
+	c := uint64(1)
+	for _, d := range values {
+		c0 := byte(c >> 35)
+		c = ((c & 0x07FFFFFFFF) << 5) ^ uint64(d)
+		for i, generator := range generators {
+			if (c0>>uint(i))&1 == 1 {
+				c ^= generator
+			}
+		}
+	}
+	return c ^ 1
+}
+
+// convertBits regroups the data from groups of fromBits to groups of toBits. It
+// returns nil if the data cannot be regrouped without padding.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	acc, bits := uint(0), uint(0)
+	maxv := uint(1)<<toBits - 1
+	converted := []byte{}
+	for _, value := range data {
+		acc = acc<<fromBits | uint(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			converted = append(converted, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			converted = append(converted, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil
+	}
+	return converted
+}
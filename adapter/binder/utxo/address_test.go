@@ -0,0 +1,131 @@
+package utxo_test
+
+import (
+	"encoding/hex"
+
+	"github.com/btcsuite/btcutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/binder/utxo"
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+var _ = Describe("Address Formats", func() {
+	Context("when encoding bitcoin cash addresses", func() {
+		bch := BitcoinCash("mainnet")
+		hash, _ := hex.DecodeString("76a04053bda0a88bda5177b86a15c3b29f559873")
+
+		It("should encode cashaddr addresses", func() {
+			address, err := bch.Format.EncodeAddress(hash, false)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(address).Should(Equal("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"))
+
+			address, err = bch.Format.EncodeAddress(hash, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(address).Should(Equal("bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq"))
+		})
+
+		It("should decode cashaddr and legacy addresses", func() {
+			for _, address := range []string{
+				"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+				"qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+				"BITCOINCASH:QPM2QSZNHKS23Z7629MMS6S4CWEF74VCWVY22GDX6A",
+				"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+			} {
+				decoded, scriptHash, err := bch.Format.DecodeAddress(address)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(decoded).Should(Equal(hash))
+				Expect(scriptHash).Should(BeFalse())
+			}
+
+			decoded, scriptHash, err := bch.Format.DecodeAddress("3CWFddi6m4ndiGyKqzYvsFYagqDLPVMTzC")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(decoded).Should(Equal(hash))
+			Expect(scriptHash).Should(BeTrue())
+		})
+
+		It("should reject invalid addresses", func() {
+			for _, address := range []string{
+				"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b",
+				"bchtest:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+				"bitcoincash:Qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+				"",
+			} {
+				_, _, err := bch.Format.DecodeAddress(address)
+				Expect(err).Should(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when encoding litecoin addresses", func() {
+		It("should use the litecoin version bytes", func() {
+			hash := make([]byte, 20)
+			for _, network := range []string{"mainnet", "testnet"} {
+				ltc := Litecoin(network)
+				for _, scriptHash := range []bool{false, true} {
+					address, err := ltc.Format.EncodeAddress(hash, scriptHash)
+					Expect(err).ShouldNot(HaveOccurred())
+					decoded, decodedScriptHash, err := ltc.Format.DecodeAddress(address)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(decoded).Should(Equal(hash))
+					Expect(decodedScriptHash).Should(Equal(scriptHash))
+				}
+			}
+
+			address, err := Litecoin("mainnet").Format.EncodeAddress(hash, false)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(address[0]).Should(Equal(byte('L')))
+		})
+
+		It("should reject bitcoin addresses", func() {
+			_, _, err := Litecoin("mainnet").Format.DecodeAddress("1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when paying to bitcoin addresses", func() {
+		btc := Bitcoin("mainnet")
+
+		It("should pay to legacy and native segwit addresses", func() {
+			for address, script := range map[string]string{
+				"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu":         "76a91476a04053bda0a88bda5177b86a15c3b29f55987388ac",
+				"3CWFddi6m4ndiGyKqzYvsFYagqDLPVMTzC":         "a91476a04053bda0a88bda5177b86a15c3b29f55987387",
+				"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+			} {
+				pkScript, err := btc.PayToAddrScript(address)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(hex.EncodeToString(pkScript)).Should(Equal(script))
+			}
+		})
+
+		It("should not pay to native segwit addresses of other chains", func() {
+			_, err := Litecoin("mainnet").PayToAddrScript("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+			Expect(err).Should(HaveOccurred())
+			_, err = btc.PayToAddrScript("tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should build the same swap script for legacy and native segwit addresses", func() {
+			legacy := swap.Swap{
+				FundingAddress:  "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+				SpendingAddress: "1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+				TimeLock:        1000,
+			}
+			script, _, err := BuildInitiateScript(btc, legacy)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			hash, _ := hex.DecodeString("76a04053bda0a88bda5177b86a15c3b29f559873")
+			witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(hash, btc.Params)
+			Expect(err).ShouldNot(HaveOccurred())
+			witness := legacy
+			witness.FundingAddress = witnessAddr.EncodeAddress()
+			witnessScript, _, err := BuildInitiateScript(btc, witness)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(witnessScript).Should(Equal(script))
+
+			witness.FundingAddress = "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3"
+			_, _, err = BuildInitiateScript(btc, witness)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
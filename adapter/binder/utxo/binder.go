@@ -0,0 +1,362 @@
+package utxo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/renproject/swapperd/core/wallet/swapper/immediate"
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/renproject/swapperd/foundation/swap"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ripemd160"
+)
+
+type swapContractBinder struct {
+	scriptAddr string
+	script     []byte
+	swap       swap.Swap
+	fee        int64
+	chain      Chain
+	cost       blockchain.Cost
+	logrus.FieldLogger
+	Account
+}
+
+// NewSwapContractBinder returns a new Atomic Swap instance for the chain of the
+// account. The swap is locked in a P2SH script.
+func NewSwapContractBinder(account Account, htlc swap.Swap, cost blockchain.Cost, logger logrus.FieldLogger) (immediate.Contract, error) {
+	chain := account.Chain()
+	script, scriptAddr, err := BuildInitiateScript(chain, htlc)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := logrus.Fields{}
+	fields["SwapID"] = htlc.ID
+	fields["ContractID"] = scriptAddr
+	fields["Token"] = htlc.Token.Name
+	logger = logger.WithFields(fields)
+
+	if _, ok := cost[chain.Token.Name]; !ok {
+		cost[chain.Token.Name] = big.NewInt(0)
+	}
+
+	if htlc.BrokerFee.Int64() != 0 && htlc.BrokerFee.Int64() < chain.Dust {
+		htlc.BrokerFee = big.NewInt(chain.Dust)
+	}
+
+	htlc.Value = new(big.Int).Add(htlc.Value, htlc.BrokerFee)
+
+	logger.Info(htlc.ID, fmt.Sprintf("%s atomic swap = %s", chain.Token.Name, scriptAddr))
+	return &swapContractBinder{
+		scriptAddr:  scriptAddr,
+		script:      script,
+		swap:        htlc,
+		fee:         htlc.Fee.Int64(),
+		chain:       chain,
+		cost:        cost,
+		FieldLogger: logger,
+		Account:     account,
+	}, nil
+}
+
+// Initiate the atomic swap by funding a HTLC.
+func (atom *swapContractBinder) Initiate() error {
+	atom.Info(fmt.Sprintf("Initiating on %s blockchain", atom.chain.Name))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// An unconfirmed funding transaction that pays less than the bumped fee is
+	// replaced by a transaction that spends its inputs again.
+	bump := atom.swap.FeeBump
+	replaced := Replaced{}
+	if Replaceable(ctx, atom.Account, bump, atom.fee) {
+		replaced = NewReplaced(bump)
+	}
+	value, err := atom.scriptValue(ctx, replaced)
+	if err != nil {
+		return NewErrInitiate(err)
+	}
+	if value >= atom.swap.Value.Int64() {
+		atom.Info(fmt.Sprintf("Send value on %s blockchain = %d", atom.chain.Name, atom.swap.Value.Int64()))
+		return nil
+	}
+	if atom.swap.Value.Int64()-value < atom.chain.Dust {
+		return nil
+	}
+
+	tx, spent, err := atom.TransferReplacing(ctx, []Output{{To: atom.scriptAddr, Value: atom.swap.Value.Int64() - value}}, atom.fee, replaced)
+	if err != nil {
+		return NewErrInitiate(err)
+	}
+	if len(replaced.TxHashes) == 0 {
+		bump.Reset()
+		atom.addCost(atom.swap.BrokerFee.Int64())
+	}
+	atom.record(tx, spent)
+	atom.Info(atom.chain.FormatTransactionView(fmt.Sprintf("Initiated on %s blockchain", atom.chain.Name), tx.TxHash().String()))
+	return nil
+}
+
+func (atom *swapContractBinder) Audit() error {
+	ctx := context.Background()
+	if value, err := atom.scriptValue(ctx, Replaced{}); err == nil && value >= atom.swap.Value.Int64() {
+		if value < new(big.Int).Sub(atom.swap.Value, atom.swap.BrokerFee).Int64() {
+			return fmt.Errorf("Audit Failed")
+		}
+		confirmations, err := atom.scriptConfirmations(ctx, atom.swap.Value.Int64())
+		if err != nil {
+			return err
+		}
+		if confirmations < atom.swap.Confirmations {
+			return immediate.NewErrConfirmationsPending(confirmations, atom.swap.Confirmations)
+		}
+		return nil
+	}
+
+	if time.Now().Unix() > atom.swap.TimeLock {
+		return immediate.ErrSwapExpired
+	}
+	return immediate.ErrAuditPending
+}
+
+// Redeem the Atomic Swap by revealing the secret and withdrawing funds from the
+// HTLC.
+func (atom *swapContractBinder) Redeem(secret [32]byte) error {
+	atom.Info(fmt.Sprintf("Redeeming on %s blockchain", atom.chain.Name))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	payToAddrScript, err := atom.chain.PayToAddrScript(atom.swap.WithdrawAddress)
+	if err != nil {
+		return NewErrRedeem(NewErrDecodeAddress(atom.swap.WithdrawAddress, err))
+	}
+
+	var feeAddrScript []byte
+	if atom.swap.BrokerFee.Int64() != 0 {
+		feeAddrScript, err = atom.chain.PayToAddrScript(atom.swap.BrokerAddress)
+		if err != nil {
+			return NewErrRedeem(NewErrDecodeAddress(atom.swap.BrokerAddress, err))
+		}
+	}
+
+	utxos, err := atom.spendableOutputs(ctx)
+	if err != nil {
+		return NewErrRedeem(err)
+	}
+	if len(utxos) == 0 {
+		atom.Info(fmt.Sprintf("Skipping redeem on %s blockchain", atom.chain.Name))
+		return nil
+	}
+
+	tx, err := atom.SpendScript(ctx, utxos, atom.script, Sequence, 0, func(value int64) ([]*wire.TxOut, error) {
+		withdrawValue := value - atom.swap.BrokerFee.Int64() - atom.fee
+		if withdrawValue < atom.chain.Dust {
+			return nil, fmt.Errorf("swap value %d is too low to redeem", value)
+		}
+		outputs := []*wire.TxOut{wire.NewTxOut(withdrawValue, payToAddrScript)}
+		if atom.swap.BrokerFee.Int64() != 0 {
+			outputs = append(outputs, wire.NewTxOut(atom.swap.BrokerFee.Int64(), feeAddrScript))
+		}
+		return outputs, nil
+	}, func(sig, pubKey []byte) ([]byte, error) {
+		return NewRedeemScript(atom.script, sig, pubKey, secret)
+	})
+	if err != nil {
+		return NewErrRedeem(err)
+	}
+	atom.record(tx, utxos)
+	atom.Info(atom.chain.FormatTransactionView(fmt.Sprintf("Redeemed on %s blockchain", atom.chain.Name), tx.TxHash().String()))
+	return nil
+}
+
+func (atom *swapContractBinder) AuditSecret() ([32]byte, error) {
+	atom.Info(fmt.Sprintf("Auditing secret on %s blockchain", atom.chain.Name))
+	sigScript, spent, err := atom.SpendingScript(context.Background(), atom.scriptAddr)
+	if err != nil || !spent {
+		if time.Now().Unix() > atom.swap.TimeLock {
+			return [32]byte{}, immediate.ErrSwapExpired
+		}
+		return [32]byte{}, immediate.ErrAuditPending
+	}
+
+	pushes, err := txscript.PushedData(sigScript)
+	if err != nil {
+		return [32]byte{}, NewErrAuditSecret(err)
+	}
+	for _, push := range pushes {
+		if sha256.Sum256(push) == atom.swap.SecretHash {
+			var secret [32]byte
+			copy(secret[:], push)
+			atom.Info(fmt.Sprintf("Audit succeeded on %s blockchain secret = %s", atom.chain.Name, base64.StdEncoding.EncodeToString(secret[:])))
+			return secret, nil
+		}
+	}
+	return [32]byte{}, NewErrAuditSecret(ErrMalformedRedeemTx)
+}
+
+// Refund the Atomic Swap after expiry and withdraw funds from the HTLC.
+func (atom *swapContractBinder) Refund() error {
+	atom.Info(fmt.Sprintf("Refunding on %s blockchain", atom.chain.Name))
+	payToAddrScript, err := atom.chain.PayToAddrScript(atom.Address())
+	if err != nil {
+		return NewErrRefund(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	utxos, err := atom.spendableOutputs(ctx)
+	if err != nil {
+		return NewErrRefund(err)
+	}
+	if len(utxos) == 0 {
+		atom.Info(fmt.Sprintf("Skipping refund on %s blockchain", atom.chain.Name))
+		return nil
+	}
+
+	tx, err := atom.SpendScript(ctx, utxos, atom.script, 0, uint32(atom.swap.TimeLock), func(value int64) ([]*wire.TxOut, error) {
+		if value-atom.fee < atom.chain.Dust {
+			return nil, fmt.Errorf("swap value %d is too low to refund", value)
+		}
+		return []*wire.TxOut{wire.NewTxOut(value-atom.fee, payToAddrScript)}, nil
+	}, func(sig, pubKey []byte) ([]byte, error) {
+		return NewRefundScript(atom.script, sig, pubKey)
+	})
+	if err != nil {
+		return NewErrRefund(err)
+	}
+	if !atom.swap.FeeBump.Pending() {
+		atom.addCost(-atom.swap.BrokerFee.Int64())
+	}
+	atom.record(tx, utxos)
+	atom.Info(atom.chain.FormatTransactionView(fmt.Sprintf("Refunded on %s blockchain", atom.chain.Name), tx.TxHash().String()))
+	return nil
+}
+
+func (atom *swapContractBinder) Cost() blockchain.Cost {
+	return atom.cost
+}
+
+// scriptValue returns the value of the unspent outputs of the swap script that
+// were not created by the replaced transactions.
+func (atom *swapContractBinder) scriptValue(ctx context.Context, replaced Replaced) (int64, error) {
+	utxos, err := atom.UnspentOutputs(ctx, atom.scriptAddr)
+	if err != nil {
+		return 0, err
+	}
+	value := int64(0)
+	for _, utxo := range utxos {
+		if !replaced.Creates(utxo) {
+			value += utxo.Amount
+		}
+	}
+	return value, nil
+}
+
+// spendableOutputs returns the outputs of the swap script that are spent by the
+// redeem or refund transaction. They are the unspent outputs of the script, or
+// the outputs spent by an unconfirmed transaction that pays less than the
+// bumped fee. No outputs are returned once the script has been spent.
+func (atom *swapContractBinder) spendableOutputs(ctx context.Context) ([]UTXO, error) {
+	bump := atom.swap.FeeBump
+	if Replaceable(ctx, atom.Account, bump, atom.fee) {
+		return NewReplaced(bump).Spent, nil
+	}
+	_, spent, err := atom.SpendingScript(ctx, atom.scriptAddr)
+	if err != nil || spent {
+		return nil, err
+	}
+	bump.Reset()
+	return atom.UnspentOutputs(ctx, atom.scriptAddr)
+}
+
+// record adds the fee of the transaction to the cost of the swap, and records
+// it in the fee bump. Only one of the replacements of a transaction is
+// confirmed, so replacements only add the increase in fee.
+func (atom *swapContractBinder) record(tx *wire.MsgTx, spent []UTXO) {
+	fee := atom.fee
+	if atom.swap.FeeBump.Pending() {
+		fee -= atom.swap.FeeBump.Fee.Int64()
+	}
+	atom.addCost(fee)
+	RecordBroadcast(atom.swap.FeeBump, tx, spent, atom.fee)
+}
+
+// scriptConfirmations returns the number of confirmations of the value funding
+// the swap script, up to the number of confirmations required by the swap.
+func (atom *swapContractBinder) scriptConfirmations(ctx context.Context, value int64) (int64, error) {
+	utxos, err := atom.UnspentOutputs(ctx, atom.scriptAddr)
+	if err != nil {
+		return 0, err
+	}
+	for confirmations := int64(1); confirmations <= atom.swap.Confirmations; confirmations++ {
+		balance := int64(0)
+		for _, utxo := range utxos {
+			if utxo.Confirmations >= confirmations {
+				balance += utxo.Amount
+			}
+		}
+		if balance < value {
+			return confirmations - 1, nil
+		}
+	}
+	return atom.swap.Confirmations, nil
+}
+
+func (atom *swapContractBinder) addCost(value int64) {
+	atom.cost[atom.chain.Token.Name] = new(big.Int).Add(big.NewInt(value), atom.cost[atom.chain.Token.Name])
+}
+
+// BuildInitiateScript returns the initiate script of the swap, and the address
+// of its P2SH output on the chain.
+func BuildInitiateScript(chain Chain, htlc swap.Swap) ([]byte, string, error) {
+	fundingAddr, err := addressToPubKeyHash(chain, htlc.FundingAddress)
+	if err != nil {
+		return nil, "", NewErrDecodeAddress(htlc.FundingAddress, err)
+	}
+
+	spendingAddr, err := addressToPubKeyHash(chain, htlc.SpendingAddress)
+	if err != nil {
+		return nil, "", NewErrDecodeAddress(htlc.SpendingAddress, err)
+	}
+
+	initiateScript, err := NewInitiateScript(fundingAddr, spendingAddr, htlc.TimeLock, htlc.SecretHash[:])
+	if err != nil {
+		return nil, "", NewErrBuildScript(err)
+	}
+	scriptAddr, err := chain.Format.EncodeAddress(btcutil.Hash160(initiateScript), true)
+	if err != nil {
+		return nil, "", NewErrBuildScript(err)
+	}
+	return initiateScript, scriptAddr, nil
+}
+
+// addressToPubKeyHash returns the public key hash of a P2PKH address, or of a
+// P2WPKH address on a segwit chain.
+func addressToPubKeyHash(chain Chain, address string) (*[ripemd160.Size]byte, error) {
+	if addr, ok := chain.witnessAddress(address); ok {
+		witnessAddr, ok := addr.(*btcutil.AddressWitnessPubKeyHash)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a p2pkh or p2wpkh address", address)
+		}
+		return witnessAddr.Hash160(), nil
+	}
+	hash, scriptHash, err := chain.Format.DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if scriptHash {
+		return nil, fmt.Errorf("%s is not a p2pkh address", address)
+	}
+	pkh := [ripemd160.Size]byte{}
+	copy(pkh[:], hash)
+	return &pkh, nil
+}
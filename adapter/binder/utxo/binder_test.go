@@ -0,0 +1,347 @@
+package utxo_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/core/wallet/swapper/immediate"
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/renproject/swapperd/foundation/swap"
+	"github.com/sirupsen/logrus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/binder/utxo"
+)
+
+var _ = Describe("UTXO Swap Contract Binder", func() {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	newAccount := func(chain Chain, client Client) Account {
+		key, err := crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+		account, err := NewAccount(chain, client, key)
+		Expect(err).ShouldNot(HaveOccurred())
+		return account
+	}
+
+	balance := func(account Account) int64 {
+		balance, err := account.Balance(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		return balance
+	}
+
+	// init returns the binders of the initiator and the redeemer of a swap of
+	// the value, with the initiator's account funded.
+	init := func(chain Chain, value int64, timeLock int64, confirmations int64) (*regtest, Account, Account, immediate.Contract, immediate.Contract, [32]byte) {
+		client := newRegtest(chain)
+		alice, bob := newAccount(chain, client), newAccount(chain, client)
+		client.fund(alice.Address(), 3*value)
+		client.fund(alice.Address(), 2*value)
+
+		secret := [32]byte{}
+		_, err := rand.Read(secret[:])
+		Expect(err).ShouldNot(HaveOccurred())
+
+		htlc := swap.Swap{
+			ID:              swap.SwapID("swap"),
+			Token:           chain.Token,
+			Value:           big.NewInt(value),
+			Fee:             big.NewInt(1000),
+			SecretHash:      secretHash(secret),
+			TimeLock:        timeLock,
+			FundingAddress:  alice.Address(),
+			SpendingAddress: bob.Address(),
+			WithdrawAddress: bob.Address(),
+			BrokerFee:       big.NewInt(0),
+			FeeBump:         swap.NewFeeBump(),
+			Confirmations:   confirmations,
+		}
+		initiator, err := NewSwapContractBinder(alice, htlc, blockchain.Cost{}, logger)
+		Expect(err).ShouldNot(HaveOccurred())
+		redeemer, err := NewSwapContractBinder(bob, htlc, blockchain.Cost{}, logger)
+		Expect(err).ShouldNot(HaveOccurred())
+		return client, alice, bob, initiator, redeemer, secret
+	}
+
+	// stalledSwap returns a swap of 100000 from alice to bob, with a fee of
+	// 1000.
+	stalledSwap := func(chain Chain, alice, bob Account, timeLock int64) swap.Swap {
+		return swap.Swap{
+			ID:              swap.SwapID("swap"),
+			Token:           chain.Token,
+			Value:           big.NewInt(100000),
+			Fee:             big.NewInt(1000),
+			TimeLock:        timeLock,
+			FundingAddress:  alice.Address(),
+			SpendingAddress: bob.Address(),
+			WithdrawAddress: bob.Address(),
+			BrokerFee:       big.NewInt(0),
+			FeeBump:         swap.NewFeeBump(),
+			Confirmations:   1,
+		}
+	}
+
+	// restart returns the swap with its fee bump restored from storage, and
+	// its fee bumped by a quarter.
+	restart := func(htlc swap.Swap) swap.Swap {
+		data, err := json.Marshal(htlc.FeeBump)
+		Expect(err).ShouldNot(HaveOccurred())
+		htlc.FeeBump = new(swap.FeeBump)
+		Expect(json.Unmarshal(data, htlc.FeeBump)).ShouldNot(HaveOccurred())
+
+		htlc.FeeBump.Bump(time.Now().Unix(), htlc.TimeLock)
+		htlc.Fee = htlc.FeeBump.Apply(big.NewInt(1000))
+		return htlc
+	}
+
+	for _, chain := range []Chain{Litecoin("testnet"), BitcoinCash("testnet")} {
+		chain := chain
+
+		Context("when swapping "+string(chain.Token.Name), func() {
+			It("should initiate, audit, redeem and audit the secret", func() {
+				_, alice, bob, initiator, redeemer, secret := init(chain, 100000, time.Now().Unix()+3600, 1)
+
+				Expect(redeemer.Audit()).Should(Equal(immediate.ErrAuditPending))
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				Expect(balance(alice)).Should(Equal(int64(500000 - 100000 - 1000)))
+				Expect(initiator.Cost()[chain.Token.Name].Int64()).Should(Equal(int64(1000)))
+
+				// Initiating again does not fund the script twice.
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				Expect(balance(alice)).Should(Equal(int64(500000 - 100000 - 1000)))
+
+				Expect(redeemer.Audit()).ShouldNot(HaveOccurred())
+				_, err := initiator.AuditSecret()
+				Expect(err).Should(Equal(immediate.ErrAuditPending))
+
+				Expect(redeemer.Redeem(secret)).ShouldNot(HaveOccurred())
+				Expect(balance(bob)).Should(Equal(int64(100000 - 1000)))
+				Expect(redeemer.Cost()[chain.Token.Name].Int64()).Should(Equal(int64(1000)))
+
+				auditedSecret, err := initiator.AuditSecret()
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(auditedSecret).Should(Equal(secret))
+			})
+
+			It("should wait for the required confirmations before passing the audit", func() {
+				client, _, _, initiator, redeemer, _ := init(chain, 100000, time.Now().Unix()+3600, 3)
+
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				Expect(redeemer.Audit()).Should(Equal(immediate.NewErrConfirmationsPending(1, 3)))
+				Expect(redeemer.Audit().Error()).Should(Equal("1 of 3 confirmations"))
+
+				client.mine(2)
+				Expect(redeemer.Audit()).ShouldNot(HaveOccurred())
+			})
+
+			It("should refund after the timelock expires", func() {
+				_, alice, _, initiator, redeemer, _ := init(chain, 100000, time.Now().Unix()-60, 1)
+
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				Expect(initiator.Refund()).ShouldNot(HaveOccurred())
+				Expect(balance(alice)).Should(Equal(int64(500000 - 2000)))
+				Expect(initiator.Cost()[chain.Token.Name].Int64()).Should(Equal(int64(2000)))
+
+				_, err := initiator.AuditSecret()
+				Expect(err).Should(HaveOccurred())
+				Expect(redeemer.Audit()).Should(Equal(immediate.ErrSwapExpired))
+			})
+
+			It("should not refund before the timelock expires", func() {
+				_, _, _, initiator, _, _ := init(chain, 100000, time.Now().Unix()+3600, 1)
+
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				Expect(initiator.Refund()).Should(HaveOccurred())
+			})
+
+			It("should replace an unconfirmed initiate transaction when its fee is bumped", func() {
+				client := newRegtest(chain)
+				alice, bob := newAccount(chain, client), newAccount(chain, client)
+				client.fund(alice.Address(), 300000)
+				client.mempool = true
+
+				cost := blockchain.Cost{}
+				htlc := stalledSwap(chain, alice, bob, time.Now().Unix()+2*swap.ExpiryUnit)
+				initiator, err := NewSwapContractBinder(alice, htlc, cost, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				original := htlc.FeeBump.TxHash
+				Expect(client.Confirmations(context.Background(), original)).Should(Equal(int64(0)))
+
+				// Initiating again without a bump does not replace the
+				// transaction.
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+				Expect(htlc.FeeBump.TxHash).Should(Equal(original))
+
+				htlc = restart(htlc)
+				replacer, err := NewSwapContractBinder(alice, htlc, cost, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(replacer.Initiate()).ShouldNot(HaveOccurred())
+				Expect(htlc.FeeBump.TxHash).ShouldNot(Equal(original))
+				Expect(htlc.FeeBump.Replaced).Should(ConsistOf(original))
+				Expect(htlc.FeeBump.Fee.Int64()).Should(Equal(int64(1250)))
+
+				_, err = client.Confirmations(context.Background(), original)
+				Expect(err).Should(HaveOccurred())
+				Expect(balance(alice)).Should(Equal(int64(300000 - 100000 - 1250)))
+				Expect(cost[chain.Token.Name].Int64()).Should(Equal(int64(1250)))
+
+				client.mine(1)
+				Expect(replacer.Initiate()).ShouldNot(HaveOccurred())
+				Expect(htlc.FeeBump.Pending()).Should(BeFalse())
+				Expect(balance(alice)).Should(Equal(int64(300000 - 100000 - 1250)))
+			})
+
+			It("should replace an unconfirmed redeem transaction when its fee is bumped", func() {
+				client := newRegtest(chain)
+				alice, bob := newAccount(chain, client), newAccount(chain, client)
+				client.fund(alice.Address(), 300000)
+
+				secret := [32]byte{}
+				_, err := rand.Read(secret[:])
+				Expect(err).ShouldNot(HaveOccurred())
+
+				htlc := stalledSwap(chain, alice, bob, time.Now().Unix()+2*swap.ExpiryUnit)
+				htlc.SecretHash = secretHash(secret)
+				initiator, err := NewSwapContractBinder(alice, htlc, blockchain.Cost{}, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(initiator.Initiate()).ShouldNot(HaveOccurred())
+
+				client.mempool = true
+				cost := blockchain.Cost{}
+				htlc.FeeBump = swap.NewFeeBump()
+				redeemer, err := NewSwapContractBinder(bob, htlc, cost, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(redeemer.Redeem(secret)).ShouldNot(HaveOccurred())
+				original := htlc.FeeBump.TxHash
+				Expect(balance(bob)).Should(Equal(int64(100000 - 1000)))
+
+				// Redeeming again without a bump does not replace the
+				// transaction.
+				Expect(redeemer.Redeem(secret)).ShouldNot(HaveOccurred())
+				Expect(htlc.FeeBump.TxHash).Should(Equal(original))
+
+				htlc = restart(htlc)
+				replacer, err := NewSwapContractBinder(bob, htlc, cost, logger)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(replacer.Redeem(secret)).ShouldNot(HaveOccurred())
+				Expect(htlc.FeeBump.Replaced).Should(ConsistOf(original))
+
+				_, err = client.Confirmations(context.Background(), original)
+				Expect(err).Should(HaveOccurred())
+				Expect(client.Confirmations(context.Background(), htlc.FeeBump.TxHash)).Should(Equal(int64(0)))
+				Expect(balance(bob)).Should(Equal(int64(100000 - 1250)))
+				Expect(cost[chain.Token.Name].Int64()).Should(Equal(int64(1250)))
+
+				auditedSecret, err := initiator.AuditSecret()
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(auditedSecret).Should(Equal(secret))
+			})
+		})
+	}
+
+	Context("when transferring", func() {
+		It("should send the change back to the account", func() {
+			chain := Litecoin("testnet")
+			client := newRegtest(chain)
+			alice, bob := newAccount(chain, client), newAccount(chain, client)
+			client.fund(alice.Address(), 100000)
+
+			txHash, err := alice.Transfer(context.Background(), bob.Address(), 60000, 1000)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance(alice)).Should(Equal(int64(39000)))
+			Expect(balance(bob)).Should(Equal(int64(60000)))
+
+			confirmations, err := client.Confirmations(context.Background(), txHash)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(confirmations).Should(Equal(int64(1)))
+
+			_, err = alice.Transfer(context.Background(), bob.Address(), 60000, 1000)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should pay every output in one transaction", func() {
+			chain := BitcoinCash("testnet")
+			client := newRegtest(chain)
+			alice, bob, carol := newAccount(chain, client), newAccount(chain, client), newAccount(chain, client)
+			client.fund(alice.Address(), 100000)
+
+			_, err := alice.TransferMany(context.Background(), []Output{{bob.Address(), 30000}, {carol.Address(), 20000}}, 1000)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance(alice)).Should(Equal(int64(49000)))
+			Expect(balance(bob)).Should(Equal(int64(30000)))
+			Expect(balance(carol)).Should(Equal(int64(20000)))
+
+			_, err = alice.TransferMany(context.Background(), []Output{{bob.Address(), 30000}, {carol.Address(), 100}}, 1000)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should size the fee from the transaction that it builds", func() {
+			chain := Litecoin("testnet")
+			client := newRegtest(chain)
+			alice, bob := newAccount(chain, client), newAccount(chain, client)
+			for i := 0; i < 3; i++ {
+				client.fund(alice.Address(), 30000)
+			}
+
+			// One input, and outputs to bob and back to alice.
+			fee, err := alice.TransferFee(context.Background(), []Output{{bob.Address(), 10000}}, 10)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fee).Should(Equal(int64(2270)))
+
+			// Every input is needed to pay 75000 and its fee.
+			fee, err = alice.TransferFee(context.Background(), []Output{{bob.Address(), 75000}}, 10)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fee).Should(Equal(int64(5250)))
+
+			_, err = alice.TransferFee(context.Background(), []Output{{bob.Address(), 89000}}, 10)
+			Expect(err).Should(HaveOccurred())
+
+			_, err = alice.Transfer(context.Background(), bob.Address(), 75000, fee)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance(alice)).Should(Equal(int64(90000 - 75000 - 5250)))
+			Expect(balance(bob)).Should(Equal(int64(75000)))
+		})
+
+		It("should subtract the fee from the value that it spends", func() {
+			chain := Litecoin("testnet")
+			client := newRegtest(chain)
+			alice, bob := newAccount(chain, client), newAccount(chain, client)
+			client.fund(alice.Address(), 30000)
+			client.fund(alice.Address(), 30000)
+
+			// Both inputs, and outputs to bob and back to alice.
+			value, fee, err := alice.SubtractFee(context.Background(), bob.Address(), 40000, 10)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fee).Should(Equal(int64(3760)))
+			Expect(value).Should(Equal(int64(40000 - 3760)))
+
+			_, _, err = alice.SubtractFee(context.Background(), bob.Address(), 60001, 10)
+			Expect(err).Should(HaveOccurred())
+
+			// Both inputs, and one output to bob.
+			value, fee, err = alice.SubtractFee(context.Background(), bob.Address(), 0, 10)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fee).Should(Equal(int64(3420)))
+			Expect(value).Should(Equal(int64(60000 - 3420)))
+
+			_, err = alice.Transfer(context.Background(), bob.Address(), value, fee)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance(alice)).Should(Equal(int64(0)))
+			Expect(balance(bob)).Should(Equal(value))
+		})
+
+		It("should size swap transactions", func() {
+			size, err := SwapTxSize()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(size).Should(Equal(int64(379)))
+		})
+	})
+})
@@ -0,0 +1,207 @@
+package utxo
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+// SigHashForkID is the sighash flag that is required by Bitcoin Cash
+// signatures (replay protection).
+const SigHashForkID = txscript.SigHashType(0x40)
+
+// A Chain describes a bitcoin-like UTXO blockchain; its network parameters,
+// address format and signature hashing rules.
+type Chain struct {
+	Name   blockchain.BlockchainName
+	Token  blockchain.Token
+	Params *chaincfg.Params
+	Format AddressFormat
+
+	// ForkID is true if signatures commit to the value of the spent outputs,
+	// and use the SigHashForkID flag.
+	ForkID bool
+
+	// Segwit is true if the chain has native segwit (bech32) addresses. The
+	// accounts of the chain use P2PKH addresses, but can pay to both.
+	Segwit bool
+
+	// Dust is the smallest value of an output that is relayed by the network.
+	Dust int64
+
+	// CoinType is the BIP44 coin type of the chain.
+	CoinType uint32
+}
+
+// NewChain returns the Chain of the blockchain on the network.
+func NewChain(name blockchain.BlockchainName, network string) (Chain, error) {
+	switch name {
+	case blockchain.Litecoin:
+		return Litecoin(network), nil
+	case blockchain.BitcoinCash:
+		return BitcoinCash(network), nil
+	default:
+		return Chain{}, blockchain.NewErrUnsupportedBlockchain(name)
+	}
+}
+
+// Litecoin returns the Chain of litecoin on the network, which is either
+// "mainnet" or "testnet".
+func Litecoin(network string) Chain {
+	params := chaincfg.MainNetParams
+	params.Name = "litecoin-mainnet"
+	params.PubKeyHashAddrID = 0x30
+	params.ScriptHashAddrID = 0x32
+	params.PrivateKeyID = 0xB0
+	params.Bech32HRPSegwit = "ltc"
+	coinType := uint32(2)
+	if isTestnet(network) {
+		params = chaincfg.TestNet3Params
+		params.Name = "litecoin-testnet"
+		params.PubKeyHashAddrID = 0x6F
+		params.ScriptHashAddrID = 0x3A
+		params.PrivateKeyID = 0xEF
+		params.Bech32HRPSegwit = "tltc"
+		coinType = 1
+	}
+	return Chain{
+		Name:     blockchain.Litecoin,
+		Token:    blockchain.TokenLTC,
+		Params:   &params,
+		Format:   NewBase58Format(&params),
+		Dust:     5460,
+		CoinType: coinType,
+	}
+}
+
+// Bitcoin returns the Chain of bitcoin on the network, which is either
+// "mainnet" or "testnet". The accounts of the chain transfer from P2PKH
+// addresses to P2PKH, P2SH or bech32 addresses, and bind P2SH swaps. P2WSH
+// swaps have their own binder.
+func Bitcoin(network string) Chain {
+	params := &chaincfg.MainNetParams
+	coinType := uint32(0)
+	if isTestnet(network) {
+		params = &chaincfg.TestNet3Params
+		coinType = 1
+	}
+	return Chain{
+		Name:     blockchain.Bitcoin,
+		Token:    blockchain.TokenBTC,
+		Params:   params,
+		Format:   NewBase58Format(params),
+		Segwit:   true,
+		Dust:     546,
+		CoinType: coinType,
+	}
+}
+
+// BitcoinCash returns the Chain of bitcoin cash on the network, which is either
+// "mainnet" or "testnet". Addresses are encoded as CashAddr, but legacy
+// addresses are accepted.
+func BitcoinCash(network string) Chain {
+	params := &chaincfg.MainNetParams
+	prefix := "bitcoincash"
+	coinType := uint32(145)
+	if isTestnet(network) {
+		params = &chaincfg.TestNet3Params
+		prefix = "bchtest"
+		coinType = 1
+	}
+	return Chain{
+		Name:     blockchain.BitcoinCash,
+		Token:    blockchain.TokenBCH,
+		Params:   params,
+		Format:   NewCashAddrFormat(prefix, params),
+		ForkID:   true,
+		Dust:     546,
+		CoinType: coinType,
+	}
+}
+
+// HashType returns the sighash type of the signatures of the chain.
+func (chain Chain) HashType() txscript.SigHashType {
+	if chain.ForkID {
+		return txscript.SigHashAll | SigHashForkID
+	}
+	return txscript.SigHashAll
+}
+
+// SigHash returns the hash that is signed to spend the input of the
+// transaction. The subScript is the script of the spent output, or the redeem
+// script of a P2SH output, and the value is the value of the spent output.
+func (chain Chain) SigHash(tx *wire.MsgTx, idx int, subScript []byte, value int64) ([]byte, error) {
+	if chain.ForkID {
+		// Bitcoin Cash uses the BIP143 digest algorithm for all inputs.
+		return txscript.CalcWitnessSigHash(subScript, txscript.NewTxSigHashes(tx), chain.HashType(), tx, idx, value)
+	}
+	return txscript.CalcSignatureHash(subScript, chain.HashType(), tx, idx)
+}
+
+// PayToAddrScript returns the script that pays to the P2PKH or P2SH address,
+// or to the P2WPKH or P2WSH address of a segwit chain.
+func (chain Chain) PayToAddrScript(address string) ([]byte, error) {
+	if addr, ok := chain.witnessAddress(address); ok {
+		return txscript.PayToAddrScript(addr)
+	}
+	hash, scriptHash, err := chain.Format.DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	builder := txscript.NewScriptBuilder()
+	if scriptHash {
+		builder.AddOp(txscript.OP_HASH160).AddData(hash).AddOp(txscript.OP_EQUAL)
+		return builder.Script()
+	}
+	builder.AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(hash)
+	builder.AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG)
+	return builder.Script()
+}
+
+// witnessAddress returns the decoded address, and true if it is a native
+// segwit address of the chain.
+func (chain Chain) witnessAddress(address string) (btcutil.Address, bool) {
+	if !chain.Segwit {
+		return nil, false
+	}
+	addr, err := btcutil.DecodeAddress(address, chain.Params)
+	if err != nil || !addr.IsForNet(chain.Params) {
+		return nil, false
+	}
+	switch addr.(type) {
+	case *btcutil.AddressWitnessPubKeyHash, *btcutil.AddressWitnessScriptHash:
+		return addr, true
+	default:
+		return nil, false
+	}
+}
+
+// FormatTransactionView returns a message with a link to the transaction on a
+// block explorer.
+func (chain Chain) FormatTransactionView(msg, txHash string) string {
+	switch chain.Name {
+	case blockchain.Bitcoin:
+		if chain.Params.Net == chaincfg.TestNet3Params.Net {
+			return fmt.Sprintf("%s, transaction can be viewed at https://blockstream.info/testnet/tx/%s", msg, txHash)
+		}
+		return fmt.Sprintf("%s, transaction can be viewed at https://blockstream.info/tx/%s", msg, txHash)
+	case blockchain.Litecoin:
+		if chain.Params.Net == chaincfg.TestNet3Params.Net {
+			return fmt.Sprintf("%s, transaction can be viewed at https://chain.so/tx/LTCTEST/%s", msg, txHash)
+		}
+		return fmt.Sprintf("%s, transaction can be viewed at https://chain.so/tx/LTC/%s", msg, txHash)
+	default:
+		if chain.Params.Net == chaincfg.TestNet3Params.Net {
+			return fmt.Sprintf("%s, transaction can be viewed at https://explorer.bitcoin.com/tbch/tx/%s", msg, txHash)
+		}
+		return fmt.Sprintf("%s, transaction can be viewed at https://explorer.bitcoin.com/bch/tx/%s", msg, txHash)
+	}
+}
+
+func isTestnet(network string) bool {
+	return network == "testnet" || network == "testnet3" || network == "regtest"
+}
@@ -0,0 +1,198 @@
+package utxo
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+// A UTXO is an unspent transaction output.
+type UTXO struct {
+	TxHash        string
+	Vout          uint32
+	Amount        int64
+	Confirmations int64
+}
+
+// ErrTxNotFound is returned when the client does not know the transaction.
+var ErrTxNotFound = fmt.Errorf("transaction not found")
+
+// A Client reads the state of a UTXO blockchain, and publishes transactions.
+type Client interface {
+	// UnspentOutputs returns the unspent outputs of the address, including
+	// outputs that have not been confirmed.
+	UnspentOutputs(ctx context.Context, address string) ([]UTXO, error)
+
+	// SpendingScript returns the signature script of the first input that
+	// spends an output of the address, and false if none of its outputs have
+	// been spent.
+	SpendingScript(ctx context.Context, address string) ([]byte, bool, error)
+
+	// PublishTransaction publishes the signed transaction.
+	PublishTransaction(ctx context.Context, tx *wire.MsgTx) error
+
+	// Confirmations returns the number of confirmations of the transaction,
+	// or ErrTxNotFound if the transaction is unknown.
+	Confirmations(ctx context.Context, txHash string) (int64, error)
+}
+
+// A WitnessClient is a Client of a blockchain with segregated witness, that
+// can also read the witness of the inputs that spend an address.
+type WitnessClient interface {
+	Client
+
+	// SpendingWitness returns the witness of the first input that spends an
+	// output of the address, and false if none of its outputs have been
+	// spent.
+	SpendingWitness(ctx context.Context, address string) ([][]byte, bool, error)
+}
+
+// DefaultEsploraURL returns the url of the public Esplora API of bitcoin on
+// the network.
+func DefaultEsploraURL(network string) string {
+	if isTestnet(network) {
+		return "https://blockstream.info/testnet/api"
+	}
+	return "https://blockstream.info/api"
+}
+
+// DefaultInsightURL returns the url of the public Insight API of the
+// blockchain on the network.
+func DefaultInsightURL(name blockchain.BlockchainName, network string) string {
+	switch name {
+	case blockchain.Litecoin:
+		if isTestnet(network) {
+			return "https://testnet.litecore.io/api"
+		}
+		return "https://insight.litecore.io/api"
+	case blockchain.BitcoinCash:
+		if isTestnet(network) {
+			return "https://test-bch-insight.bitpay.com/api"
+		}
+		return "https://bch-insight.bitpay.com/api"
+	default:
+		return ""
+	}
+}
+
+type insightClient struct {
+	url string
+}
+
+// NewInsightClient returns a Client that uses the Insight API at the url.
+func NewInsightClient(url string) Client {
+	return &insightClient{url}
+}
+
+func (client *insightClient) UnspentOutputs(ctx context.Context, address string) ([]UTXO, error) {
+	outputs := []struct {
+		TxID          string `json:"txid"`
+		Vout          uint32 `json:"vout"`
+		Satoshis      int64  `json:"satoshis"`
+		Confirmations int64  `json:"confirmations"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/addr/%s/utxo", address), &outputs); err != nil {
+		return nil, err
+	}
+	utxos := make([]UTXO, len(outputs))
+	for i, output := range outputs {
+		utxos[i] = UTXO{output.TxID, output.Vout, output.Satoshis, output.Confirmations}
+	}
+	return utxos, nil
+}
+
+func (client *insightClient) SpendingScript(ctx context.Context, address string) ([]byte, bool, error) {
+	resp := struct {
+		Txs []struct {
+			Vin []struct {
+				Addr      string `json:"addr"`
+				ScriptSig struct {
+					Hex string `json:"hex"`
+				} `json:"scriptSig"`
+			} `json:"vin"`
+		} `json:"txs"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/txs?address=%s", address), &resp); err != nil {
+		return nil, false, err
+	}
+	for _, tx := range resp.Txs {
+		for _, vin := range tx.Vin {
+			if vin.Addr != address {
+				continue
+			}
+			script, err := hex.DecodeString(vin.ScriptSig.Hex)
+			if err != nil {
+				return nil, false, err
+			}
+			return script, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (client *insightClient) PublishTransaction(ctx context.Context, tx *wire.MsgTx) error {
+	buf := new(bytes.Buffer)
+	if err := tx.Serialize(buf); err != nil {
+		return err
+	}
+	reqBytes, err := json.Marshal(map[string]string{"rawtx": hex.EncodeToString(buf.Bytes())})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", client.url+"/tx/send", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("cannot publish transaction: %s", respBytes)
+	}
+	return nil
+}
+
+func (client *insightClient) Confirmations(ctx context.Context, txHash string) (int64, error) {
+	tx := struct {
+		Confirmations int64 `json:"confirmations"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/tx/%s", txHash), &tx); err != nil {
+		if err == errNotFound {
+			return 0, ErrTxNotFound
+		}
+		return 0, err
+	}
+	return tx.Confirmations, nil
+}
+
+var errNotFound = fmt.Errorf("not found")
+
+func (client *insightClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", client.url+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code=%v from %s", resp.StatusCode, client.url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,31 @@
+package utxo
+
+import (
+	"fmt"
+)
+
+var ErrMalformedRedeemTx = fmt.Errorf("redeem transaction returned by the blockchain is malformed")
+
+func NewErrDecodeAddress(addr string, err error) error {
+	return fmt.Errorf("failed to decode address (%s): %v", addr, err)
+}
+
+func NewErrBuildScript(err error) error {
+	return fmt.Errorf("failed to build script: %v", err)
+}
+
+func NewErrInitiate(err error) error {
+	return fmt.Errorf("failed to initiate: %v", err)
+}
+
+func NewErrRedeem(err error) error {
+	return fmt.Errorf("failed to redeem: %v", err)
+}
+
+func NewErrRefund(err error) error {
+	return fmt.Errorf("failed to refund: %v", err)
+}
+
+func NewErrAuditSecret(err error) error {
+	return fmt.Errorf("failed to audit secret: %v", err)
+}
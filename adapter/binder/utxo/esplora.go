@@ -0,0 +1,178 @@
+package utxo
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+type esploraClient struct {
+	url string
+}
+
+// NewEsploraClient returns a WitnessClient that uses the Esplora API at the
+// url, such as https://blockstream.info/api.
+func NewEsploraClient(url string) WitnessClient {
+	return &esploraClient{url}
+}
+
+func (client *esploraClient) UnspentOutputs(ctx context.Context, address string) ([]UTXO, error) {
+	outputs := []struct {
+		TxID   string `json:"txid"`
+		Vout   uint32 `json:"vout"`
+		Value  int64  `json:"value"`
+		Status struct {
+			Confirmed   bool  `json:"confirmed"`
+			BlockHeight int64 `json:"block_height"`
+		} `json:"status"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/address/%s/utxo", address), &outputs); err != nil {
+		return nil, err
+	}
+	tip, err := client.tip(ctx)
+	if err != nil {
+		return nil, err
+	}
+	utxos := make([]UTXO, len(outputs))
+	for i, output := range outputs {
+		confirmations := int64(0)
+		if output.Status.Confirmed {
+			confirmations = tip - output.Status.BlockHeight + 1
+		}
+		utxos[i] = UTXO{output.TxID, output.Vout, output.Value, confirmations}
+	}
+	return utxos, nil
+}
+
+func (client *esploraClient) SpendingScript(ctx context.Context, address string) ([]byte, bool, error) {
+	txs := []struct {
+		Vin []struct {
+			Prevout struct {
+				Address string `json:"scriptpubkey_address"`
+			} `json:"prevout"`
+			ScriptSig string `json:"scriptsig"`
+		} `json:"vin"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/address/%s/txs", address), &txs); err != nil {
+		return nil, false, err
+	}
+	for _, tx := range txs {
+		for _, vin := range tx.Vin {
+			if vin.Prevout.Address != address {
+				continue
+			}
+			script, err := hex.DecodeString(vin.ScriptSig)
+			if err != nil {
+				return nil, false, err
+			}
+			return script, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (client *esploraClient) SpendingWitness(ctx context.Context, address string) ([][]byte, bool, error) {
+	txs := []struct {
+		Vin []struct {
+			Prevout struct {
+				Address string `json:"scriptpubkey_address"`
+			} `json:"prevout"`
+			Witness []string `json:"witness"`
+		} `json:"vin"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/address/%s/txs", address), &txs); err != nil {
+		return nil, false, err
+	}
+	for _, tx := range txs {
+		for _, vin := range tx.Vin {
+			if vin.Prevout.Address != address {
+				continue
+			}
+			witness := make([][]byte, len(vin.Witness))
+			for i, item := range vin.Witness {
+				data, err := hex.DecodeString(item)
+				if err != nil {
+					return nil, false, err
+				}
+				witness[i] = data
+			}
+			return witness, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (client *esploraClient) PublishTransaction(ctx context.Context, tx *wire.MsgTx) error {
+	buf := new(bytes.Buffer)
+	if err := tx.Serialize(buf); err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", client.url+"/tx", bytes.NewBufferString(hex.EncodeToString(buf.Bytes())))
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("cannot publish transaction: %s", respBytes)
+	}
+	return nil
+}
+
+func (client *esploraClient) Confirmations(ctx context.Context, txHash string) (int64, error) {
+	status := struct {
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int64 `json:"block_height"`
+	}{}
+	if err := client.get(ctx, fmt.Sprintf("/tx/%s/status", txHash), &status); err != nil {
+		if err == errNotFound {
+			return 0, ErrTxNotFound
+		}
+		return 0, err
+	}
+	if !status.Confirmed {
+		return 0, nil
+	}
+	tip, err := client.tip(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return tip - status.BlockHeight + 1, nil
+}
+
+func (client *esploraClient) tip(ctx context.Context) (int64, error) {
+	tip := int64(0)
+	if err := client.get(ctx, "/blocks/tip/height", &tip); err != nil {
+		return 0, err
+	}
+	return tip, nil
+}
+
+func (client *esploraClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", client.url+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code=%v from %s", resp.StatusCode, client.url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
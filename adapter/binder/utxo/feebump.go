@@ -0,0 +1,77 @@
+package utxo
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+// Replaced describes the unconfirmed transactions that are replaced by a
+// transaction; the outputs that they spend, and their hashes.
+type Replaced struct {
+	Spent    []UTXO
+	TxHashes []string
+}
+
+// NewReplaced returns the unconfirmed transactions of the fee bump, which are
+// replaced by its next transaction.
+func NewReplaced(bump *swap.FeeBump) Replaced {
+	if !bump.Pending() {
+		return Replaced{}
+	}
+	spent := make([]UTXO, len(bump.Spent))
+	for i, output := range bump.Spent {
+		spent[i] = UTXO{TxHash: output.TxHash, Vout: output.Vout, Amount: output.Amount}
+	}
+	return Replaced{Spent: spent, TxHashes: bump.TxHashes()}
+}
+
+// spends returns true if the output is spent by the replaced transactions.
+func (replaced Replaced) spends(utxo UTXO) bool {
+	for _, spent := range replaced.Spent {
+		if spent.TxHash == utxo.TxHash && spent.Vout == utxo.Vout {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates returns true if the output is created by one of the replaced
+// transactions.
+func (replaced Replaced) Creates(utxo UTXO) bool {
+	for _, txHash := range replaced.TxHashes {
+		if txHash == utxo.TxHash {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordBroadcast records the transaction, the outputs that it spends, and its
+// fee in the fee bump.
+func RecordBroadcast(bump *swap.FeeBump, tx *wire.MsgTx, spent []UTXO, fee int64) {
+	if bump == nil {
+		return
+	}
+	bump.Broadcast(tx.TxHash().String(), big.NewInt(fee), 0, time.Now().Unix())
+	bump.Spent = make([]swap.SpentOutput, len(spent))
+	for i, utxo := range spent {
+		bump.Spent[i] = swap.SpentOutput{TxHash: utxo.TxHash, Vout: utxo.Vout, Amount: utxo.Amount}
+	}
+}
+
+// Replaceable returns true if the transaction of the fee bump is unconfirmed,
+// and pays less than the fee. The fee bump is reset once its transaction, or a
+// transaction that it replaced, has been confirmed.
+func Replaceable(ctx context.Context, client Client, bump *swap.FeeBump, fee int64) bool {
+	for _, txHash := range bump.TxHashes() {
+		if confirmations, err := client.Confirmations(ctx, txHash); err == nil && confirmations > 0 {
+			bump.Confirmed()
+			return false
+		}
+	}
+	return bump.Bumped(big.NewInt(fee))
+}
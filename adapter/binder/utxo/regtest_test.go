@@ -0,0 +1,278 @@
+package utxo_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	. "github.com/renproject/swapperd/adapter/binder/utxo"
+)
+
+// regtest is an in-memory UTXO blockchain. Every published transaction is
+// verified and confirmed immediately, unless the mempool is enabled. Unconfirmed
+// transactions are replaced by conflicting transactions that pay a higher fee.
+type regtest struct {
+	mu       *sync.Mutex
+	chain    Chain
+	nonce    uint32
+	mempool  bool
+	outputs  map[wire.OutPoint]*wire.TxOut
+	spending map[string][]byte
+	txs      map[string]int64
+
+	// spent are the outputs spent by each transaction, created are the number
+	// of outputs that it created, fees are their fees, and spenders are the
+	// scripts that were first spent by them.
+	spent    map[string]map[wire.OutPoint]*wire.TxOut
+	fees     map[string]int64
+	spenders map[string][]string
+	created  map[string]int
+}
+
+func newRegtest(chain Chain) *regtest {
+	return &regtest{
+		mu:       new(sync.Mutex),
+		chain:    chain,
+		outputs:  map[wire.OutPoint]*wire.TxOut{},
+		spending: map[string][]byte{},
+		txs:      map[string]int64{},
+		spent:    map[string]map[wire.OutPoint]*wire.TxOut{},
+		fees:     map[string]int64{},
+		spenders: map[string][]string{},
+		created:  map[string]int{},
+	}
+}
+
+// fund creates an output of the value to the address, out of thin air.
+func (chain *regtest) fund(address string, value int64) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	pkScript, err := chain.chain.PayToAddrScript(address)
+	if err != nil {
+		panic(err)
+	}
+	chain.nonce++
+	hash := chainhash.DoubleHashH([]byte(fmt.Sprintf("coinbase-%d", chain.nonce)))
+	chain.outputs[*wire.NewOutPoint(&hash, 0)] = wire.NewTxOut(value, pkScript)
+	chain.txs[hash.String()] = 1
+}
+
+// mine confirms every transaction by another n blocks.
+func (chain *regtest) mine(n int64) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	for txHash := range chain.txs {
+		chain.txs[txHash] += n
+	}
+}
+
+func (chain *regtest) UnspentOutputs(ctx context.Context, address string) ([]UTXO, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	pkScript, err := chain.chain.PayToAddrScript(address)
+	if err != nil {
+		return nil, err
+	}
+	utxos := []UTXO{}
+	for outPoint, txOut := range chain.outputs {
+		if hex.EncodeToString(txOut.PkScript) == hex.EncodeToString(pkScript) {
+			utxos = append(utxos, UTXO{outPoint.Hash.String(), outPoint.Index, txOut.Value, chain.txs[outPoint.Hash.String()]})
+		}
+	}
+	return utxos, nil
+}
+
+func (chain *regtest) SpendingScript(ctx context.Context, address string) ([]byte, bool, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	pkScript, err := chain.chain.PayToAddrScript(address)
+	if err != nil {
+		return nil, false, err
+	}
+	sigScript, ok := chain.spending[hex.EncodeToString(pkScript)]
+	return sigScript, ok, nil
+}
+
+func (chain *regtest) PublishTransaction(ctx context.Context, tx *wire.MsgTx) error {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	if !isFinal(tx) {
+		return fmt.Errorf("non-final transaction")
+	}
+	in, out := int64(0), int64(0)
+	prevOuts := make([]*wire.TxOut, len(tx.TxIn))
+	conflicts := map[string]bool{}
+	for i, txIn := range tx.TxIn {
+		prevOut, ok := chain.outputs[txIn.PreviousOutPoint]
+		if !ok {
+			txHash, spentOut, spent := chain.spender(txIn.PreviousOutPoint)
+			if !spent || chain.txs[txHash] > 0 {
+				return fmt.Errorf("missing input %v", txIn.PreviousOutPoint)
+			}
+			prevOut = spentOut
+			conflicts[txHash] = true
+		}
+		if err := chain.verify(tx, i, prevOut); err != nil {
+			return err
+		}
+		prevOuts[i] = prevOut
+		in += prevOut.Value
+	}
+	for _, txOut := range tx.TxOut {
+		if txOut.Value < chain.chain.Dust {
+			return fmt.Errorf("dust output %d", txOut.Value)
+		}
+		out += txOut.Value
+	}
+	if in < out {
+		return fmt.Errorf("outputs %d exceed inputs %d", out, in)
+	}
+	for txHash := range conflicts {
+		if chain.fees[txHash] >= in-out {
+			return fmt.Errorf("fee %d does not replace %s", in-out, txHash)
+		}
+	}
+	for txHash := range conflicts {
+		chain.evict(txHash)
+	}
+
+	hash := tx.TxHash()
+	chain.spent[hash.String()] = map[wire.OutPoint]*wire.TxOut{}
+	for i, txIn := range tx.TxIn {
+		pkScript := hex.EncodeToString(prevOuts[i].PkScript)
+		if _, ok := chain.spending[pkScript]; !ok {
+			chain.spending[pkScript] = txIn.SignatureScript
+			chain.spenders[hash.String()] = append(chain.spenders[hash.String()], pkScript)
+		}
+		chain.spent[hash.String()][txIn.PreviousOutPoint] = prevOuts[i]
+		delete(chain.outputs, txIn.PreviousOutPoint)
+	}
+	for i, txOut := range tx.TxOut {
+		chain.outputs[*wire.NewOutPoint(&hash, uint32(i))] = txOut
+	}
+	chain.created[hash.String()] = len(tx.TxOut)
+	chain.fees[hash.String()] = in - out
+	chain.txs[hash.String()] = 1
+	if chain.mempool {
+		chain.txs[hash.String()] = 0
+	}
+	return nil
+}
+
+// spender returns the transaction that spent the output, and the output.
+func (chain *regtest) spender(outPoint wire.OutPoint) (string, *wire.TxOut, bool) {
+	for txHash, spent := range chain.spent {
+		if txOut, ok := spent[outPoint]; ok {
+			return txHash, txOut, true
+		}
+	}
+	return "", nil, false
+}
+
+// evict removes an unconfirmed transaction that has been replaced. Its outputs
+// are removed, and the outputs that it spent are unspent again.
+func (chain *regtest) evict(txHash string) {
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < chain.created[txHash]; i++ {
+		delete(chain.outputs, *wire.NewOutPoint(hash, uint32(i)))
+	}
+	for outPoint, txOut := range chain.spent[txHash] {
+		chain.outputs[outPoint] = txOut
+	}
+	for _, pkScript := range chain.spenders[txHash] {
+		delete(chain.spending, pkScript)
+	}
+	delete(chain.spent, txHash)
+	delete(chain.spenders, txHash)
+	delete(chain.created, txHash)
+	delete(chain.fees, txHash)
+	delete(chain.txs, txHash)
+}
+
+func (chain *regtest) Confirmations(ctx context.Context, txHash string) (int64, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	confirmations, ok := chain.txs[txHash]
+	if !ok {
+		return 0, fmt.Errorf("transaction %s not found", txHash)
+	}
+	return confirmations, nil
+}
+
+// verify executes the scripts of the input. Bitcoin Cash signatures are not
+// supported by the script engine, so they are verified against the public key
+// that is pushed by the signature script.
+func (chain *regtest) verify(tx *wire.MsgTx, idx int, prevOut *wire.TxOut) error {
+	if !chain.chain.ForkID {
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, idx, txscript.StandardVerifyFlags, nil, nil, prevOut.Value)
+		if err != nil {
+			return err
+		}
+		return engine.Execute()
+	}
+
+	pushes, err := txscript.PushedData(tx.TxIn[idx].SignatureScript)
+	if err != nil {
+		return err
+	}
+	if len(pushes) < 2 {
+		return fmt.Errorf("missing signature")
+	}
+	subScript := prevOut.PkScript
+	if txscript.GetScriptClass(prevOut.PkScript) == txscript.ScriptHashTy {
+		subScript = pushes[len(pushes)-1]
+	}
+	sigBytes := pushes[0]
+	if sigBytes[len(sigBytes)-1] != byte(chain.chain.HashType()) {
+		return fmt.Errorf("invalid sighash type %x", sigBytes[len(sigBytes)-1])
+	}
+	sig, err := btcec.ParseDERSignature(sigBytes[:len(sigBytes)-1], btcec.S256())
+	if err != nil {
+		return err
+	}
+	pubKey, err := btcec.ParsePubKey(pushes[1], btcec.S256())
+	if err != nil {
+		return err
+	}
+	hash, err := chain.chain.SigHash(tx, idx, subScript, prevOut.Value)
+	if err != nil {
+		return err
+	}
+	if !sig.Verify(hash, pubKey) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+func isFinal(tx *wire.MsgTx) bool {
+	if tx.LockTime == 0 || int64(tx.LockTime) <= time.Now().Unix() {
+		return true
+	}
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence != wire.MaxTxInSequenceNum {
+			return false
+		}
+	}
+	return true
+}
+
+func secretHash(secret [32]byte) [32]byte {
+	return sha256.Sum256(secret[:])
+}
@@ -0,0 +1,97 @@
+package utxo
+
+import (
+	"github.com/btcsuite/btcd/txscript"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// NewInitiateScript creates an Atomic Swap initiate script for bitcoin-like
+// blockchains.
+//
+//			OP_IF
+//				OP_SIZE
+// 				32
+//				OP_EQUALVERIFY
+//				OP_SHA256
+//				<secret_hash>
+//				OP_EQUALVERIFY
+//				OP_DUP
+//				OP_HASH160
+//				<foreign_address>
+//			OP_ELSE
+//				<lock_time>
+//				OP_CHECKLOCKTIMEVERIFY
+//				OP_DROP
+//				OP_DUP
+//				OP_HASH160
+//				<personal_address>
+//			OP_ENDIF
+//			OP_EQUALVERIFY
+//			OP_CHECKSIG
+//
+func NewInitiateScript(pkhMe, pkhThem *[ripemd160.Size]byte, locktime int64, secretHash []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+
+	b.AddOp(txscript.OP_IF)
+	{
+		b.AddOp(txscript.OP_SIZE)
+		b.AddData([]byte{32})
+		b.AddOp(txscript.OP_EQUALVERIFY)
+		b.AddOp(txscript.OP_SHA256)
+		b.AddData(secretHash)
+		b.AddOp(txscript.OP_EQUALVERIFY)
+		b.AddOp(txscript.OP_DUP)
+		b.AddOp(txscript.OP_HASH160)
+		b.AddData(pkhThem[:])
+	}
+	b.AddOp(txscript.OP_ELSE)
+	{
+		b.AddInt64(locktime)
+		b.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
+		b.AddOp(txscript.OP_DROP)
+		b.AddOp(txscript.OP_DUP)
+		b.AddOp(txscript.OP_HASH160)
+		b.AddData(pkhMe[:])
+	}
+	b.AddOp(txscript.OP_ENDIF)
+	b.AddOp(txscript.OP_EQUALVERIFY)
+	b.AddOp(txscript.OP_CHECKSIG)
+
+	return b.Script()
+}
+
+// NewRedeemScript creates the signature script that redeems a P2SH Atomic
+// Swap.
+//
+//			<Signature>
+//			<PublicKey>
+//			<Secret>
+//			1 (True)
+//			<InitiateScript>
+//
+func NewRedeemScript(initiateScript, sig, pubkey []byte, secret [32]byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+	b.AddData(sig)
+	b.AddData(pubkey)
+	b.AddData(secret[:])
+	b.AddInt64(1)
+	b.AddData(initiateScript)
+	return b.Script()
+}
+
+// NewRefundScript creates the signature script that refunds a P2SH Atomic
+// Swap.
+//
+//			<Signature>
+//			<PublicKey>
+//			0 (False)
+//			<InitiateScript>
+//
+func NewRefundScript(initiateScript, sig, pubkey []byte) ([]byte, error) {
+	b := txscript.NewScriptBuilder()
+	b.AddData(sig)
+	b.AddData(pubkey)
+	b.AddInt64(0)
+	b.AddData(initiateScript)
+	return b.Script()
+}
@@ -7,16 +7,20 @@ import (
 	"io/ioutil"
 	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/renproject/swapperd/core/wallet/swapper/delayed"
 	"github.com/renproject/swapperd/foundation/swap"
 )
 
 type cb struct {
+	timeLocks swap.TimeLockPolicies
 }
 
-func New() delayed.DelayCallback {
-	return &cb{}
+// New returns a delay callback that checks the timelocks of the filled swaps
+// against the timelock policies.
+func New(timeLocks swap.TimeLockPolicies) delayed.DelayCallback {
+	return &cb{timeLocks}
 }
 
 func (cb *cb) DelayCallback(partialSwap swap.SwapBlob) (swap.SwapBlob, error) {
@@ -42,7 +46,7 @@ func (cb *cb) DelayCallback(partialSwap swap.SwapBlob) (swap.SwapBlob, error) {
 		if err := json.Unmarshal(respBytes, &filledSwap); err != nil {
 			return partialSwap, err
 		}
-		return verifyDelaySwap(partialSwap, filledSwap)
+		return cb.verifyDelaySwap(partialSwap, filledSwap)
 	case http.StatusNoContent:
 		return partialSwap, delayed.ErrSwapDetailsUnavailable
 	case http.StatusGone:
@@ -52,7 +56,11 @@ func (cb *cb) DelayCallback(partialSwap swap.SwapBlob) (swap.SwapBlob, error) {
 	}
 }
 
-func verifyDelaySwap(partialSwap, filledSwap swap.SwapBlob) (swap.SwapBlob, error) {
+// verifyDelaySwap returns the partial swap with the details filled by the
+// callback. Only the counterparty addresses, the amounts, the secret hash and
+// the timelock are taken from the filled swap, everything else is kept from
+// the partial swap.
+func (cb *cb) verifyDelaySwap(partialSwap, filledSwap swap.SwapBlob) (swap.SwapBlob, error) {
 	initialMinReceiveValue, ok := new(big.Int).SetString(partialSwap.MinimumReceiveAmount, 10)
 	if !ok {
 		initialMinReceiveValue = big.NewInt(0)
@@ -86,6 +94,32 @@ func verifyDelaySwap(partialSwap, filledSwap swap.SwapBlob) (swap.SwapBlob, erro
 		return partialSwap, fmt.Errorf("invalid filled swap unfavorable price")
 	}
 
-	filledSwap.Delay = false
-	return filledSwap, nil
+	blob := partialSwap
+	blob.Delay = false
+	blob.SendTo = filledSwap.SendTo
+	blob.ReceiveFrom = filledSwap.ReceiveFrom
+	blob.SendAmount = filledSwap.SendAmount
+	blob.ReceiveAmount = filledSwap.ReceiveAmount
+	blob.TimeLock = filledSwap.TimeLock
+
+	// The secret of the swaps that we initiate is derived from our password,
+	// so the callback only sets the secret hash of the swaps of the peer.
+	if !partialSwap.ShouldInitiateFirst {
+		blob.SecretHash = filledSwap.SecretHash
+	}
+
+	// The contracts keep the gap and the safety margin they were created with,
+	// and the swaps stored without timelocks take them from the policy.
+	policy := cb.timeLocks.Pair(partialSwap.SendToken, partialSwap.ReceiveToken)
+	if partialSwap.NativeTimeLock != 0 && partialSwap.ForeignTimeLock != 0 {
+		policy.Gap = partialSwap.NativeTimeLock - partialSwap.ForeignTimeLock
+		if policy.Gap < 0 {
+			policy.Gap = -policy.Gap
+		}
+		policy.SafetyMargin = partialSwap.SafetyMargin
+	}
+	if err := policy.VerifyTimeLock(time.Now().Unix(), blob.TimeLock); err != nil {
+		return partialSwap, err
+	}
+	return policy.SetTimeLocks(blob), nil
 }
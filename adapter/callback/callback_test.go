@@ -20,6 +20,8 @@ import (
 )
 
 var _ = Describe("Server Adapter", func() {
+	duration := swap.DefaultTimeLockPolicy.Duration
+
 	writeError := func(w http.ResponseWriter, statusCode int, err string) {
 		w.WriteHeader(statusCode)
 		w.Write([]byte(err))
@@ -138,7 +140,7 @@ var _ = Describe("Server Adapter", func() {
 
 				if initiationOption {
 					swap.SecretHash = randomString()
-					swap.TimeLock = time.Now().Unix()
+					swap.TimeLock = time.Now().Unix() + duration
 				}
 
 				partialSwaps = append(partialSwaps, swap)
@@ -158,7 +160,7 @@ var _ = Describe("Server Adapter", func() {
 
 				if !swap.ShouldInitiateFirst {
 					swap.SecretHash = randomString()
-					swap.TimeLock = time.Now().Unix()
+					swap.TimeLock = time.Now().Unix() + duration
 				}
 				swap.SendTo = fmt.Sprintf("Address:%s", swap.SendToken)
 				swap.ReceiveFrom = fmt.Sprintf("Address:%s", swap.ReceiveToken)
@@ -174,7 +176,7 @@ var _ = Describe("Server Adapter", func() {
 		for _, pendingSwap := range partialSwaps {
 			It(fmt.Sprintf("verification should succeed, and delay should be set to false"), func() {
 				pendingSwap.DelayCallbackURL = "http://127.0.0.1:17777/swaps"
-				swapFiller := New()
+				swapFiller := New(swap.TimeLockPolicies{})
 				filledSwap, err := swapFiller.DelayCallback(pendingSwap)
 				Expect(err).Should(BeNil())
 				Expect(filledSwap.Delay).Should(BeFalse())
@@ -205,7 +207,7 @@ var _ = Describe("Server Adapter", func() {
 
 				if !swap.ShouldInitiateFirst {
 					swap.SecretHash = randomString()
-					swap.TimeLock = time.Now().Unix()
+					swap.TimeLock = time.Now().Unix() + duration
 				}
 				swap.SendTo = fmt.Sprintf("Address:%s", swap.SendToken)
 				swap.ReceiveFrom = fmt.Sprintf("Address:%s", swap.ReceiveToken)
@@ -220,7 +222,7 @@ var _ = Describe("Server Adapter", func() {
 		for _, pendingSwap := range partialSwaps {
 			It(fmt.Sprintf("verification should succeed, and delay should be set to false %v", pendingSwap), func() {
 				pendingSwap.DelayCallbackURL = "http://127.0.0.1:17778/swaps"
-				swapFiller := New()
+				swapFiller := New(swap.TimeLockPolicies{})
 				filledSwap, err := swapFiller.DelayCallback(pendingSwap)
 				Expect(err).Should(BeNil())
 				Expect(filledSwap.Delay).Should(BeFalse())
@@ -250,7 +252,7 @@ var _ = Describe("Server Adapter", func() {
 
 				if !swap.ShouldInitiateFirst {
 					swap.SecretHash = randomString()
-					swap.TimeLock = time.Now().Unix()
+					swap.TimeLock = time.Now().Unix() + duration
 				}
 				swap.SendTo = fmt.Sprintf("Address:%s", swap.SendToken)
 				swap.ReceiveFrom = fmt.Sprintf("Address:%s", swap.ReceiveToken)
@@ -265,11 +267,98 @@ var _ = Describe("Server Adapter", func() {
 		for _, pendingSwap := range partialSwaps {
 			It(fmt.Sprintf("verification should fail %v", pendingSwap), func() {
 				pendingSwap.DelayCallbackURL = "http://127.0.0.1:17779/swaps"
-				swapFiller := New()
+				swapFiller := New(swap.TimeLockPolicies{})
 				_, err := swapFiller.DelayCallback(pendingSwap)
 				Expect(err).ShouldNot(BeNil())
 			})
 		}
 		close(doneCh)
 	})
+
+	Context("when the broker is filling fields it does not own", func() {
+		doneCh := make(chan struct{})
+		go startTestServer(func() http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				blob := swap.SwapBlob{}
+				if err := json.NewDecoder(r.Body).Decode(&blob); err != nil {
+					writeError(w, http.StatusBadRequest, fmt.Sprintf("cannot decode swap request: %v", err))
+					return
+				}
+
+				blob.SecretHash = randomString()
+				blob.TimeLock = time.Now().Unix() + duration
+				blob.SendTo = fmt.Sprintf("Address:%s", blob.SendToken)
+				blob.ReceiveFrom = fmt.Sprintf("Address:%s", blob.ReceiveToken)
+				blob.WithdrawAddress = "Address:Withdraw"
+				blob.NotifyURL = "http://127.0.0.1:17781/notify"
+				blob.ForceRefund = true
+				blob.AddressIndex = 42
+				blob.NativeTimeLock = 1
+				blob.ForeignTimeLock = 1
+				blob.SafetyMargin = 1
+				if blob.DelayInfo != nil {
+					blob.TimeLock = time.Now().Unix() + 60
+				}
+				blob.DelayInfo = nil
+				w.WriteHeader(http.StatusOK)
+				if err := json.NewEncoder(w).Encode(blob); err != nil {
+					writeError(w, http.StatusInternalServerError, fmt.Sprintf("cannot encode swap response: %v", err))
+					return
+				}
+			}
+		}, doneCh, 17780)
+
+		partialSwap := swap.SwapBlob{
+			ID:                  swap.SwapID(randomString()),
+			SendToken:           blockchain.BTC,
+			ReceiveToken:        blockchain.ETH,
+			SendAmount:          "1000",
+			ReceiveAmount:       "100",
+			ShouldInitiateFirst: true,
+			SecretHash:          randomString(),
+			TimeLock:            time.Now().Unix() + duration,
+			NativeTimeLock:      time.Now().Unix() + duration,
+			ForeignTimeLock:     time.Now().Unix() + duration - swap.ExpiryUnit,
+			SafetyMargin:        swap.ExpiryUnit,
+			AddressIndex:        7,
+			Delay:               true,
+			DelayCallbackURL:    "http://127.0.0.1:17780/swaps",
+		}
+
+		It("should only take the counterparty details from the filled swap", func() {
+			filledSwap, err := New(swap.TimeLockPolicies{}).DelayCallback(partialSwap)
+			Expect(err).Should(BeNil())
+			Expect(filledSwap.Delay).Should(BeFalse())
+			Expect(filledSwap.SendTo).Should(Equal("Address:BTC"))
+			Expect(filledSwap.ReceiveFrom).Should(Equal("Address:ETH"))
+			Expect(filledSwap.SecretHash).Should(Equal(partialSwap.SecretHash))
+			Expect(filledSwap.WithdrawAddress).Should(BeEmpty())
+			Expect(filledSwap.NotifyURL).Should(BeEmpty())
+			Expect(filledSwap.ForceRefund).Should(BeFalse())
+			Expect(filledSwap.AddressIndex).Should(Equal(uint32(7)))
+			Expect(filledSwap.NativeTimeLock).Should(Equal(filledSwap.TimeLock))
+			Expect(filledSwap.ForeignTimeLock).Should(Equal(filledSwap.TimeLock - swap.ExpiryUnit))
+			Expect(filledSwap.SafetyMargin).Should(Equal(swap.ExpiryUnit))
+		})
+
+		It("should reject a timelock that is not safe for the policy", func() {
+			unsafeSwap := partialSwap
+			unsafeSwap.DelayInfo = json.RawMessage(`{}`)
+			_, err := New(swap.TimeLockPolicies{}).DelayCallback(unsafeSwap)
+			Expect(err).ShouldNot(BeNil())
+		})
+
+		It("should set the timelocks of swaps stored without them from the policy", func() {
+			legacySwap := partialSwap
+			legacySwap.NativeTimeLock = 0
+			legacySwap.ForeignTimeLock = 0
+			legacySwap.SafetyMargin = 0
+			filledSwap, err := New(swap.TimeLockPolicies{}).DelayCallback(legacySwap)
+			Expect(err).Should(BeNil())
+			Expect(filledSwap.NativeTimeLock).Should(Equal(filledSwap.TimeLock))
+			Expect(filledSwap.ForeignTimeLock).Should(Equal(filledSwap.TimeLock))
+			Expect(filledSwap.SafetyMargin).Should(Equal(swap.DefaultTimeLockPolicy.SafetyMargin))
+		})
+		close(doneCh)
+	})
 })
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"encoding/binary"
+
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/syndtr/goleveldb/leveldb"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	TableAddressIndices = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07}
+)
+
+// AddressIndex returns the last address index that has been allocated to the
+// wallet on the blockchain. It returns zero if no index has been allocated.
+func (db *dbStorage) AddressIndex(walletID string, chain blockchain.BlockchainName) (uint32, error) {
+	indexData, err := db.db.Get(addressIndexKey(walletID, chain), nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(indexData), nil
+}
+
+// PutAddressIndex stores the last address index that has been allocated to
+// the wallet on the blockchain.
+func (db *dbStorage) PutAddressIndex(walletID string, chain blockchain.BlockchainName, index uint32) error {
+	indexData := make([]byte, 4)
+	binary.BigEndian.PutUint32(indexData, index)
+	return db.db.Put(addressIndexKey(walletID, chain), indexData, nil)
+}
+
+func addressIndexKey(walletID string, chain blockchain.BlockchainName) []byte {
+	hash := sha3.Sum256([]byte(walletID + string(chain)))
+	return append(TableAddressIndices[:], hash[:]...)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 
+	"github.com/renproject/swapperd/adapter/auth"
+	"github.com/renproject/swapperd/adapter/webhook"
 	"github.com/renproject/swapperd/core/wallet/transfer"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
@@ -23,6 +25,10 @@ var (
 	TableSwapReceipts      = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}
 	TableSwapReceiptsStart = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 	TableSwapReceiptsLimit = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+
+	TableSwapHistory      = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04}
+	TableSwapHistoryStart = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	TableSwapHistoryLimit = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
 )
 
 type Storage interface {
@@ -30,7 +36,12 @@ type Storage interface {
 	DeletePendingSwap(swapID swap.SwapID) error
 	PendingSwaps() ([]swap.SwapBlob, error)
 
+	Notifications() ([]webhook.Notification, error)
+	PutNotification(notification webhook.Notification) error
+	DeleteNotification(id string) error
+
 	PutTransfer(transfer transfer.TransferReceipt) error
+	Transfer(id transfer.TransferID) (transfer.TransferReceipt, error)
 	Transfers() ([]transfer.TransferReceipt, error)
 	UpdateTransferReceipt(updateReceipt transfer.UpdateReceipt) error
 
@@ -39,16 +50,55 @@ type Storage interface {
 	UpdateReceipt(receiptUpdate swap.ReceiptUpdate) error
 	Receipts() ([]swap.SwapReceipt, error)
 	Receipt(swapID swap.SwapID) (swap.SwapReceipt, error)
+	SwapHistory(swapID swap.SwapID) ([]swap.StatusUpdate, error)
 	LoadCosts(swapID swap.SwapID) (blockchain.Cost, blockchain.Cost)
+
+	PutToken(token auth.Token) error
+	Token(hash string) (auth.Token, error)
+	Tokens() ([]auth.Token, error)
+	DeleteToken(id string) error
+
+	AddressIndex(walletID string, chain blockchain.BlockchainName) (uint32, error)
+	PutAddressIndex(walletID string, chain blockchain.BlockchainName, index uint32) error
+
+	PutAcceptedQuote(id swap.SwapID) error
+	QuoteAccepted(id swap.SwapID) (bool, error)
+}
+
+// A Notifier is told about every swap and transfer receipt after it has been
+// written to the db.
+type Notifier interface {
+	NotifyReceipt(receipt swap.SwapReceipt)
+	NotifyTransfer(receipt transfer.TransferReceipt)
 }
 
 type dbStorage struct {
-	db *leveldb.DB
+	db       *leveldb.DB
+	notifier Notifier
 }
 
 func New(db *leveldb.DB) Storage {
+	return NewWithNotifier(db, nil)
+}
+
+// NewWithNotifier returns a Storage that passes every receipt it writes to the
+// notifier.
+func NewWithNotifier(db *leveldb.DB, notifier Notifier) Storage {
 	return &dbStorage{
-		db: db,
+		db:       db,
+		notifier: notifier,
+	}
+}
+
+func (db *dbStorage) notifyReceipt(receipt swap.SwapReceipt) {
+	if db.notifier != nil {
+		db.notifier.NotifyReceipt(receipt)
+	}
+}
+
+func (db *dbStorage) notifyTransfer(receipt transfer.TransferReceipt) {
+	if db.notifier != nil {
+		db.notifier.NotifyTransfer(receipt)
 	}
 }
 
@@ -62,6 +112,9 @@ func (db *dbStorage) PutSwap(blob swap.SwapBlob) error {
 	if err != nil {
 		return err
 	}
+	if err := db.putFeeBumps(id, blob); err != nil {
+		return err
+	}
 	if err := db.db.Put(append(TablePendingSwaps[:], id...), swapData, nil); err != nil {
 		return err
 	}
@@ -73,6 +126,9 @@ func (db *dbStorage) DeletePendingSwap(swapID swap.SwapID) error {
 	if err != nil {
 		return err
 	}
+	if err := db.deleteFeeBumps(id); err != nil {
+		return err
+	}
 	return db.db.Delete(append(TablePendingSwaps[:], id...), nil)
 }
 
@@ -89,6 +145,9 @@ func (db *dbStorage) PendingSwap(swapID swap.SwapID) (swap.SwapBlob, error) {
 	if err := json.Unmarshal(swapBlobBytes, &blob); err != nil {
 		return swap.SwapBlob{}, err
 	}
+	if err := db.loadFeeBumps(id, &blob); err != nil {
+		return swap.SwapBlob{}, err
+	}
 	return blob, nil
 }
 
@@ -102,6 +161,9 @@ func (db *dbStorage) PendingSwaps() ([]swap.SwapBlob, error) {
 		if err := json.Unmarshal(value, &swap); err != nil {
 			return pendingSwaps, err
 		}
+		if err := db.loadFeeBumps(iterator.Key()[len(TablePendingSwaps):], &swap); err != nil {
+			return pendingSwaps, err
+		}
 		pendingSwaps = append(pendingSwaps, swap)
 	}
 	return pendingSwaps, iterator.Error()
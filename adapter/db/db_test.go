@@ -1,6 +1,11 @@
 package db_test
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
 	"reflect"
 	"testing/quick"
 
@@ -8,6 +13,9 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/renproject/swapperd/adapter/db"
 
+	"github.com/renproject/swapperd/adapter/auth"
+	"github.com/renproject/swapperd/core/wallet/transfer"
+	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
 	"github.com/renproject/swapperd/testutils"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -32,5 +40,253 @@ var _ bool = Describe("DB", func() {
 
 			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
 		})
+
+		It("should record the status transitions of a swap", func() {
+			ldb, err := leveldb.OpenFile("./db-test", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			test := func(blob swap.SwapBlob) bool {
+				blob.DelayInfo = []byte("null")
+				receipt := swap.NewSwapReceipt(blob)
+				Expect(db.PutReceipt(receipt)).ShouldNot(HaveOccurred())
+
+				statuses := []int{swap.Initiated, swap.Initiated, swap.Audited, swap.Redeemed}
+				for _, status := range statuses {
+					status := status
+					Expect(db.UpdateReceipt(swap.NewReceiptUpdate(blob.ID, func(receipt *swap.SwapReceipt) {
+						receipt.Status = status
+					}))).ShouldNot(HaveOccurred())
+				}
+
+				history, err := db.SwapHistory(blob.ID)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(history).Should(HaveLen(4))
+				Expect(history[0]).Should(Equal(swap.NewStatusUpdate(swap.Inactive, receipt.Timestamp)))
+				for i, status := range []int{swap.Initiated, swap.Audited, swap.Redeemed} {
+					Expect(history[i+1].Status).Should(Equal(status))
+					Expect(history[i+1].Timestamp).Should(BeNumerically(">=", history[i].Timestamp))
+				}
+				return true
+			}
+
+			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+		})
+
+		It("should queue a webhook notification for every status transition", func() {
+			ldb, err := leveldb.OpenFile("./db-test", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			test := func(blob swap.SwapBlob) bool {
+				blob.DelayInfo = []byte("null")
+				Expect(db.PutReceipt(swap.NewSwapReceipt(blob))).ShouldNot(HaveOccurred())
+				for _, status := range []int{swap.Initiated, swap.Initiated, swap.Audited} {
+					status := status
+					Expect(db.UpdateReceipt(swap.NewReceiptUpdate(blob.ID, func(receipt *swap.SwapReceipt) {
+						receipt.Status = status
+					}))).ShouldNot(HaveOccurred())
+				}
+
+				notifications, err := db.Notifications()
+				Expect(err).ShouldNot(HaveOccurred())
+				statuses := []int{}
+				for _, notification := range notifications {
+					if notification.Receipt.ID == blob.ID {
+						statuses = append(statuses, notification.Receipt.Status)
+					}
+					Expect(db.DeleteNotification(notification.ID)).ShouldNot(HaveOccurred())
+				}
+				Expect(statuses).Should(ConsistOf(swap.Inactive, swap.Initiated, swap.Audited))
+				return true
+			}
+
+			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+		})
+
+		It("should notify every receipt that is written", func() {
+			ldb, err := leveldb.OpenFile("./db-test", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			notifier := &mockNotifier{}
+			db := NewWithNotifier(ldb, notifier)
+			defer ldb.Close()
+
+			test := func(blob swap.SwapBlob) bool {
+				blob.DelayInfo = []byte("null")
+				notifier.receipts = nil
+				Expect(db.PutReceipt(swap.NewSwapReceipt(blob))).ShouldNot(HaveOccurred())
+				Expect(db.UpdateReceipt(swap.NewReceiptUpdate(blob.ID, func(receipt *swap.SwapReceipt) {
+					receipt.Status = swap.Initiated
+				}))).ShouldNot(HaveOccurred())
+
+				return len(notifier.receipts) == 2 &&
+					notifier.receipts[0].Status == swap.Inactive &&
+					notifier.receipts[1].Status == swap.Initiated &&
+					notifier.receipts[1].ID == blob.ID
+			}
+
+			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+		})
+
+		It("should look up api tokens by the hash of their secret", func() {
+			ldb, err := leveldb.OpenFile("./db-test", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			secret, token, err := auth.NewToken("bot", []auth.Scope{auth.ScopeSwap}, "password")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(db.PutToken(token)).ShouldNot(HaveOccurred())
+
+			stored, err := db.Token(auth.HashSecret(secret))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(stored).Should(Equal(token))
+			tokens, err := db.Tokens()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tokens).Should(ContainElement(token))
+
+			Expect(db.DeleteToken(token.ID)).ShouldNot(HaveOccurred())
+			_, err = db.Token(auth.HashSecret(secret))
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should store transfers by their id and update them", func() {
+			ldb, err := leveldb.OpenFile("./db-test", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			notifier := &mockNotifier{}
+			db := NewWithNotifier(ldb, notifier)
+			defer ldb.Close()
+
+			id := transfer.NewTransferID()
+			receipt := transfer.TransferReceipt{ID: id, Status: transfer.TransferStatusPending}
+			Expect(db.PutTransfer(receipt)).ShouldNot(HaveOccurred())
+			update := transfer.NewUpdateReceipt("txHash", func(receipt *transfer.TransferReceipt) {
+				receipt.Status = transfer.TransferStatusBroadcast
+				receipt.TxHash = "txHash"
+			})
+			update.ID = id
+			Expect(db.UpdateTransferReceipt(update)).ShouldNot(HaveOccurred())
+
+			stored, err := db.Transfer(id)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(stored.Status).Should(Equal(transfer.TransferStatusBroadcast))
+			Expect(stored.TxHash).Should(Equal("txHash"))
+			Expect(notifier.transfers).Should(HaveLen(2))
+			Expect(db.Transfers()).Should(ContainElement(stored))
+		})
+
+		It("should give transfers stored by their tx hash an id", func() {
+			ldb, err := leveldb.OpenFile("./db-test", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			txHash := [32]byte{1}
+			Expect(ldb.Put(append(TableTransfer[:], txHash[:]...), []byte(`{"confirmations":1}`), nil)).ShouldNot(HaveOccurred())
+			id := transfer.TransferID(base64.StdEncoding.EncodeToString(txHash[:]))
+			stored, err := db.Transfer(id)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(stored.ID).Should(Equal(id))
+			Expect(stored.Status).Should(Equal(transfer.TransferStatusBroadcast))
+			Expect(stored.Confirmations).Should(Equal(int64(1)))
+		})
+
+		It("should store the last address index of each wallet and blockchain", func() {
+			dir, err := ioutil.TempDir("", "db-test")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			ldb, err := leveldb.OpenFile(dir, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			index, err := db.AddressIndex("wallet", blockchain.Bitcoin)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(index).Should(Equal(uint32(0)))
+
+			Expect(db.PutAddressIndex("wallet", blockchain.Bitcoin, 7)).ShouldNot(HaveOccurred())
+			index, err = db.AddressIndex("wallet", blockchain.Bitcoin)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(index).Should(Equal(uint32(7)))
+
+			index, err = db.AddressIndex("other", blockchain.Bitcoin)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(index).Should(Equal(uint32(0)))
+		})
+
+		It("should store the fee bumps of a swap apart from its json", func() {
+			dir, err := ioutil.TempDir("", "db-test")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			ldb, err := leveldb.OpenFile(dir, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			blob := swap.SwapBlob{ID: swap.SwapID(base64.StdEncoding.EncodeToString([]byte("swap"))), SendFeeBump: swap.NewFeeBump()}
+			blob.SendFeeBump.Broadcast("tx", big.NewInt(1000), 0, 1)
+			blob.SendFeeBump.Bump(2, 3)
+			data, err := json.Marshal(blob)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).ShouldNot(ContainSubstring("tx"))
+
+			Expect(db.PutSwap(blob)).ShouldNot(HaveOccurred())
+			stored, err := db.PendingSwap(blob.ID)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(stored.SendFeeBump).Should(Equal(blob.SendFeeBump))
+			Expect(stored.ReceiveFeeBump).Should(BeNil())
+
+			pending, err := db.PendingSwaps()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pending).Should(HaveLen(1))
+			Expect(pending[0].SendFeeBump).Should(Equal(blob.SendFeeBump))
+
+			// Swaps cannot be given fee bumps through their json.
+			Expect(json.Unmarshal([]byte(`{"sendFeeBump":{"percent":1000}}`), &blob)).ShouldNot(HaveOccurred())
+			Expect(blob.SendFeeBump.Percent).Should(Equal(stored.SendFeeBump.Percent))
+
+			Expect(db.DeletePendingSwap(blob.ID)).ShouldNot(HaveOccurred())
+			Expect(db.PutSwap(swap.SwapBlob{ID: blob.ID})).ShouldNot(HaveOccurred())
+			stored, err = db.PendingSwap(blob.ID)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(stored.SendFeeBump).Should(BeNil())
+		})
+
+		It("should record the accepted quotes", func() {
+			dir, err := ioutil.TempDir("", "db-test")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			ldb, err := leveldb.OpenFile(dir, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			db := New(ldb)
+			defer ldb.Close()
+
+			accepted, err := db.QuoteAccepted("quote")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(accepted).Should(BeFalse())
+
+			Expect(db.PutAcceptedQuote("quote")).ShouldNot(HaveOccurred())
+			accepted, err = db.QuoteAccepted("quote")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(accepted).Should(BeTrue())
+		})
 	})
 })
+
+type mockNotifier struct {
+	receipts  []swap.SwapReceipt
+	transfers []transfer.TransferReceipt
+}
+
+func (notifier *mockNotifier) NotifyReceipt(receipt swap.SwapReceipt) {
+	notifier.receipts = append(notifier.receipts, receipt)
+}
+
+func (notifier *mockNotifier) NotifyTransfer(receipt transfer.TransferReceipt) {
+	notifier.transfers = append(notifier.transfers, receipt)
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"encoding/json"
+
+	"github.com/renproject/swapperd/foundation/swap"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var (
+	TableFeeBumps = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09}
+)
+
+// feeBumps are the fee bumps of a pending swap. They are stored apart from the
+// swap blob, because they are never part of the JSON of a swap.
+type feeBumps struct {
+	Send    *swap.FeeBump `json:"send,omitempty"`
+	Receive *swap.FeeBump `json:"receive,omitempty"`
+}
+
+// putFeeBumps stores the fee bumps of the swap, if it has any.
+func (db *dbStorage) putFeeBumps(id []byte, blob swap.SwapBlob) error {
+	if blob.SendFeeBump == nil && blob.ReceiveFeeBump == nil {
+		return nil
+	}
+	data, err := json.Marshal(feeBumps{blob.SendFeeBump, blob.ReceiveFeeBump})
+	if err != nil {
+		return err
+	}
+	return db.db.Put(append(TableFeeBumps[:], id...), data, nil)
+}
+
+// loadFeeBumps sets the stored fee bumps of the swap.
+func (db *dbStorage) loadFeeBumps(id []byte, blob *swap.SwapBlob) error {
+	data, err := db.db.Get(append(TableFeeBumps[:], id...), nil)
+	if err == leveldb.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	bumps := feeBumps{}
+	if err := json.Unmarshal(data, &bumps); err != nil {
+		return err
+	}
+	blob.SendFeeBump, blob.ReceiveFeeBump = bumps.Send, bumps.Receive
+	return nil
+}
+
+func (db *dbStorage) deleteFeeBumps(id []byte) error {
+	return db.db.Delete(append(TableFeeBumps[:], id...), nil)
+}
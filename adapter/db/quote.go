@@ -0,0 +1,21 @@
+package db
+
+import (
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+var (
+	TableAcceptedQuotes = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08}
+)
+
+// PutAcceptedQuote records that the quote with the given swap id has been
+// accepted.
+func (db *dbStorage) PutAcceptedQuote(id swap.SwapID) error {
+	return db.db.Put(append(TableAcceptedQuotes[:], []byte(id)...), []byte{1}, nil)
+}
+
+// QuoteAccepted returns true if the quote with the given swap id has been
+// accepted.
+func (db *dbStorage) QuoteAccepted(id swap.SwapID) (bool, error) {
+	return db.db.Has(append(TableAcceptedQuotes[:], []byte(id)...), nil)
+}
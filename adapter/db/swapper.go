@@ -3,9 +3,12 @@ package db
 import (
 	"encoding/base64"
 	"encoding/json"
+	"time"
 
+	"github.com/renproject/swapperd/adapter/webhook"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
+	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
@@ -18,7 +21,21 @@ func (db *dbStorage) PutReceipt(receipt swap.SwapReceipt) error {
 	if err != nil {
 		return err
 	}
-	return db.db.Put(append(TableSwapReceipts[:], id...), receiptData, nil)
+	historyData, err := json.Marshal([]swap.StatusUpdate{swap.NewStatusUpdate(receipt.Status, receipt.Timestamp)})
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	batch.Put(append(TableSwapReceipts[:], id...), receiptData)
+	batch.Put(append(TableSwapHistory[:], id...), historyData)
+	if err := putNotification(batch, webhook.NewNotification(receipt, receipt.Timestamp)); err != nil {
+		return err
+	}
+	if err := db.db.Write(batch, nil); err != nil {
+		return err
+	}
+	db.notifyReceipt(receipt)
+	return nil
 }
 
 func (db *dbStorage) UpdateReceipt(receiptUpdate swap.ReceiptUpdate) error {
@@ -34,12 +51,43 @@ func (db *dbStorage) UpdateReceipt(receiptUpdate swap.ReceiptUpdate) error {
 	if err := json.Unmarshal(receiptBytes, &receipt); err != nil {
 		return err
 	}
+	status := receipt.Status
 	receiptUpdate.Update(&receipt)
 	updatedReceiptBytes, err := json.Marshal(receipt)
 	if err != nil {
 		return err
 	}
-	return db.db.Put(append(TableSwapReceipts[:], id...), updatedReceiptBytes, nil)
+	if receipt.Status == status {
+		if err := db.db.Put(append(TableSwapReceipts[:], id...), updatedReceiptBytes, nil); err != nil {
+			return err
+		}
+		db.notifyReceipt(receipt)
+		return nil
+	}
+
+	// The status has changed, so the transition is appended to the history
+	// and queued for the webhooks in the same batch as the receipt.
+	history, err := db.SwapHistory(receiptUpdate.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	history = append(history, swap.NewStatusUpdate(receipt.Status, now))
+	historyData, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	batch.Put(append(TableSwapReceipts[:], id...), updatedReceiptBytes)
+	batch.Put(append(TableSwapHistory[:], id...), historyData)
+	if err := putNotification(batch, webhook.NewNotification(receipt, now)); err != nil {
+		return err
+	}
+	if err := db.db.Write(batch, nil); err != nil {
+		return err
+	}
+	db.notifyReceipt(receipt)
+	return nil
 }
 
 func (db *dbStorage) Receipts() ([]swap.SwapReceipt, error) {
@@ -75,6 +123,30 @@ func (db *dbStorage) Receipt(swapID swap.SwapID) (swap.SwapReceipt, error) {
 	return receipt, nil
 }
 
+// SwapHistory returns the status transitions of the swap in the order in which
+// they were applied. Swaps created before the history table existed have an
+// empty history.
+func (db *dbStorage) SwapHistory(swapID swap.SwapID) ([]swap.StatusUpdate, error) {
+	history := []swap.StatusUpdate{}
+	id, err := base64.StdEncoding.DecodeString(string(swapID))
+	if err != nil {
+		return history, err
+	}
+
+	historyBytes, err := db.db.Get(append(TableSwapHistory[:], id...), nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return history, nil
+		}
+		return history, err
+	}
+
+	if err := json.Unmarshal(historyBytes, &history); err != nil {
+		return history, err
+	}
+	return history, nil
+}
+
 func (db *dbStorage) LoadCosts(swapID swap.SwapID) (blockchain.Cost, blockchain.Cost) {
 	receipt, err := db.Receipt(swapID)
 	if err != nil {
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/renproject/swapperd/adapter/auth"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var (
+	TableTokens      = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06}
+	TableTokensStart = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	TableTokensLimit = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+)
+
+// PutToken stores the API token under the hash of its secret.
+func (db *dbStorage) PutToken(token auth.Token) error {
+	tokenData, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	hash, err := base64.StdEncoding.DecodeString(token.Hash)
+	if err != nil {
+		return err
+	}
+	return db.db.Put(append(TableTokens[:], hash...), tokenData, nil)
+}
+
+// Token returns the API token with the given hash of its secret.
+func (db *dbStorage) Token(hash string) (auth.Token, error) {
+	hashBytes, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil {
+		return auth.Token{}, err
+	}
+	tokenData, err := db.db.Get(append(TableTokens[:], hashBytes...), nil)
+	if err != nil {
+		return auth.Token{}, err
+	}
+	token := auth.Token{}
+	if err := json.Unmarshal(tokenData, &token); err != nil {
+		return auth.Token{}, err
+	}
+	return token, nil
+}
+
+func (db *dbStorage) Tokens() ([]auth.Token, error) {
+	iterator := db.db.NewIterator(&util.Range{Start: TableTokensStart[:], Limit: TableTokensLimit[:]}, nil)
+	defer iterator.Release()
+	tokens := []auth.Token{}
+	for iterator.Next() {
+		token := auth.Token{}
+		if err := json.Unmarshal(iterator.Value(), &token); err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, iterator.Error()
+}
+
+// DeleteToken deletes the API token with the given id.
+func (db *dbStorage) DeleteToken(id string) error {
+	tokens, err := db.Tokens()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.ID != id {
+			continue
+		}
+		hash, err := base64.StdEncoding.DecodeString(token.Hash)
+		if err != nil {
+			return err
+		}
+		return db.db.Delete(append(TableTokens[:], hash...), nil)
+	}
+	return fmt.Errorf("api token %s not found", id)
+}
@@ -1,7 +1,7 @@
 package db
 
 import (
-	"encoding/hex"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -20,11 +20,27 @@ func (db *dbStorage) PutTransfer(transfer transfer.TransferReceipt) error {
 	if err != nil {
 		return err
 	}
-	txHashBytes, err := txHashToBytes(transfer.TxHash)
+	key, err := transferKey(transfer.ID)
 	if err != nil {
 		return err
 	}
-	return db.db.Put(append(TableTransfer[:], txHashBytes...), transferData, nil)
+	if err := db.db.Put(key, transferData, nil); err != nil {
+		return err
+	}
+	db.notifyTransfer(transfer)
+	return nil
+}
+
+func (db *dbStorage) Transfer(id transfer.TransferID) (transfer.TransferReceipt, error) {
+	key, err := transferKey(id)
+	if err != nil {
+		return transfer.TransferReceipt{}, err
+	}
+	receiptBytes, err := db.db.Get(key, nil)
+	if err != nil {
+		return transfer.TransferReceipt{}, err
+	}
+	return unmarshalTransfer(key, receiptBytes)
 }
 
 func (db *dbStorage) Transfers() ([]transfer.TransferReceipt, error) {
@@ -32,9 +48,8 @@ func (db *dbStorage) Transfers() ([]transfer.TransferReceipt, error) {
 	defer iterator.Release()
 	receipts := []transfer.TransferReceipt{}
 	for iterator.Next() {
-		value := iterator.Value()
-		receipt := transfer.TransferReceipt{}
-		if err := json.Unmarshal(value, &receipt); err != nil {
+		receipt, err := unmarshalTransfer(iterator.Key(), iterator.Value())
+		if err != nil {
 			return receipts, err
 		}
 		receipts = append(receipts, receipt)
@@ -43,36 +58,36 @@ func (db *dbStorage) Transfers() ([]transfer.TransferReceipt, error) {
 }
 
 func (db *dbStorage) UpdateTransferReceipt(updateReceipt transfer.UpdateReceipt) error {
-	txHash, err := txHashToBytes(updateReceipt.TxHash)
-	if err != nil {
-		return err
-	}
-	receiptBytes, err := db.db.Get(append(TableTransfer[:], txHash...), nil)
+	receipt, err := db.Transfer(updateReceipt.ID)
 	if err != nil {
 		return err
 	}
+	updateReceipt.Update(&receipt)
+	return db.PutTransfer(receipt)
+}
+
+// unmarshalTransfer decodes the transfer receipt stored at the key. Transfers
+// that were stored before they had IDs are stored by their tx hash, which
+// becomes their ID, and were always broadcast.
+func unmarshalTransfer(key, value []byte) (transfer.TransferReceipt, error) {
 	receipt := transfer.TransferReceipt{}
-	if err := json.Unmarshal(receiptBytes, &receipt); err != nil {
-		return err
+	if err := json.Unmarshal(value, &receipt); err != nil {
+		return receipt, err
 	}
-	updateReceipt.Update(&receipt)
-	updatedReceiptBytes, err := json.Marshal(receipt)
-	if err != nil {
-		return err
+	if receipt.ID == "" {
+		receipt.ID = transfer.TransferID(base64.StdEncoding.EncodeToString(key[len(TableTransfer):]))
+		receipt.Status = transfer.TransferStatusBroadcast
 	}
-	return db.db.Put(append(TableTransfer[:], txHash...), updatedReceiptBytes, nil)
+	return receipt, nil
 }
 
-func txHashToBytes(txHash string) ([]byte, error) {
-	if len(txHash) > 2 && txHash[:2] == "0x" {
-		txHash = txHash[2:]
-	}
-	txHashBytes, err := hex.DecodeString(txHash)
+func transferKey(id transfer.TransferID) ([]byte, error) {
+	idBytes, err := base64.StdEncoding.DecodeString(string(id))
 	if err != nil {
 		return nil, err
 	}
-	if len(txHashBytes) != 32 {
-		return nil, fmt.Errorf("unexpected tx hash length: %d", len(txHashBytes))
+	if len(idBytes) != 32 {
+		return nil, fmt.Errorf("unexpected transfer id length: %d", len(idBytes))
 	}
-	return txHashBytes, nil
+	return append(TableTransfer[:], idBytes...), nil
 }
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/renproject/swapperd/adapter/webhook"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var (
+	TableWebhooks      = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05}
+	TableWebhooksStart = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	TableWebhooksLimit = [40]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+)
+
+func (db *dbStorage) Notifications() ([]webhook.Notification, error) {
+	iterator := db.db.NewIterator(&util.Range{Start: TableWebhooksStart[:], Limit: TableWebhooksLimit[:]}, nil)
+	defer iterator.Release()
+	notifications := []webhook.Notification{}
+	for iterator.Next() {
+		value := iterator.Value()
+		notification := webhook.Notification{}
+		if err := json.Unmarshal(value, &notification); err != nil {
+			return notifications, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, iterator.Error()
+}
+
+func (db *dbStorage) PutNotification(notification webhook.Notification) error {
+	batch := new(leveldb.Batch)
+	if err := putNotification(batch, notification); err != nil {
+		return err
+	}
+	return db.db.Write(batch, nil)
+}
+
+func (db *dbStorage) DeleteNotification(id string) error {
+	notificationID, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return err
+	}
+	return db.db.Delete(append(TableWebhooks[:], notificationID...), nil)
+}
+
+// putNotification adds the notification to the batch, so that it is written
+// together with the receipt update that caused it.
+func putNotification(batch *leveldb.Batch, notification webhook.Notification) error {
+	notificationData, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	id, err := base64.StdEncoding.DecodeString(notification.ID)
+	if err != nil {
+		return err
+	}
+	batch.Put(append(TableWebhooks[:], id...), notificationData)
+	return nil
+}
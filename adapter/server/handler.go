@@ -12,9 +12,14 @@ import (
 	"io/ioutil"
 	"math/big"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/renproject/swapperd/adapter/auth"
 	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/adapter/webhook"
 	coreWallet "github.com/renproject/swapperd/core/wallet"
 	"github.com/renproject/swapperd/core/wallet/swapper"
 	"github.com/renproject/swapperd/core/wallet/transfer"
@@ -27,11 +32,40 @@ import (
 
 var ErrHandlerIsShuttingDown = fmt.Errorf("Http handler is shutting down")
 
+// ErrSwapNotFound is returned for the swaps that do not exist, or that belong
+// to another wallet.
+var ErrSwapNotFound = fmt.Errorf("swap receipt not found")
+
+// ErrSwapNotCancellable is returned when a swap is not held by a swapper, or
+// when its status does not allow it to be cancelled.
+type ErrSwapNotCancellable struct {
+	Reason string
+}
+
+func (err ErrSwapNotCancellable) Error() string {
+	return fmt.Sprintf("swap cannot be cancelled: %s", err.Reason)
+}
+
 type handler struct {
-	bootloaded map[string]bool
-	wallet     wallet.Wallet
-	storage    Storage
-	receiver   *Receiver
+	sessions  *auth.Sessions
+	wallet    wallet.Wallet
+	storage   Storage
+	receiver  *Receiver
+	webhook   *webhook.Webhook
+	timeLocks swap.TimeLockPolicies
+
+	// unlockMu makes sure that the pending swaps of a wallet are only
+	// bootloaded once.
+	unlockMu *sync.Mutex
+
+	// indexMu makes sure that an address index is only allocated to one swap.
+	indexMu *sync.Mutex
+
+	// quoteMu makes sure that a quote is only accepted once.
+	quoteMu *sync.Mutex
+
+	// readOnly handlers do not bootload the swaps of the wallets they unlock.
+	readOnly bool
 }
 
 // The Handler for swapperd requests
@@ -44,31 +78,59 @@ type Handler interface {
 	GetBalance(password string, token blockchain.Token) (GetBalanceResponse, error)
 	GetAddresses(password string) (GetAddressesResponse, error)
 	GetAddress(password string, token blockchain.Token) (GetAddressResponse, error)
+	GetWitnessAddress(password string) (GetAddressResponse, error)
+	GetTransfer(password string, id transfer.TransferID) (GetTransferResponse, error)
 	GetTransfers(password string) (GetTransfersResponse, error)
 	GetJSONSignature(password string, message json.RawMessage) (GetSignatureResponseJSON, error)
 	GetBase64Signature(password string, message string) (GetSignatureResponseString, error)
 	GetHexSignature(password string, message string) (GetSignatureResponseString, error)
-	PostTransfers(PostTransfersRequest) error
+	GetWebhookSecret(password string) (GetWebhookSecretResponse, error)
+	VerifyJSONSignature(req PostVerifyRequestJSON) (PostVerifyResponse, error)
+	VerifyBase64Signature(req PostVerifyRequestString) (PostVerifyResponse, error)
+	VerifyHexSignature(req PostVerifyRequestString) (PostVerifyResponse, error)
+	PostTransfers(PostTransfersRequest) (PostTransfersResponse, error)
 	PostSwaps(PostSwapRequest) (PostSwapResponse, error)
 	PostDelayedSwaps(PostSwapRequest) error
+	PostQuotes(PostQuoteRequest) (Quote, error)
+	PostAcceptQuote(PostAcceptQuoteRequest) (PostAcceptQuoteResponse, error)
+	CancelSwap(password string, id swap.SwapID) error
+	RefundSwap(password string, id swap.SwapID) error
+	Unlock(PostUnlockRequest) (auth.Session, error)
+	Lock(password string) error
+	PostTokens(PostTokenRequest) (PostTokenResponse, error)
+	GetTokens(password string) (GetTokensResponse, error)
+	DeleteToken(password string, id string) error
+	Authenticate(token string, scope auth.Scope) (string, error)
 	Shutdown()
 }
 
-func NewHandler(cap int, wallet wallet.Wallet, storage Storage, receiver *Receiver) Handler {
+func NewHandler(cap int, wallet wallet.Wallet, storage Storage, receiver *Receiver, webhook *webhook.Webhook, timeLocks swap.TimeLockPolicies) Handler {
+	return newHandler(wallet, storage, receiver, webhook, timeLocks, false)
+}
+
+func newHandler(wallet wallet.Wallet, storage Storage, receiver *Receiver, webhook *webhook.Webhook, timeLocks swap.TimeLockPolicies, readOnly bool) *handler {
 	return &handler{
-		bootloaded: map[string]bool{},
-		wallet:     wallet,
-		storage:    storage,
-		receiver:   receiver,
+		sessions:  auth.NewSessions(),
+		wallet:    wallet,
+		storage:   storage,
+		receiver:  receiver,
+		webhook:   webhook,
+		timeLocks: timeLocks,
+		unlockMu:  new(sync.Mutex),
+		indexMu:   new(sync.Mutex),
+		quoteMu:   new(sync.Mutex),
+		readOnly:  readOnly,
 	}
 }
 
 func (handler *handler) GetInfo(password string) GetInfoResponse {
-	handler.bootload(password)
 	return GetInfoResponse{
 		Version:         "v1.0.0-beta.3",
-		Bootloaded:      handler.bootloaded[passwordHash(password)],
+		Bootloaded:      handler.sessions.Unlocked(password, time.Now().Unix()),
+		Unlocked:        handler.sessions.Sessions(time.Now().Unix()),
+		ReadOnly:        handler.readOnly,
 		SupportedTokens: handler.wallet.SupportedTokens(),
+		Tokens:          blockchain.RegisteredTokens(),
 	}
 }
 
@@ -77,18 +139,20 @@ func (handler *handler) Shutdown() {
 }
 
 func (handler *handler) GetAddresses(password string) (GetAddressesResponse, error) {
-	handler.bootload(password)
 	return handler.wallet.Addresses(password)
 }
 
 func (handler *handler) GetAddress(password string, token blockchain.Token) (GetAddressResponse, error) {
-	handler.bootload(password)
 	address, err := handler.wallet.GetAddress(password, token.Blockchain)
 	return GetAddressResponse(address), err
 }
 
+func (handler *handler) GetWitnessAddress(password string) (GetAddressResponse, error) {
+	address, err := handler.wallet.GetWitnessAddress(password)
+	return GetAddressResponse(address), err
+}
+
 func (handler *handler) GetSwaps(password string) (GetSwapsResponse, error) {
-	handler.bootload(password)
 	resp := GetSwapsResponse{}
 
 	receipts, err := handler.getSwapReceipts(password)
@@ -98,12 +162,11 @@ func (handler *handler) GetSwaps(password string) (GetSwapsResponse, error) {
 
 	// swapReceipts := handler.getSwapReceipts(password)
 	for _, receipt := range receipts {
-		passwordHash, err := base64.StdEncoding.DecodeString(receipt.PasswordHash)
-		if receipt.PasswordHash != "" && err != nil {
-			return resp, fmt.Errorf("corrupted password")
+		ok, err := verifyPasswordHash(receipt.PasswordHash, password)
+		if err != nil {
+			return resp, err
 		}
-
-		if receipt.PasswordHash != "" && bcrypt.CompareHashAndPassword(passwordHash, []byte(password)) != nil {
+		if !ok {
 			continue
 		}
 		receipt.PasswordHash = ""
@@ -114,16 +177,83 @@ func (handler *handler) GetSwaps(password string) (GetSwapsResponse, error) {
 }
 
 func (handler *handler) GetSwap(password string, id swap.SwapID) (GetSwapResponse, error) {
-	handler.bootload(password)
-	swapReceipts, err := handler.getSwapReceipts(password)
+	receipt, err := handler.getSwapReceipt(password, id)
+	if err != nil {
+		return GetSwapResponse{}, err
+	}
+
+	history, err := handler.storage.SwapHistory(id)
 	if err != nil {
 		return GetSwapResponse{}, err
 	}
-	receipt, ok := swapReceipts[id]
+	return GetSwapResponse{receipt, history}, nil
+}
+
+func (handler *handler) CancelSwap(password string, id swap.SwapID) error {
+	if err := handler.verifySwapCancellable(password, id); err != nil {
+		return err
+	}
+	return handler.Write(swapper.CancelSwap{ID: id})
+}
+
+func (handler *handler) RefundSwap(password string, id swap.SwapID) error {
+	if err := handler.verifySwapInProgress(password, id); err != nil {
+		return err
+	}
+	return handler.Write(swapper.RefundSwap{ID: id})
+}
+
+func (handler *handler) verifySwapInProgress(password string, id swap.SwapID) error {
+	receipt, err := handler.getSwapReceipt(password, id)
+	if err != nil {
+		return err
+	}
+	switch receipt.Status {
+	case swap.Redeemed, swap.Refunded, swap.Cancelled, swap.Expired:
+		return fmt.Errorf("swap has already finished with status %d", receipt.Status)
+	default:
+		return nil
+	}
+}
+
+// verifySwapCancellable returns an error if the swap is not pending in a
+// swapper of the wallet of the password, or if it may have been funded on our
+// side already.
+func (handler *handler) verifySwapCancellable(password string, id swap.SwapID) error {
+	receipt, err := handler.getSwapReceipt(password, id)
+	if err != nil {
+		return err
+	}
+	if !receipt.Active || !handler.sessions.Bootloaded(password) {
+		return ErrSwapNotCancellable{"the swap is not active, unlock its wallet first"}
+	}
+	blob, err := handler.storage.PendingSwap(id)
+	if err != nil {
+		return ErrSwapNotCancellable{fmt.Sprintf("the swap is not pending, it has status %d", receipt.Status)}
+	}
+	if !swap.Cancellable(blob, receipt.Status) {
+		return ErrSwapNotCancellable{fmt.Sprintf("the swap may have been funded, it has status %d", receipt.Status)}
+	}
+	return nil
+}
+
+// getSwapReceipt returns the receipt of the swap with the given id, if it
+// belongs to the password.
+func (handler *handler) getSwapReceipt(password string, id swap.SwapID) (swap.SwapReceipt, error) {
+	receipt, err := handler.storage.Receipt(id)
+	if err != nil {
+		return receipt, ErrSwapNotFound
+	}
+
+	ok, err := verifyPasswordHash(receipt.PasswordHash, password)
+	if err != nil {
+		return receipt, err
+	}
 	if !ok {
-		return GetSwapResponse{}, fmt.Errorf("swap receipt not found")
+		return swap.SwapReceipt{}, ErrSwapNotFound
 	}
-	return GetSwapResponse(receipt), nil
+	receipt.PasswordHash = ""
+	return receipt, nil
 }
 
 func (handler *handler) getSwapReceipts(password string) (map[swap.SwapID]swap.SwapReceipt, error) {
@@ -142,19 +272,35 @@ func (handler *handler) getSwapReceipts(password string) (map[swap.SwapID]swap.S
 }
 
 func (handler *handler) GetBalances(password string) (GetBalancesResponse, error) {
-	handler.bootload(password)
 	balanceMap, err := handler.wallet.Balances(password)
-	return GetBalancesResponse(balanceMap), err
+	if err != nil {
+		return GetBalancesResponse(balanceMap), err
+	}
+	for _, token := range handler.wallet.SupportedTokens() {
+		if !token.Blockchain.HasAddressIndices() {
+			continue
+		}
+		balance, err := handler.GetBalance(password, token)
+		if err != nil {
+			return GetBalancesResponse(balanceMap), err
+		}
+		balanceMap[token.Name] = blockchain.Balance(balance)
+	}
+	return GetBalancesResponse(balanceMap), nil
 }
 
+// GetBalance returns the spendable balance of the token, and the balance that
+// has been received at the addresses that have been allocated to swaps.
 func (handler *handler) GetBalance(password string, token blockchain.Token) (GetBalanceResponse, error) {
-	handler.bootload(password)
-	balance, err := handler.wallet.Balance(password, token)
+	lastIndex, err := handler.lastAddressIndex(password, token.Blockchain)
+	if err != nil {
+		return GetBalanceResponse{}, err
+	}
+	balance, err := handler.wallet.AggregateBalance(password, token, lastIndex)
 	return GetBalanceResponse(balance), err
 }
 
 func (handler *handler) GetTransfers(password string) (GetTransfersResponse, error) {
-	handler.bootload(password)
 	transfers, err := handler.storage.Transfers()
 	if err != nil {
 		return GetTransfersResponse{}, err
@@ -162,44 +308,57 @@ func (handler *handler) GetTransfers(password string) (GetTransfersResponse, err
 
 	receiptMap := transfer.TransferReceiptMap{}
 	for _, receipt := range transfers {
-		passwordHash, err := base64.StdEncoding.DecodeString(receipt.PasswordHash)
-		if receipt.PasswordHash != "" && err != nil {
+		ok, err := verifyPasswordHash(receipt.PasswordHash, password)
+		if err != nil {
 			return GetTransfersResponse{}, err
 		}
-
-		if receipt.PasswordHash != "" && bcrypt.CompareHashAndPassword(passwordHash, []byte(password)) != nil {
+		if !ok {
 			continue
 		}
+		receiptMap[receipt.ID] = receipt
+	}
+	return MarshalGetTransfersResponse(receiptMap), nil
+}
 
-		update, err := handler.wallet.Lookup(receipt.Token, receipt.TxHash)
-		if err != nil {
-			return GetTransfersResponse{}, fmt.Errorf("Failed to lookup tx with txHash (%s) on %s blockchain", receipt.TxHash, receipt.Token.Blockchain)
-		}
+func (handler *handler) GetTransfer(password string, id transfer.TransferID) (GetTransferResponse, error) {
+	receipt, err := handler.storage.Transfer(id)
+	if err != nil {
+		return GetTransferResponse{}, fmt.Errorf("transfer receipt not found")
+	}
 
-		update.Update(&receipt)
-		receiptMap[receipt.TxHash] = receipt
+	ok, err := verifyPasswordHash(receipt.PasswordHash, password)
+	if err != nil {
+		return GetTransferResponse{}, err
 	}
-	return MarshalGetTransfersResponse(receiptMap), nil
+	if !ok {
+		return GetTransferResponse{}, fmt.Errorf("transfer receipt not found")
+	}
+	receipt.PasswordHash = ""
+	return GetTransferResponse(receipt), nil
 }
 
 func (handler *handler) PostSwaps(swapReq PostSwapRequest) (PostSwapResponse, error) {
-	handler.bootload(swapReq.Password)
+	if err := verifySwapSignature(swapReq); err != nil {
+		return PostSwapResponse{}, err
+	}
 
-	blob, err := handler.patchSwap(swap.SwapBlob(swapReq))
+	blob, err := handler.patchSwap(swapReq.SwapBlob)
 	if err != nil {
 		return PostSwapResponse{}, err
 	}
 
-	if err := handler.Write(swapper.SwapRequest(blob)); err != nil {
+	if err := handler.writeSwap(blob); err != nil {
 		return PostSwapResponse{}, err
 	}
 	return handler.buildSwapResponse(blob)
 }
 
 func (handler *handler) PostDelayedSwaps(swapReq PostSwapRequest) error {
-	handler.bootload(swapReq.Password)
+	if err := verifySwapSignature(swapReq); err != nil {
+		return err
+	}
 
-	blob, err := handler.patchDelayedSwap(swap.SwapBlob(swapReq))
+	blob, err := handler.patchDelayedSwap(swapReq.SwapBlob)
 	if err != nil {
 		return err
 	}
@@ -209,43 +368,295 @@ func (handler *handler) PostDelayedSwaps(swapReq PostSwapRequest) error {
 		return err
 	}
 
-	return handler.Write(swapper.SwapRequest(blob))
+	return handler.writeSwap(blob)
 }
 
-func (handler *handler) PostTransfers(req PostTransfersRequest) error {
-	handler.bootload(req.Password)
-	token, err := blockchain.PatchToken(req.Token)
+// PostQuotes returns a signed quote of a swap that we initiate, once the quote
+// has been accepted by the peer.
+func (handler *handler) PostQuotes(req PostQuoteRequest) (Quote, error) {
+	sendToken, err := blockchain.PatchToken(string(req.SendToken))
+	if err != nil {
+		return Quote{}, err
+	}
+	receiveToken, err := blockchain.PatchToken(string(req.ReceiveToken))
+	if err != nil {
+		return Quote{}, err
+	}
+	if err := verifyBitcoinScript(req.BitcoinScript); err != nil {
+		return Quote{}, err
+	}
+	if req.BrokerFee != 0 {
+		if err := handler.wallet.VerifyAddress(sendToken.Blockchain, req.BrokerSendTokenAddr); err != nil {
+			return Quote{}, err
+		}
+		if err := handler.wallet.VerifyAddress(receiveToken.Blockchain, req.BrokerReceiveTokenAddr); err != nil {
+			return Quote{}, err
+		}
+	}
+	if err := handler.verifySendAmount(req.Password, sendToken, req.SendAmount, req.BrokerFee); err != nil {
+		return Quote{}, err
+	}
+	if _, ok := new(big.Int).SetString(req.ReceiveAmount, 10); !ok {
+		return Quote{}, fmt.Errorf("invalid receive amount")
+	}
+	if err := handler.verifyReceiveAmount(req.Password, receiveToken); err != nil {
+		return Quote{}, err
+	}
+	timeLockPolicy, err := handler.timeLocks.Policy(sendToken, receiveToken)
+	if err != nil {
+		return Quote{}, err
+	}
+	if req.Expiry <= 0 {
+		req.Expiry = DefaultQuoteExpiry
+	}
+
+	swapID := [32]byte{}
+	rand.Read(swapID[:])
+	blob := swap.SwapBlob{
+		ID:                     swap.SwapID(base64.StdEncoding.EncodeToString(swapID[:])),
+		SendToken:              req.SendToken,
+		ReceiveToken:           req.ReceiveToken,
+		SendAmount:             req.SendAmount,
+		ReceiveAmount:          req.ReceiveAmount,
+		TimeLock:               time.Now().Unix() + timeLockPolicy.Duration,
+		ShouldInitiateFirst:    true,
+		BrokerFee:              req.BrokerFee,
+		BrokerSendTokenAddr:    req.BrokerSendTokenAddr,
+		BrokerReceiveTokenAddr: req.BrokerReceiveTokenAddr,
+		BitcoinScript:          req.BitcoinScript,
+		Password:               req.Password,
+	}
+	secret := genereateSecret(blob.Password, blob.ID)
+	secretHash := sha256.Sum256(secret[:])
+	blob.SecretHash = base64.StdEncoding.EncodeToString(secretHash[:])
+
+	offer, err := handler.mirrorSwap(blob)
+	if err != nil {
+		return Quote{}, err
+	}
+	return handler.signQuote(req.Password, Quote{
+		Swap:   offer,
+		Expiry: time.Now().Unix() + req.Expiry,
+	})
+}
+
+// PostAcceptQuote accepts the quote of a peer. If the peer offered the quote,
+// we respond to the swap and return the quote that the peer must accept to
+// initiate it. If the quote accepts our own offer, we initiate the swap.
+func (handler *handler) PostAcceptQuote(req PostAcceptQuoteRequest) (PostAcceptQuoteResponse, error) {
+	quote := req.Quote
+	if err := VerifyQuote(quote, time.Now().Unix()); err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	if req.PeerID != "" && req.PeerID != quote.ID {
+		return PostAcceptQuoteResponse{}, ErrInvalidQuoteSignature
+	}
+
+	blob := QuotedSwap(quote)
+	blob.Password = req.Password
+	blob.PasswordHash = req.PasswordHash
+	if quote.Offer != nil {
+		return handler.acceptOwnQuote(quote, blob)
+	}
+
+	blob, err := handler.patchSwap(blob)
+	if err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	mirror, err := handler.mirrorSwap(blob)
+	if err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	mirror.ID = quote.Swap.ID
+	response, err := handler.signQuote(req.Password, Quote{
+		Swap:   mirror,
+		Expiry: quote.Expiry,
+		Offer:  &quote,
+	})
+	if err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	if err := handler.writeSwap(blob); err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	return PostAcceptQuoteResponse{ID: blob.ID, Quote: &response}, nil
+}
+
+// acceptOwnQuote initiates the swap of a quote that we offered, and that has
+// been accepted by the peer. The blob only holds the quoted terms.
+func (handler *handler) acceptOwnQuote(quote Quote, blob swap.SwapBlob) (PostAcceptQuoteResponse, error) {
+	offer := *quote.Offer
+	if err := VerifyQuote(offer, time.Now().Unix()); err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	id, err := handler.wallet.ID(blob.Password, "")
+	if err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	if offer.ID != id {
+		return PostAcceptQuoteResponse{}, ErrInvalidQuoteSignature
+	}
+	if err := verifyMirror(offer.Swap, blob); err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	blob, err = handler.patchQuotedSwap(blob)
+	if err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	if err := handler.recordAcceptedQuote(blob.ID); err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	if err := handler.writeSwap(blob); err != nil {
+		return PostAcceptQuoteResponse{}, err
+	}
+	return PostAcceptQuoteResponse{ID: blob.ID}, nil
+}
+
+// recordAcceptedQuote records that the quote of the swap has been accepted, and
+// returns ErrQuoteAccepted if it has already been accepted. Swaps that were
+// accepted before quotes were recorded are found by their receipt.
+func (handler *handler) recordAcceptedQuote(id swap.SwapID) error {
+	handler.quoteMu.Lock()
+	defer handler.quoteMu.Unlock()
+
+	accepted, err := handler.storage.QuoteAccepted(id)
 	if err != nil {
 		return err
 	}
+	if _, err := handler.storage.Receipt(id); accepted || err == nil {
+		return ErrQuoteAccepted
+	}
+	return handler.storage.PutAcceptedQuote(id)
+}
+
+// PostTransfers stores a pending receipt of the transfer, and returns its ID.
+// The transfer is broadcast in the background, and its receipt is updated with
+// the result.
+func (handler *handler) PostTransfers(req PostTransfersRequest) (PostTransfersResponse, error) {
+	token, err := blockchain.PatchToken(req.Token)
+	if err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	if len(req.Recipients) > 0 {
+		if req.SendAll || req.SubtractFeeFromAmount {
+			return PostTransfersResponse{}, fmt.Errorf("cannot subtract the fee from a batch transfer")
+		}
+		return handler.postBatchTransfers(req, token)
+	}
+
+	if req.SendAll || req.SubtractFeeFromAmount {
+		return handler.postSubtractFeeTransfers(req, token)
+	}
 
 	if err := handler.wallet.VerifyAddress(token.Blockchain, req.To); err != nil {
-		return err
+		return PostTransfersResponse{}, err
 	}
 
 	amount, ok := big.NewInt(0).SetString(req.Amount, 10)
 	if !ok {
-		return fmt.Errorf("invalid amount %s", req.Amount)
+		return PostTransfersResponse{}, fmt.Errorf("invalid amount %s", req.Amount)
 	}
 
 	if err := handler.wallet.VerifyBalance(req.Password, token, amount); err != nil {
-		return err
+		return PostTransfersResponse{}, err
 	}
 
-	txCost, err := token.TransactionCost(amount)
+	txCost, err := handler.wallet.TransferCost(req.Password, token, []transfer.Recipient{{To: req.To, Amount: amount}})
 	if err != nil {
-		return err
+		return PostTransfersResponse{}, err
 	}
 
-	if err := handler.Write(transfer.NewTransferRequest(req.Password, token, req.To, amount, txCost)); err != nil {
-		return err
+	transferReq := transfer.NewTransferRequest(transfer.NewTransferID(), req.Password, token, req.To, amount, txCost)
+	if err := handler.storage.PutTransfer(transfer.NewPendingReceipt(transferReq)); err != nil {
+		return PostTransfersResponse{}, err
 	}
 
-	return nil
+	if err := handler.Write(transferReq); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	return PostTransfersResponse{ID: transferReq.ID}, nil
+}
+
+// postSubtractFeeTransfers transfers the requested amount, or the whole
+// balance, minus the fee of the transfer. Only transfers of a requested amount
+// record it in their receipt.
+func (handler *handler) postSubtractFeeTransfers(req PostTransfersRequest, token blockchain.Token) (PostTransfersResponse, error) {
+	if err := handler.wallet.VerifyAddress(token.Blockchain, req.To); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	var requested *big.Int
+	if req.SendAll {
+		if req.Amount != "" {
+			return PostTransfersResponse{}, fmt.Errorf("cannot send all with an amount")
+		}
+	} else {
+		amount, ok := big.NewInt(0).SetString(req.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return PostTransfersResponse{}, fmt.Errorf("invalid amount %s", req.Amount)
+		}
+		requested = amount
+	}
+
+	amount, txCost, err := handler.wallet.SpendableAmount(req.Password, token, req.To, requested)
+	if err != nil {
+		return PostTransfersResponse{}, err
+	}
+	transferReq := transfer.NewSubtractFeeTransferRequest(transfer.NewTransferID(), req.Password, token, req.To, amount, requested, txCost)
+	if err := handler.storage.PutTransfer(transfer.NewPendingReceipt(transferReq)); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	if err := handler.Write(transferReq); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	return PostTransfersResponse{ID: transferReq.ID}, nil
+}
+
+func (handler *handler) postBatchTransfers(req PostTransfersRequest, token blockchain.Token) (PostTransfersResponse, error) {
+	if req.To != "" || req.Amount != "" {
+		return PostTransfersResponse{}, fmt.Errorf("cannot transfer to an address and recipients")
+	}
+
+	total := big.NewInt(0)
+	recipients := make([]transfer.Recipient, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		if err := handler.wallet.VerifyAddress(token.Blockchain, recipient.To); err != nil {
+			return PostTransfersResponse{}, err
+		}
+		amount, ok := big.NewInt(0).SetString(recipient.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return PostTransfersResponse{}, fmt.Errorf("invalid amount %s", recipient.Amount)
+		}
+		recipients[i] = transfer.Recipient{To: recipient.To, Amount: amount}
+		total.Add(total, amount)
+	}
+
+	if err := handler.wallet.VerifyBalance(req.Password, token, total); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	txCost, err := handler.wallet.TransferCost(req.Password, token, recipients)
+	if err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	transferReq := transfer.NewBatchTransferRequest(transfer.NewTransferID(), req.Password, token, recipients, txCost)
+	if err := handler.storage.PutTransfer(transfer.NewPendingReceipt(transferReq)); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	if err := handler.Write(transferReq); err != nil {
+		return PostTransfersResponse{}, err
+	}
+
+	return PostTransfersResponse{ID: transferReq.ID}, nil
 }
 
 func (handler *handler) GetID(password, idType string) (string, error) {
-	handler.bootload(password)
 	id, err := handler.wallet.ID(password, idType)
 	if err != nil {
 		return "", err
@@ -254,7 +665,6 @@ func (handler *handler) GetID(password, idType string) (string, error) {
 }
 
 func (handler *handler) GetJSONSignature(password string, message json.RawMessage) (GetSignatureResponseJSON, error) {
-	handler.bootload(password)
 	sig, err := handler.sign(password, message)
 	if err != nil {
 		return GetSignatureResponseJSON{}, err
@@ -266,7 +676,6 @@ func (handler *handler) GetJSONSignature(password string, message json.RawMessag
 }
 
 func (handler *handler) GetBase64Signature(password string, message string) (GetSignatureResponseString, error) {
-	handler.bootload(password)
 	msg, err := base64.StdEncoding.DecodeString(message)
 	if err != nil {
 		return GetSignatureResponseString{}, err
@@ -284,7 +693,6 @@ func (handler *handler) GetBase64Signature(password string, message string) (Get
 }
 
 func (handler *handler) GetHexSignature(password string, message string) (GetSignatureResponseString, error) {
-	handler.bootload(password)
 	if len(message) > 2 && message[:2] == "0x" {
 		message = message[2:]
 	}
@@ -304,81 +712,375 @@ func (handler *handler) GetHexSignature(password string, message string) (GetSig
 	}, nil
 }
 
+func (handler *handler) VerifyJSONSignature(req PostVerifyRequestJSON) (PostVerifyResponse, error) {
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return PostVerifyResponse{}, err
+	}
+	return verifySignatureResponse(req.Message, sig, req.ID)
+}
+
+func (handler *handler) VerifyBase64Signature(req PostVerifyRequestString) (PostVerifyResponse, error) {
+	msg, err := base64.StdEncoding.DecodeString(req.Message)
+	if err != nil {
+		return PostVerifyResponse{}, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return PostVerifyResponse{}, err
+	}
+	return verifySignatureResponse(msg, sig, req.ID)
+}
+
+func (handler *handler) VerifyHexSignature(req PostVerifyRequestString) (PostVerifyResponse, error) {
+	msg, err := hex.DecodeString(strings.TrimPrefix(req.Message, "0x"))
+	if err != nil {
+		return PostVerifyResponse{}, err
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil {
+		return PostVerifyResponse{}, err
+	}
+	return verifySignatureResponse(msg, sig, req.ID)
+}
+
+func verifySignatureResponse(message, signature []byte, id string) (PostVerifyResponse, error) {
+	if id == "" {
+		return PostVerifyResponse{}, fmt.Errorf("id of the signer is required")
+	}
+	return PostVerifyResponse{VerifySignature(message, signature, id) == nil}, nil
+}
+
+func (handler *handler) GetWebhookSecret(password string) (GetWebhookSecretResponse, error) {
+	secret, err := webhook.Secret(handler.wallet, password)
+	if err != nil {
+		return GetWebhookSecretResponse{}, fmt.Errorf("unable to load webhook secret: %v", err)
+	}
+	return GetWebhookSecretResponse{base64.StdEncoding.EncodeToString(secret)}, nil
+}
+
+// writeSwap starts the swap, and allows its notifications to be delivered
+// while its wallet is unlocked.
+func (handler *handler) writeSwap(blob swap.SwapBlob) error {
+	handler.webhook.AddSwap(blob.Password, blob.PasswordHash)
+	return handler.Write(swapper.SwapRequest(blob))
+}
+
 func (handler *handler) Write(msg tau.Message) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
 	defer cancel()
 	return handler.receiver.Write(ctx, msg)
 }
 
-func (handler *handler) bootload(password string) {
-	if !handler.bootloaded[passwordHash(password)] {
-		if err := handler.Write(coreWallet.Bootload{password}); err != nil {
-			return
+// Unlock bootloads the pending swaps of the wallet of the password, the first
+// time that it is unlocked, allows the notifications of its swaps to be
+// delivered, and starts a session for the wallet and its API tokens.
+func (handler *handler) Unlock(req PostUnlockRequest) (auth.Session, error) {
+	if req.Expiry < 0 {
+		return auth.Session{}, fmt.Errorf("invalid session expiry: %d", req.Expiry)
+	}
+	id, err := handler.wallet.ID(req.Password, "")
+	if err != nil {
+		return auth.Session{}, fmt.Errorf("unable to load wallet id: %v", err)
+	}
+
+	handler.unlockMu.Lock()
+	defer handler.unlockMu.Unlock()
+
+	now := time.Now().Unix()
+	expiry := int64(0)
+	if req.Expiry != 0 {
+		expiry = now + req.Expiry
+	}
+	if !handler.sessions.Bootloaded(req.Password) {
+		if !handler.readOnly {
+			if err := handler.Write(coreWallet.Bootload{req.Password}); err != nil {
+				return auth.Session{}, err
+			}
 		}
-		handler.bootloaded[passwordHash(password)] = true
+		go handler.scanAddressIndices(req.Password)
+	}
+	handler.webhook.Unlock(req.Password)
+	session := handler.sessions.Unlock(id, req.Password, now, expiry)
+
+	tokens, err := handler.ownedTokens(req.Password)
+	if err != nil {
+		return session, err
+	}
+	for _, token := range tokens {
+		handler.sessions.AddToken(token.Hash, req.Password)
 	}
+	return session, nil
 }
 
-func (handler *handler) patchSwap(swapBlob swap.SwapBlob) (swap.SwapBlob, error) {
-	sendToken, err := blockchain.PatchToken(string(swapBlob.SendToken))
+// nextAddressIndex allocates the next address index of the wallet of the
+// password on the blockchain of the token. Tokens without address indices are
+// always received at the first address.
+func (handler *handler) nextAddressIndex(password string, tokenName blockchain.TokenName) (uint32, error) {
+	token, err := blockchain.PatchToken(string(tokenName))
 	if err != nil {
-		return swapBlob, err
+		return 0, err
+	}
+	if !token.Blockchain.HasAddressIndices() {
+		return 0, nil
+	}
+	id, err := handler.wallet.ID(password, "")
+	if err != nil {
+		return 0, err
 	}
 
-	if err := handler.wallet.VerifyAddress(sendToken.Blockchain, swapBlob.SendTo); err != nil {
-		return swapBlob, err
+	handler.indexMu.Lock()
+	defer handler.indexMu.Unlock()
+
+	index, err := handler.storage.AddressIndex(id, token.Blockchain)
+	if err != nil {
+		return 0, err
+	}
+	if err := handler.storage.PutAddressIndex(id, token.Blockchain, index+1); err != nil {
+		return 0, err
 	}
+	return index + 1, nil
+}
 
-	receiveToken, err := blockchain.PatchToken(string(swapBlob.ReceiveToken))
+// lastAddressIndex returns the last address index that has been allocated to
+// the wallet of the password on the blockchain.
+func (handler *handler) lastAddressIndex(password string, chain blockchain.BlockchainName) (uint32, error) {
+	if !chain.HasAddressIndices() {
+		return 0, nil
+	}
+	id, err := handler.wallet.ID(password, "")
 	if err != nil {
-		return swapBlob, err
+		return 0, err
 	}
+	return handler.storage.AddressIndex(id, chain)
+}
 
-	if err := handler.wallet.VerifyAddress(receiveToken.Blockchain, swapBlob.ReceiveFrom); err != nil {
-		return swapBlob, err
+// scanAddressIndices finds the addresses that hold funds when a wallet has
+// been restored from its mnemonic, and has no allocated address indices.
+func (handler *handler) scanAddressIndices(password string) {
+	id, err := handler.wallet.ID(password, "")
+	if err != nil {
+		return
 	}
+	for _, token := range handler.wallet.SupportedTokens() {
+		if !token.Blockchain.HasAddressIndices() {
+			continue
+		}
+		if index, err := handler.storage.AddressIndex(id, token.Blockchain); err != nil || index != 0 {
+			continue
+		}
+		lastIndex, err := handler.wallet.LastUsedIndex(password, token, wallet.GapLimit)
+		if err != nil || lastIndex == 0 {
+			continue
+		}
 
-	if swapBlob.WithdrawAddress != "" {
-		if err := handler.wallet.VerifyAddress(receiveToken.Blockchain, swapBlob.WithdrawAddress); err != nil {
-			return swapBlob, err
+		handler.indexMu.Lock()
+		if index, err := handler.storage.AddressIndex(id, token.Blockchain); err == nil && index < lastIndex {
+			handler.storage.PutAddressIndex(id, token.Blockchain, lastIndex)
 		}
+		handler.indexMu.Unlock()
 	}
+}
 
-	if err := handler.verifySendAmount(swapBlob.Password, sendToken, swapBlob.SendAmount, swapBlob.BrokerFee); err != nil {
-		return swapBlob, err
+// Lock ends the session of the wallet of the password. The API tokens of the
+// wallet cannot be used, and the notifications of its swaps are not delivered,
+// until it is unlocked again.
+func (handler *handler) Lock(password string) error {
+	if !handler.sessions.Lock(password) {
+		return fmt.Errorf("wallet is not unlocked")
 	}
+	handler.webhook.Lock(password)
+	return nil
+}
 
-	if err := handler.verifyReceiveAmount(swapBlob.Password, receiveToken); err != nil {
+// Authenticate returns the password of the wallet that the API token was
+// created for, if the token is allowed to make requests of the scope.
+func (handler *handler) Authenticate(secret string, scope auth.Scope) (string, error) {
+	token, err := handler.storage.Token(auth.HashSecret(secret))
+	if err != nil {
+		return "", auth.ErrInvalidToken
+	}
+	if !token.Allows(scope) {
+		return "", auth.ErrScopeNotAllowed(scope)
+	}
+	password, ok := handler.sessions.TokenPassword(token.Hash, time.Now().Unix())
+	if !ok {
+		return "", auth.ErrWalletLocked
+	}
+	return password, nil
+}
+
+func (handler *handler) PostTokens(req PostTokenRequest) (PostTokenResponse, error) {
+	secret, token, err := auth.NewToken(req.Name, req.Scopes, req.Password)
+	if err != nil {
+		return PostTokenResponse{}, err
+	}
+	if err := handler.storage.PutToken(token); err != nil {
+		return PostTokenResponse{}, fmt.Errorf("unable to store api token: %v", err)
+	}
+	handler.sessions.AddToken(token.Hash, req.Password)
+	return PostTokenResponse{token.Public(), secret}, nil
+}
+
+func (handler *handler) GetTokens(password string) (GetTokensResponse, error) {
+	tokens, err := handler.ownedTokens(password)
+	if err != nil {
+		return GetTokensResponse{}, err
+	}
+	resp := GetTokensResponse{Tokens: []auth.Token{}}
+	for _, token := range tokens {
+		resp.Tokens = append(resp.Tokens, token.Public())
+	}
+	return resp, nil
+}
+
+func (handler *handler) DeleteToken(password, id string) error {
+	tokens, err := handler.ownedTokens(password)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.ID != id {
+			continue
+		}
+		if err := handler.storage.DeleteToken(id); err != nil {
+			return err
+		}
+		handler.sessions.RemoveToken(token.Hash)
+		return nil
+	}
+	return fmt.Errorf("api token %s not found", id)
+}
+
+func (handler *handler) ownedTokens(password string) ([]auth.Token, error) {
+	tokens, err := handler.storage.Tokens()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load api tokens: %v", err)
+	}
+	owned := []auth.Token{}
+	for _, token := range tokens {
+		if token.OwnedBy(password) {
+			owned = append(owned, token)
+		}
+	}
+	return owned, nil
+}
+
+func (handler *handler) patchSwap(swapBlob swap.SwapBlob) (swap.SwapBlob, error) {
+	swapBlob.ForceRefund = false
+	timeLockPolicy, err := handler.verifySwap(swapBlob)
+	if err != nil {
 		return swapBlob, err
 	}
 
 	swapID := [32]byte{}
 	rand.Read(swapID[:])
 	swapBlob.ID = swap.SwapID(base64.StdEncoding.EncodeToString(swapID[:]))
-	secret := [32]byte{}
 	if swapBlob.ShouldInitiateFirst {
-		swapBlob.TimeLock = time.Now().Unix() + 3*swap.ExpiryUnit
-		secret = genereateSecret(swapBlob.Password, swapBlob.ID)
+		swapBlob.TimeLock = time.Now().Unix() + timeLockPolicy.Duration
+		secret := genereateSecret(swapBlob.Password, swapBlob.ID)
 		hash := sha256.Sum256(secret[:])
 		swapBlob.SecretHash = base64.StdEncoding.EncodeToString(hash[:])
-		return swapBlob, nil
+	} else {
+		secretHash, err := base64.StdEncoding.DecodeString(swapBlob.SecretHash)
+		if len(secretHash) != 32 || err != nil {
+			return swapBlob, fmt.Errorf("invalid secret hash")
+		}
+		if err := timeLockPolicy.VerifyTimeLock(time.Now().Unix(), swapBlob.TimeLock); err != nil {
+			return swapBlob, fmt.Errorf("not enough time to do the atomic swap: %v", err)
+		}
 	}
 
-	secretHash, err := base64.StdEncoding.DecodeString(swapBlob.SecretHash)
-	if len(secretHash) != 32 || err != nil {
-		return swapBlob, fmt.Errorf("invalid secret hash")
+	// The address index is only allocated once the swap is valid, so that
+	// rejected swaps do not use up indices.
+	swapBlob.AddressIndex, err = handler.nextAddressIndex(swapBlob.Password, swapBlob.ReceiveToken)
+	if err != nil {
+		return swapBlob, err
 	}
-	if time.Now().Unix()+2*swap.ExpiryUnit > swapBlob.TimeLock {
-		return swapBlob, fmt.Errorf("not enough time to do the atomic swap")
+	return timeLockPolicy.SetTimeLocks(swapBlob), nil
+}
+
+// patchQuotedSwap verifies a swap that we initiate after our quote has been
+// accepted. The id and timelock of the swap are the ones of the quote, and the
+// funds are received at the first address that the quote was offered with.
+func (handler *handler) patchQuotedSwap(blob swap.SwapBlob) (swap.SwapBlob, error) {
+	blob.ForceRefund = false
+	blob.AddressIndex = 0
+	blob.ShouldInitiateFirst = true
+	timeLockPolicy, err := handler.verifySwap(blob)
+	if err != nil {
+		return blob, err
+	}
+	if err := timeLockPolicy.VerifyTimeLock(time.Now().Unix(), blob.TimeLock); err != nil {
+		return blob, fmt.Errorf("not enough time to do the atomic swap: %v", err)
 	}
-	return swapBlob, nil
+	return timeLockPolicy.SetTimeLocks(blob), nil
+}
+
+// verifySwap verifies the addresses, amounts and options of the swap, and
+// returns the timelock policy of its token pair.
+func (handler *handler) verifySwap(swapBlob swap.SwapBlob) (swap.TimeLockPolicy, error) {
+	sendToken, err := blockchain.PatchToken(string(swapBlob.SendToken))
+	if err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	if err := handler.wallet.VerifyAddress(sendToken.Blockchain, swapBlob.SendTo); err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	receiveToken, err := blockchain.PatchToken(string(swapBlob.ReceiveToken))
+	if err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	if err := handler.wallet.VerifyAddress(receiveToken.Blockchain, swapBlob.ReceiveFrom); err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	timeLockPolicy, err := handler.timeLocks.Policy(sendToken, receiveToken)
+	if err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	if err := verifyNotifyURL(swapBlob.NotifyURL); err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	if err := verifyBitcoinScript(swapBlob.BitcoinScript); err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	if swapBlob.WithdrawAddress != "" {
+		if err := handler.wallet.VerifyAddress(receiveToken.Blockchain, swapBlob.WithdrawAddress); err != nil {
+			return swap.TimeLockPolicy{}, err
+		}
+	}
+
+	if err := handler.verifySendAmount(swapBlob.Password, sendToken, swapBlob.SendAmount, swapBlob.BrokerFee); err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+
+	if err := handler.verifyReceiveAmount(swapBlob.Password, receiveToken); err != nil {
+		return swap.TimeLockPolicy{}, err
+	}
+	return timeLockPolicy, nil
 }
 
 func (handler *handler) patchDelayedSwap(blob swap.SwapBlob) (swap.SwapBlob, error) {
+	blob.ForceRefund = false
 	if blob.DelayCallbackURL == "" {
 		return blob, fmt.Errorf("delay url cannot be empty")
 	}
 
+	if err := verifyNotifyURL(blob.NotifyURL); err != nil {
+		return blob, err
+	}
+
+	if err := verifyBitcoinScript(blob.BitcoinScript); err != nil {
+		return blob, err
+	}
+
 	swapID := [32]byte{}
 	rand.Read(swapID[:])
 	blob.ID = swap.SwapID(base64.StdEncoding.EncodeToString(swapID[:]))
@@ -399,11 +1101,46 @@ func (handler *handler) patchDelayedSwap(blob swap.SwapBlob) (swap.SwapBlob, err
 		return blob, err
 	}
 
+	timeLockPolicy, err := handler.timeLocks.Policy(sendToken, receiveToken)
+	if err != nil {
+		return blob, err
+	}
+
 	secret := genereateSecret(blob.Password, blob.ID)
 	secretHash := sha256.Sum256(secret[:])
 	blob.SecretHash = base64.StdEncoding.EncodeToString(secretHash[:])
-	blob.TimeLock = time.Now().Unix() + 3*swap.ExpiryUnit
-	return blob, nil
+	blob.TimeLock = time.Now().Unix() + timeLockPolicy.Duration
+
+	// The address index is only allocated once the swap is valid.
+	blob.AddressIndex, err = handler.nextAddressIndex(blob.Password, blob.ReceiveToken)
+	if err != nil {
+		return blob, err
+	}
+	return timeLockPolicy.SetTimeLocks(blob), nil
+}
+
+// verifyNotifyURL returns an error if the notify url is set, but is not an
+// absolute http(s) url.
+func verifyNotifyURL(notifyURL string) error {
+	if notifyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(notifyURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid notify url: %s", notifyURL)
+	}
+	return nil
+}
+
+// verifyBitcoinScript returns an error if the bitcoin script type is set, but
+// is not supported.
+func verifyBitcoinScript(scriptType string) error {
+	switch scriptType {
+	case "", swap.BitcoinScriptP2SH, swap.BitcoinScriptP2WSH:
+		return nil
+	default:
+		return fmt.Errorf("invalid bitcoin script: %s", scriptType)
+	}
 }
 
 func (handler *handler) verifySendAmount(password string, token blockchain.Token, amount string, fee int64) error {
@@ -440,48 +1177,31 @@ func (handler *handler) signDelayInfo(blob swap.SwapBlob) (swap.SwapBlob, error)
 }
 
 func (handler *handler) buildSwapResponse(blob swap.SwapBlob) (PostSwapResponse, error) {
-	responseBlob := swap.SwapBlob{}
-	responseBlob.SendToken = blob.ReceiveToken
-	responseBlob.ReceiveToken = blob.SendToken
-	responseBlob.SendAmount = blob.ReceiveAmount
-	responseBlob.ReceiveAmount = blob.SendAmount
 	swapResponse := PostSwapResponse{}
-
-	sendToken, err := blockchain.PatchToken(string(responseBlob.SendToken))
+	responseBlob, err := handler.mirrorSwap(blob)
 	if err != nil {
 		return swapResponse, err
 	}
+	responseBlob.ID = ""
 
-	receiveToken, err := blockchain.PatchToken(string(responseBlob.ReceiveToken))
-	if err != nil {
-		return swapResponse, err
-	}
-
-	sendTo, err := handler.wallet.GetAddress(blob.Password, sendToken.Blockchain)
+	responseBlobBytes, err := json.Marshal(responseBlob)
 	if err != nil {
 		return swapResponse, err
 	}
 
-	receiveFrom, err := handler.wallet.GetAddress(blob.Password, receiveToken.Blockchain)
+	responseBlobSig, err := handler.sign(blob.Password, responseBlobBytes)
 	if err != nil {
 		return swapResponse, err
 	}
 
-	responseBlob.SendTo = sendTo
-	responseBlob.ReceiveFrom = receiveFrom
-	responseBlob.SecretHash = blob.SecretHash
-	responseBlob.TimeLock = blob.TimeLock
-
-	responseBlob.BrokerFee = blob.BrokerFee
-	responseBlob.BrokerSendTokenAddr = blob.BrokerReceiveTokenAddr
-	responseBlob.BrokerReceiveTokenAddr = blob.BrokerSendTokenAddr
-
-	responseBlobBytes, err := json.Marshal(responseBlob)
+	// The terms of the swap are signed separately, so that the peer can
+	// verify them when it starts the responding swap.
+	termsBytes, err := json.Marshal(swapTerms(responseBlob))
 	if err != nil {
 		return swapResponse, err
 	}
 
-	responseBlobSig, err := handler.sign(blob.Password, responseBlobBytes)
+	termsSig, err := handler.sign(blob.Password, termsBytes)
 	if err != nil {
 		return swapResponse, err
 	}
@@ -489,6 +1209,7 @@ func (handler *handler) buildSwapResponse(blob swap.SwapBlob) (PostSwapResponse,
 	if blob.ShouldInitiateFirst {
 		swapResponse.Swap = responseBlob
 		swapResponse.Signature = base64.StdEncoding.EncodeToString(responseBlobSig)
+		swapResponse.TermsSignature = base64.StdEncoding.EncodeToString(termsSig)
 	}
 
 	if blob.ResponseURL != "" {
@@ -517,6 +1238,67 @@ func (handler *handler) buildSwapResponse(blob swap.SwapBlob) (PostSwapResponse,
 	return swapResponse, nil
 }
 
+// mirrorSwap returns the swap as seen by the other party, who sends to and
+// receives from our addresses.
+func (handler *handler) mirrorSwap(blob swap.SwapBlob) (swap.SwapBlob, error) {
+	mirror := swap.SwapBlob{}
+	mirror.ID = blob.ID
+	mirror.SendToken = blob.ReceiveToken
+	mirror.ReceiveToken = blob.SendToken
+	mirror.SendAmount = blob.ReceiveAmount
+	mirror.ReceiveAmount = blob.SendAmount
+
+	sendToken, err := blockchain.PatchToken(string(mirror.SendToken))
+	if err != nil {
+		return mirror, err
+	}
+
+	receiveToken, err := blockchain.PatchToken(string(mirror.ReceiveToken))
+	if err != nil {
+		return mirror, err
+	}
+
+	sendTo, err := handler.wallet.GetAddressAt(blob.Password, sendToken.Blockchain, blob.AddressIndex)
+	if err != nil {
+		return mirror, err
+	}
+
+	receiveFrom, err := handler.wallet.GetAddress(blob.Password, receiveToken.Blockchain)
+	if err != nil {
+		return mirror, err
+	}
+
+	mirror.SendTo = sendTo
+	mirror.ReceiveFrom = receiveFrom
+	mirror.SecretHash = blob.SecretHash
+	mirror.TimeLock = blob.TimeLock
+	mirror.BitcoinScript = blob.BitcoinScript
+
+	mirror.BrokerFee = blob.BrokerFee
+	mirror.BrokerSendTokenAddr = blob.BrokerReceiveTokenAddr
+	mirror.BrokerReceiveTokenAddr = blob.BrokerSendTokenAddr
+	return mirror, nil
+}
+
+// signQuote signs the quote with our identity key.
+func (handler *handler) signQuote(password string, quote Quote) (Quote, error) {
+	id, err := handler.wallet.ID(password, "")
+	if err != nil {
+		return quote, err
+	}
+	quote.ID = id
+	msg, err := quote.Message()
+	if err != nil {
+		return quote, err
+	}
+	sig, err := handler.sign(password, msg)
+	if err != nil {
+		return quote, err
+	}
+	quote.Signature = base64.StdEncoding.EncodeToString(sig)
+	return quote, nil
+}
+
 func (handler *handler) sign(password string, message []byte) ([]byte, error) {
 	signer, err := handler.wallet.ECDSASigner(password)
 	if err != nil {
@@ -534,9 +1316,17 @@ func genereateSecret(password string, id swap.SwapID) [32]byte {
 	return sha3.Sum256(append([]byte(password), []byte(id)...))
 }
 
-func passwordHash(password string) string {
-	passwordHash32 := sha3.Sum256([]byte(password))
-	return base64.StdEncoding.EncodeToString(passwordHash32[:])
+// verifyPasswordHash returns true if the password matches the base64 encoded
+// bcrypt hash. Receipts without a password hash are visible to everyone.
+func verifyPasswordHash(passwordHash, password string) (bool, error) {
+	if passwordHash == "" {
+		return true, nil
+	}
+	hash, err := base64.StdEncoding.DecodeString(passwordHash)
+	if err != nil {
+		return false, fmt.Errorf("corrupted password")
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil, nil
 }
 
 func withFees(amount *big.Int, bips int64) *big.Int {
@@ -4,12 +4,17 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/renproject/swapperd/adapter/auth"
 	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/adapter/webhook"
 	"github.com/renproject/swapperd/core/wallet/transfer"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
@@ -19,28 +24,63 @@ import (
 )
 
 type Storage interface {
+	Receipt(swapID swap.SwapID) (swap.SwapReceipt, error)
 	Receipts() ([]swap.SwapReceipt, error)
+	PendingSwap(swapID swap.SwapID) (swap.SwapBlob, error)
+	SwapHistory(swapID swap.SwapID) ([]swap.StatusUpdate, error)
+	PutTransfer(receipt transfer.TransferReceipt) error
+	Transfer(id transfer.TransferID) (transfer.TransferReceipt, error)
 	Transfers() ([]transfer.TransferReceipt, error)
+	PutToken(token auth.Token) error
+	Token(hash string) (auth.Token, error)
+	Tokens() ([]auth.Token, error)
+	DeleteToken(id string) error
+	AddressIndex(walletID string, chain blockchain.BlockchainName) (uint32, error)
+	PutAddressIndex(walletID string, chain blockchain.BlockchainName, index uint32) error
+	PutAcceptedQuote(id swap.SwapID) error
+	QuoteAccepted(id swap.SwapID) (bool, error)
 }
 
+// ErrReadOnly is returned by the requests that move funds, or sign, when the
+// server is read-only.
+var ErrReadOnly = fmt.Errorf("swapperd is running in read-only mode")
+
 type httpServer struct {
-	port    string
-	handler Handler
-	logger  logrus.FieldLogger
+	port     string
+	handler  Handler
+	stream   *Stream
+	logger   logrus.FieldLogger
+	readOnly bool
+}
+
+func NewHttpServer(cap int, port string, receiver *Receiver, stream *Stream, storage Storage, wallet wallet.Wallet, webhook *webhook.Webhook, timeLocks swap.TimeLockPolicies, logger logrus.FieldLogger) Server {
+	return &httpServer{port, NewHandler(cap, wallet, storage, receiver, webhook, timeLocks), stream, logger, false}
 }
 
-func NewHttpServer(cap int, port string, receiver *Receiver, storage Storage, wallet wallet.Wallet, logger logrus.FieldLogger) Server {
-	return &httpServer{port, NewHandler(cap, wallet, storage, receiver), logger}
+// NewReadOnlyHttpServer returns a server that only serves the requests that
+// read the state of the wallets, such as their balances and swaps, for a
+// watch-only wallet.
+func NewReadOnlyHttpServer(cap int, port string, receiver *Receiver, stream *Stream, storage Storage, wallet wallet.Wallet, webhook *webhook.Webhook, timeLocks swap.TimeLockPolicies, logger logrus.FieldLogger) Server {
+	return &httpServer{port, newHandler(wallet, storage, receiver, webhook, timeLocks, true), stream, logger, true}
 }
 
 // NewHttpListener creates a new http listener
 func (server *httpServer) Run(done <-chan struct{}) {
-	r := mux.NewRouter()
-	r.HandleFunc("/swaps", server.postSwapsHandler(server.handler)).Methods("POST")
+	// Swap ids are base64 encoded and can contain "/" (or "//"), so the id
+	// route matches the rest of the path and paths are not cleaned.
+	r := mux.NewRouter().SkipClean(true)
+	r.HandleFunc("/swaps", server.writable(server.postSwapsHandler(server.handler))).Methods("POST")
 	r.HandleFunc("/swaps", server.getSwapsHandler(server.handler)).Methods("GET")
-	// r.HandleFunc("/swaps/{id}", server.getSwapHandler(server.handler)).Methods("GET")
-	r.HandleFunc("/transfers", server.postTransfersHandler(server.handler)).Methods("POST")
+	r.HandleFunc("/swaps/{id:.+}/refund", server.writable(server.postRefundSwapHandler(server.handler))).Methods("POST")
+	r.HandleFunc("/swaps/{id:.+}", server.getSwapHandler(server.handler)).Methods("GET")
+	r.HandleFunc("/swaps/{id:.+}", server.writable(server.deleteSwapHandler(server.handler))).Methods("DELETE")
+	r.HandleFunc("/quotes", server.writable(server.postQuotesHandler(server.handler))).Methods("POST")
+	r.HandleFunc("/quotes/accept", server.writable(server.postAcceptQuoteHandler(server.handler))).Methods("POST")
+	r.HandleFunc("/transfers", server.writable(server.postTransfersHandler(server.handler))).Methods("POST")
 	r.HandleFunc("/transfers", server.getTransfersHandler(server.handler)).Methods("GET")
+	r.HandleFunc("/transfers/{id:.+}", server.getTransferHandler(server.handler)).Methods("GET")
+	r.HandleFunc("/events", server.getEventsHandler(server.stream)).Methods("GET")
+	r.HandleFunc("/webhooks/secret", server.getWebhookSecretHandler(server.handler)).Methods("GET")
 	r.HandleFunc("/balances", server.getBalancesHandler(server.handler)).Methods("GET")
 	r.HandleFunc("/balances/{token}", server.getBalancesHandler(server.handler)).Methods("GET")
 	r.HandleFunc("/addresses", server.getAddressesHandler(server.handler)).Methods("GET")
@@ -48,12 +88,18 @@ func (server *httpServer) Run(done <-chan struct{}) {
 	r.HandleFunc("/info", server.getInfoHandler(server.handler)).Methods("GET")
 	r.HandleFunc("/id/{type}", server.getIDHandler(server.handler)).Methods("GET")
 	r.HandleFunc("/id", server.getIDHandler(server.handler)).Methods("GET")
-	r.HandleFunc("/sign/{type}", server.postSignatureHandler(server.handler)).Methods("POST")
+	r.HandleFunc("/sign/{type}", server.writable(server.postSignatureHandler(server.handler))).Methods("POST")
+	r.HandleFunc("/verify/{type}", server.postVerifyHandler(server.handler)).Methods("POST")
+	r.HandleFunc("/unlock", server.postUnlockHandler(server.handler)).Methods("POST")
+	r.HandleFunc("/lock", server.postLockHandler(server.handler)).Methods("POST")
+	r.HandleFunc("/tokens", server.postTokensHandler(server.handler)).Methods("POST")
+	r.HandleFunc("/tokens", server.getTokensHandler(server.handler)).Methods("GET")
+	r.HandleFunc("/tokens/{id}", server.deleteTokenHandler(server.handler)).Methods("DELETE")
 	r.Use(recoveryHandler)
 	handler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowCredentials: true,
-		AllowedMethods:   []string{"GET", "POST"},
+		AllowedMethods:   []string{"GET", "POST", "DELETE"},
 	}).Handler(r)
 
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", server.port))
@@ -69,6 +115,7 @@ func (server *httpServer) Run(done <-chan struct{}) {
 	<-done
 	server.logger.Infof("swapperd stopped listening on http://127.0.0.1:%s", server.port)
 	listener.Close()
+	server.stream.Shutdown()
 	server.handler.Shutdown()
 }
 
@@ -91,13 +138,48 @@ func recoveryHandler(h http.Handler) http.Handler {
 	})
 }
 
+// writable rejects the requests of the handler when the server is read-only.
+func (server *httpServer) writable(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if server.readOnly {
+			server.writeError(w, r, http.StatusForbidden, ErrReadOnly.Error())
+			return
+		}
+		h(w, r)
+	}
+}
+
+// authenticate returns the password of the wallet that the request is made
+// for. Requests are authenticated by the wallet password, using basic auth, or
+// by an API token that is allowed the scope, using a bearer token.
+func (server *httpServer) authenticate(w http.ResponseWriter, r *http.Request, scope auth.Scope) (string, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		password, err := server.handler.Authenticate(strings.TrimPrefix(header, "Bearer "), scope)
+		if err != nil {
+			statusCode := http.StatusUnauthorized
+			if _, ok := err.(auth.ErrScopeNotAllowed); ok {
+				statusCode = http.StatusForbidden
+			}
+			server.writeError(w, r, statusCode, err.Error())
+			return "", false
+		}
+		return password, true
+	}
+
+	_, password, ok := r.BasicAuth()
+	if !ok {
+		server.writeError(w, r, http.StatusUnauthorized, "authentication required")
+		return "", false
+	}
+	return password, true
+}
+
 // getInfoHandler handles the get info request, it returns the basic information
 // of the swapper such as the version, supported tokens addresses.
 func (server *httpServer) getInfoHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 		respBytes, err := json.MarshalIndent(reqHandler.GetInfo(password), "\t", "")
@@ -110,16 +192,16 @@ func (server *httpServer) getInfoHandler(reqHandler Handler) http.HandlerFunc {
 }
 
 // getSwapHandler handles the get swap request, it returns the status of the
-// existing swap with given swap id on the swapper.
+// existing swap with given swap id on the swapper, along with the history of
+// its status transitions.
 func (server *httpServer) getSwapHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
-		swapID := r.FormValue("id")
+		swapID := mux.Vars(r)["id"]
 		if swapID == "" {
 			server.writeError(w, r, http.StatusBadRequest, "requires a swap id")
 			return
@@ -144,9 +226,8 @@ func (server *httpServer) getSwapHandler(reqHandler Handler) http.HandlerFunc {
 // existing swaps of the person calling it.
 func (server *httpServer) getSwapsHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -170,9 +251,8 @@ func (server *httpServer) getSwapsHandler(reqHandler Handler) http.HandlerFunc {
 // information and starts the Atomic Swap.
 func (server *httpServer) postSwapsHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeSwap)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -224,12 +304,126 @@ func (server *httpServer) postSwapsHandler(reqHandler Handler) http.HandlerFunc
 	}
 }
 
+// postQuotesHandler handles the post quotes request, it returns a signed quote
+// of a swap that can be accepted by the peer until the quote expires.
+func (server *httpServer) postQuotesHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeSwap)
+		if !ok {
+			return
+		}
+
+		quoteReq := PostQuoteRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&quoteReq); err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode quote request: %v", err))
+			return
+		}
+		quoteReq.Password = password
+
+		quote, err := reqHandler.PostQuotes(quoteReq)
+		if err != nil {
+			server.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(quote, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode quote response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusCreated, respBytes)
+	}
+}
+
+// postAcceptQuoteHandler handles the accept quote request, it verifies the
+// signature of the peer's quote and starts the swap.
+func (server *httpServer) postAcceptQuoteHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeSwap)
+		if !ok {
+			return
+		}
+
+		acceptReq := PostAcceptQuoteRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&acceptReq); err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode accept quote request: %v", err))
+			return
+		}
+		acceptReq.Password = password
+
+		passwordHashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode accept quote request: %v", err))
+			return
+		}
+		acceptReq.PasswordHash = base64.StdEncoding.EncodeToString(passwordHashBytes)
+
+		resp, err := reqHandler.PostAcceptQuote(acceptReq)
+		if err != nil {
+			server.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(resp, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode accept quote response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusCreated, respBytes)
+	}
+}
+
+// deleteSwapHandler handles the delete swap request, it cancels the swap with
+// the given swap id if it has not been initiated on our side yet. It responds
+// with 404 if the swap does not exist, and with 409 if the swap is not pending
+// or may have been funded.
+func (server *httpServer) deleteSwapHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeSwap)
+		if !ok {
+			return
+		}
+
+		swapID := mux.Vars(r)["id"]
+		if err := reqHandler.CancelSwap(password, swap.SwapID(swapID)); err != nil {
+			statusCode := http.StatusBadRequest
+			if err == ErrSwapNotFound {
+				statusCode = http.StatusNotFound
+			}
+			if _, ok := err.(ErrSwapNotCancellable); ok {
+				statusCode = http.StatusConflict
+			}
+			server.writeError(w, r, statusCode, fmt.Sprintf("cannot cancel swap with id (%s): %v", swapID, err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusAccepted, []byte{})
+	}
+}
+
+// postRefundSwapHandler handles the refund swap request, it stops the swap with
+// the given swap id from progressing and refunds it as soon as the timelock
+// expires.
+func (server *httpServer) postRefundSwapHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeSwap)
+		if !ok {
+			return
+		}
+
+		swapID := mux.Vars(r)["id"]
+		if err := reqHandler.RefundSwap(password, swap.SwapID(swapID)); err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot refund swap with id (%s): %v", swapID, err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusAccepted, []byte{})
+	}
+}
+
 // postTransferHandler handles the post withdrawal
 func (server *httpServer) postTransfersHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeTransfer)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -240,11 +434,18 @@ func (server *httpServer) postTransfersHandler(reqHandler Handler) http.HandlerF
 		}
 		transferReq.Password = password
 
-		if err := reqHandler.PostTransfers(transferReq); err != nil {
+		resp, err := reqHandler.PostTransfers(transferReq)
+		if err != nil {
 			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode transfers request: %v", err))
 			return
 		}
-		server.writeResponse(w, r, http.StatusCreated, []byte{})
+
+		respBytes, err := json.MarshalIndent(resp, "", "\t")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode transfers response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusCreated, respBytes)
 	}
 }
 
@@ -255,9 +456,8 @@ func (server *httpServer) getBalancesHandler(reqHandler Handler) http.HandlerFun
 		opts := mux.Vars(r)
 		tokenName := opts["token"]
 
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -302,9 +502,8 @@ func (server *httpServer) getBalancesHandler(reqHandler Handler) http.HandlerFun
 func (server *httpServer) getTransfersHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var transfers GetTransfersResponse
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -323,6 +522,124 @@ func (server *httpServer) getTransfersHandler(reqHandler Handler) http.HandlerFu
 	}
 }
 
+// getTransferHandler handles the get transfer request, and returns the receipt
+// of the transfer.
+func (server *httpServer) getTransferHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
+		if !ok {
+			return
+		}
+
+		id := mux.Vars(r)["id"]
+		resp, err := reqHandler.GetTransfer(password, transfer.TransferID(id))
+		if err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot get transfer with id (%s): %v", id, err))
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(resp, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode get transfer response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusOK, respBytes)
+	}
+}
+
+// getEventsHandler handles the get events request, it streams the swap and
+// transfer receipts of the person calling it as server-sent events, whenever
+// they are updated.
+func (server *httpServer) getEventsHandler(stream *Stream) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
+		if !ok {
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			server.writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+			return
+		}
+
+		events := stream.Subscribe(128)
+		defer stream.Unsubscribe(events)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		server.writeResponse(w, r, http.StatusOK, []byte{})
+		flusher.Flush()
+
+		// Comparing bcrypt hashes is slow, so the result is remembered for
+		// every password hash seen on this connection.
+		owned := map[string]bool{}
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-stream.Done():
+				return
+			case <-ticker.C:
+				if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+					return
+				}
+				flusher.Flush()
+			case event := <-events:
+				ok, seen := owned[event.PasswordHash]
+				if !seen {
+					var err error
+					if ok, err = verifyPasswordHash(event.PasswordHash, password); err != nil {
+						server.logger.Warnf("cannot verify the owner of a %s event: %v", event.Type, err)
+						continue
+					}
+					owned[event.PasswordHash] = ok
+				}
+				if !ok {
+					continue
+				}
+
+				data, err := json.Marshal(event.Receipt)
+				if err != nil {
+					server.logger.Warnf("cannot encode %s event: %v", event.Type, err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// getWebhookSecretHandler handles the get webhook secret request, it returns
+// the secret used to sign the webhook notifications of the person calling it.
+func (server *httpServer) getWebhookSecretHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
+		if !ok {
+			return
+		}
+
+		resp, err := reqHandler.GetWebhookSecret(password)
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot get webhook secret: %v", err))
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(resp, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode webhook secret response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusOK, respBytes)
+	}
+}
+
 // getAddressesHandler handles the get addresses request, and returns the addresses
 // of the accounts held by the swapper.
 func (server *httpServer) getAddressesHandler(reqHandler Handler) http.HandlerFunc {
@@ -330,9 +647,8 @@ func (server *httpServer) getAddressesHandler(reqHandler Handler) http.HandlerFu
 		opts := mux.Vars(r)
 		tokenName := opts["token"]
 
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -354,7 +670,12 @@ func (server *httpServer) getAddressesHandler(reqHandler Handler) http.HandlerFu
 				server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid token name: %s", tokenName))
 				return
 			}
-			address, err := reqHandler.GetAddress(password, token)
+			var address GetAddressResponse
+			if token.Blockchain == blockchain.Bitcoin && r.URL.Query().Get("type") == "bech32" {
+				address, err = reqHandler.GetWitnessAddress(password)
+			} else {
+				address, err = reqHandler.GetAddress(password, token)
+			}
 			if err != nil {
 				server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("unable to retrieve address for token: %s", tokenName))
 				return
@@ -367,9 +688,8 @@ func (server *httpServer) getAddressesHandler(reqHandler Handler) http.HandlerFu
 
 func (server *httpServer) getIDHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeRead)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -387,9 +707,8 @@ func (server *httpServer) getIDHandler(reqHandler Handler) http.HandlerFunc {
 
 func (server *httpServer) postSignatureHandler(reqHandler Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		password, ok := server.authenticate(w, r, auth.ScopeSign)
 		if !ok {
-			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
@@ -444,6 +763,186 @@ func (server *httpServer) postSignatureHandler(reqHandler Handler) http.HandlerF
 	}
 }
 
+// postVerifyHandler handles the verify request, it returns whether the message
+// was signed by the owner of the id, using the same scheme as the sign request.
+func (server *httpServer) postVerifyHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := server.authenticate(w, r, auth.ScopeRead); !ok {
+			return
+		}
+
+		var resp PostVerifyResponse
+		vars := mux.Vars(r)
+		switch vars["type"] {
+		case "json":
+			req := PostVerifyRequestJSON{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode verify request: %v", err))
+				return
+			}
+			verified, err := reqHandler.VerifyJSONSignature(req)
+			if err != nil {
+				server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("failed to verify the message: %v", err))
+				return
+			}
+			resp = verified
+		case "base64", "hex":
+			req := PostVerifyRequestString{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode verify request: %v", err))
+				return
+			}
+			verify := reqHandler.VerifyBase64Signature
+			if vars["type"] == "hex" {
+				verify = reqHandler.VerifyHexSignature
+			}
+			verified, err := verify(req)
+			if err != nil {
+				server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("failed to verify the message: %v", err))
+				return
+			}
+			resp = verified
+		default:
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown message type: %s", vars["type"]))
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(resp, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode verify response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusOK, respBytes)
+	}
+}
+
+// postUnlockHandler handles the unlock request, it resumes the pending swaps
+// of the wallet the first time that it is unlocked, and allows its API tokens
+// to be used until the session expires or the wallet is locked.
+func (server *httpServer) postUnlockHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok {
+			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		unlockReq := PostUnlockRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&unlockReq); err != nil && err != io.EOF {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode unlock request: %v", err))
+			return
+		}
+		unlockReq.Password = password
+
+		session, err := reqHandler.Unlock(unlockReq)
+		if err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot unlock wallet: %v", err))
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(session, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode unlock response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusOK, respBytes)
+	}
+}
+
+// postLockHandler handles the lock request, it ends the session of the wallet.
+func (server *httpServer) postLockHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok {
+			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		if err := reqHandler.Lock(password); err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot lock wallet: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusOK, []byte{})
+	}
+}
+
+// postTokensHandler handles the post tokens request, it creates an API token
+// with the requested scopes. API tokens can only be managed using the wallet
+// password.
+func (server *httpServer) postTokensHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok {
+			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		tokenReq := PostTokenRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&tokenReq); err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot decode token request: %v", err))
+			return
+		}
+		tokenReq.Password = password
+
+		resp, err := reqHandler.PostTokens(tokenReq)
+		if err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot create api token: %v", err))
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(resp, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode token response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusCreated, respBytes)
+	}
+}
+
+// getTokensHandler handles the get tokens request, it returns the API tokens
+// created with the wallet password, without their secrets.
+func (server *httpServer) getTokensHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok {
+			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		resp, err := reqHandler.GetTokens(password)
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot get api tokens: %v", err))
+			return
+		}
+
+		respBytes, err := json.MarshalIndent(resp, "\t", "")
+		if err != nil {
+			server.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("cannot encode tokens response: %v", err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusOK, respBytes)
+	}
+}
+
+// deleteTokenHandler handles the delete token request, it revokes the API
+// token with the given id.
+func (server *httpServer) deleteTokenHandler(reqHandler Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok {
+			server.writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		id := mux.Vars(r)["id"]
+		if err := reqHandler.DeleteToken(password, id); err != nil {
+			server.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("cannot delete api token with id (%s): %v", id, err))
+			return
+		}
+		server.writeResponse(w, r, http.StatusAccepted, []byte{})
+	}
+}
+
 func (server *httpServer) writeResponse(w http.ResponseWriter, r *http.Request, statusCode int, resp []byte) {
 	logger := server.logger
 	logger = logger.WithField("method", r.Method)
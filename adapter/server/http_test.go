@@ -14,6 +14,7 @@ import (
 	. "github.com/renproject/swapperd/adapter/server"
 
 	bc "github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/adapter/webhook"
 	"github.com/renproject/swapperd/core/wallet"
 	"github.com/renproject/swapperd/core/wallet/swapper"
 	"github.com/renproject/swapperd/driver/logger"
@@ -36,7 +37,8 @@ var _ = Describe("Server Adapter", func() {
 		blockchain := bc.New(config)
 		storage := testutils.NewMockStorage()
 		logger := logger.NewStdOut()
-		httpServer := NewHttpServer(128, port, receiver, storage, blockchain, logger)
+		webhook := webhook.New(webhook.Config{}, nil, blockchain, logger)
+		httpServer := NewHttpServer(128, port, receiver, NewStream(), storage, blockchain, webhook, swap.TimeLockPolicies{}, logger)
 		return httpServer
 	}
 
@@ -3,22 +3,37 @@ package server
 import (
 	"encoding/json"
 
+	"github.com/renproject/swapperd/adapter/auth"
 	"github.com/renproject/swapperd/core/wallet/transfer"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
 )
 
 type GetInfoResponse struct {
-	Version         string             `json:"version"`
-	Bootloaded      bool               `json:"bootloaded"`
-	SupportedTokens []blockchain.Token `json:"supportedTokens"`
+	Version         string                  `json:"version"`
+	Bootloaded      bool                    `json:"bootloaded"`
+	Unlocked        []auth.Session          `json:"unlocked"`
+	ReadOnly        bool                    `json:"readOnly,omitempty"`
+	SupportedTokens []blockchain.Token      `json:"supportedTokens"`
+	Tokens          []blockchain.TokenEntry `json:"tokens"`
+}
+
+// A PostUnlockRequest unlocks the wallet of the password, for the number of
+// seconds of the expiry. The wallet stays unlocked until it is locked when the
+// expiry is not set.
+type PostUnlockRequest struct {
+	Expiry   int64  `json:"expiry,omitempty"`
+	Password string `json:"-"`
 }
 
 type GetSwapsResponse struct {
 	Swaps []swap.SwapReceipt `json:"swaps"`
 }
 
-type GetSwapResponse swap.SwapReceipt
+type GetSwapResponse struct {
+	swap.SwapReceipt
+	History []swap.StatusUpdate `json:"history"`
+}
 
 type GetBalanceResponse blockchain.Balance
 type GetBalancesResponse map[blockchain.TokenName]blockchain.Balance
@@ -26,26 +41,93 @@ type GetBalancesResponse map[blockchain.TokenName]blockchain.Balance
 type GetAddressesResponse map[blockchain.TokenName]string
 type GetAddressResponse string
 
-type PostSwapRequest swap.SwapBlob
+// A PostSwapRequest starts a swap. When the swap was received from the peer,
+// the signature of the peer over the terms of the swap can be verified by
+// setting the id of the peer.
+type PostSwapRequest struct {
+	swap.SwapBlob
+	Signature string `json:"signature,omitempty"`
+	PeerID    string `json:"peerId,omitempty"`
+}
 
+// A PostSwapResponse contains the swap that the peer responds with. The
+// signature is over the whole swap, and the terms signature is over the terms
+// of the swap that the peer verifies when it starts the swap.
 type PostSwapResponse struct {
-	ID        swap.SwapID   `json:"id"`
-	Swap      swap.SwapBlob `json:"swap,omitempty"`
-	Signature string        `json:"signature,omitempty"`
+	ID             swap.SwapID   `json:"id"`
+	Swap           swap.SwapBlob `json:"swap,omitempty"`
+	Signature      string        `json:"signature,omitempty"`
+	TermsSignature string        `json:"termsSignature,omitempty"`
 }
 
 type PostRedeemSwapResponse struct {
 	ID swap.SwapID `json:"id"`
 }
 
+// A PostQuoteRequest asks for a quote of a swap of the send amount of the send
+// token for the receive amount of the receive token. The expiry is the number
+// of seconds that the quote can be accepted for.
+type PostQuoteRequest struct {
+	SendToken              blockchain.TokenName `json:"sendToken"`
+	ReceiveToken           blockchain.TokenName `json:"receiveToken"`
+	SendAmount             string               `json:"sendAmount"`
+	ReceiveAmount          string               `json:"receiveAmount"`
+	BrokerFee              int64                `json:"brokerFee,omitempty"`
+	BrokerSendTokenAddr    string               `json:"brokerSendTokenAddr,omitempty"`
+	BrokerReceiveTokenAddr string               `json:"brokerReceiveTokenAddr,omitempty"`
+	BitcoinScript          string               `json:"bitcoinScript,omitempty"`
+	Expiry                 int64                `json:"expiry,omitempty"`
+	Password               string               `json:"-"`
+}
+
+// A PostAcceptQuoteRequest accepts a quote of a peer. The quote is rejected if
+// the peer id is set, and the quote was not signed by it.
+type PostAcceptQuoteRequest struct {
+	Quote        Quote  `json:"quote"`
+	PeerID       string `json:"peerId,omitempty"`
+	Password     string `json:"-"`
+	PasswordHash string `json:"-"`
+}
+
+// A PostAcceptQuoteResponse returns the id of the swap started by accepting a
+// quote. When the quote was offered by the peer, it also returns the quote
+// that the peer must accept to start its side of the swap.
+type PostAcceptQuoteResponse struct {
+	ID    swap.SwapID `json:"id"`
+	Quote *Quote      `json:"quote,omitempty"`
+}
+
 type PostTransfersRequest struct {
 	Token    string `json:"token"`
 	To       string `json:"to"`
 	Amount   string `json:"amount"`
 	Password string `json:"password"`
+
+	// Recipients are paid by a batch transfer, instead of the amount being
+	// transferred to a single address.
+	Recipients []PostTransfersRecipient `json:"recipients,omitempty"`
+
+	// SendAll transfers the whole balance, and SubtractFeeFromAmount
+	// transfers the amount minus the fee, so that the transfer spends no more
+	// than the amount.
+	SendAll               bool `json:"sendAll,omitempty"`
+	SubtractFeeFromAmount bool `json:"subtractFeeFromAmount,omitempty"`
+}
+
+type PostTransfersRecipient struct {
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
+
+type PostTransfersResponse struct {
+	ID transfer.TransferID `json:"id"`
 }
 
-type PostTransfersResponse transfer.TransferReceipt
+type GetTransferResponse transfer.TransferReceipt
+
+type GetWebhookSecretResponse struct {
+	Secret string `json:"secret"`
+}
 
 type GetSignatureResponseJSON struct {
 	Message   json.RawMessage `json:"message"`
@@ -57,6 +139,41 @@ type GetSignatureResponseString struct {
 	Signature string `json:"signature"`
 }
 
+type PostVerifyRequestJSON struct {
+	Message   json.RawMessage `json:"message"`
+	Signature string          `json:"signature"`
+	ID        string          `json:"id"`
+}
+
+type PostVerifyRequestString struct {
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+	ID        string `json:"id"`
+}
+
+type PostVerifyResponse struct {
+	Verified bool `json:"verified"`
+}
+
+// A PostTokenRequest creates an API token that can make the requests of its
+// scopes on behalf of the wallet of the password.
+type PostTokenRequest struct {
+	Name     string       `json:"name"`
+	Scopes   []auth.Scope `json:"scopes"`
+	Password string       `json:"-"`
+}
+
+// A PostTokenResponse returns the secret of a new API token. The secret is
+// not stored and cannot be retrieved again.
+type PostTokenResponse struct {
+	auth.Token
+	Secret string `json:"token"`
+}
+
+type GetTokensResponse struct {
+	Tokens []auth.Token `json:"tokens"`
+}
+
 type GetTransfersResponse struct {
 	Transfers []transfer.TransferReceipt `json:"transfers"`
 }
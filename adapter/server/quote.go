@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+// DefaultQuoteExpiry is the time, in seconds, that a quote can be accepted for
+// when the request does not set an expiry.
+const DefaultQuoteExpiry = int64(10 * 60)
+
+var ErrQuoteExpired = fmt.Errorf("quote expired")
+var ErrInvalidQuoteSignature = fmt.Errorf("invalid quote signature")
+var ErrQuoteAccepted = fmt.Errorf("quote already accepted")
+
+// A Quote is a signed offer of a swap, from the point of view of the peer that
+// it is offered to. The peer accepts the quote by answering it with a quote of
+// the mirrored swap, which includes the original offer.
+type Quote struct {
+	Swap      swap.SwapBlob `json:"swap"`
+	Expiry    int64         `json:"expiry"`
+	ID        string        `json:"id"`
+	Offer     *Quote        `json:"offer,omitempty"`
+	Signature string        `json:"signature,omitempty"`
+}
+
+// Message returns the message that is signed by the quote's signature.
+func (quote Quote) Message() ([]byte, error) {
+	quote.Signature = ""
+	return json.Marshal(quote)
+}
+
+// VerifyQuote returns an error if the quote has expired at the given unix
+// time, or was not signed by the owner of its id.
+func VerifyQuote(quote Quote, now int64) error {
+	if now > quote.Expiry {
+		return ErrQuoteExpired
+	}
+	msg, err := quote.Message()
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(quote.Signature)
+	if err != nil {
+		return ErrInvalidQuoteSignature
+	}
+	if err := VerifySignature(msg, sig, quote.ID); err != nil {
+		return ErrInvalidQuoteSignature
+	}
+	return nil
+}
+
+// QuotedSwap returns the swap of the quote with only the terms that were quoted
+// and signed. The other fields of a swap only concern one of the parties, so
+// they are set from our own wallet and request, and never by the peer.
+func QuotedSwap(quote Quote) swap.SwapBlob {
+	blob := swapTerms(quote.Swap)
+	blob.ID = quote.Swap.ID
+	blob.ShouldInitiateFirst = false
+	return blob
+}
+
+// verifyMirror returns an error if the terms of the mirrored swap are not the
+// terms of the swap, as seen by the other party.
+func verifyMirror(blob, mirror swap.SwapBlob) error {
+	if mirror.ID != blob.ID ||
+		mirror.SendToken != blob.ReceiveToken ||
+		mirror.ReceiveToken != blob.SendToken ||
+		mirror.SendAmount != blob.ReceiveAmount ||
+		mirror.ReceiveAmount != blob.SendAmount ||
+		mirror.SecretHash != blob.SecretHash ||
+		mirror.TimeLock != blob.TimeLock ||
+		mirror.BrokerFee != blob.BrokerFee ||
+		mirror.BrokerSendTokenAddr != blob.BrokerReceiveTokenAddr ||
+		mirror.BrokerReceiveTokenAddr != blob.BrokerSendTokenAddr ||
+		mirror.BitcoinScript != blob.BitcoinScript {
+		return fmt.Errorf("accepted swap does not match the quote")
+	}
+	return nil
+}
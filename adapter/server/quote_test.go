@@ -0,0 +1,101 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/foundation/swap"
+	"golang.org/x/crypto/sha3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/server"
+)
+
+var _ = Describe("Quotes", func() {
+	sign := func(key *ecdsa.PrivateKey, quote Quote) Quote {
+		quote.ID = base64.StdEncoding.EncodeToString(crypto.FromECDSAPub(&key.PublicKey))
+		msg, err := quote.Message()
+		Expect(err).ShouldNot(HaveOccurred())
+		hash := sha3.Sum256(msg)
+		sig, err := crypto.Sign(hash[:], key)
+		Expect(err).ShouldNot(HaveOccurred())
+		quote.Signature = base64.StdEncoding.EncodeToString(sig)
+		return quote
+	}
+
+	newQuote := func(expiry int64) (*ecdsa.PrivateKey, Quote) {
+		key, err := crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+		return key, sign(key, Quote{
+			Swap: swap.SwapBlob{
+				ID:            swap.SwapID("swap"),
+				SendToken:     "BTC",
+				ReceiveToken:  "ETH",
+				SendAmount:    "100000",
+				ReceiveAmount: "1000000000000000000",
+				TimeLock:      time.Now().Unix() + 3600,
+				SecretHash:    base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			},
+			Expiry: expiry,
+		})
+	}
+
+	Context("when verifying a quote", func() {
+		It("should accept a quote signed by the owner of its id", func() {
+			_, quote := newQuote(time.Now().Unix() + 60)
+			Expect(VerifyQuote(quote, time.Now().Unix())).ShouldNot(HaveOccurred())
+		})
+
+		It("should reject an expired quote", func() {
+			_, quote := newQuote(time.Now().Unix() - 60)
+			Expect(VerifyQuote(quote, time.Now().Unix())).Should(Equal(ErrQuoteExpired))
+		})
+
+		It("should reject a quote whose terms have changed", func() {
+			_, quote := newQuote(time.Now().Unix() + 60)
+			quote.Swap.ReceiveAmount = "1"
+			Expect(VerifyQuote(quote, time.Now().Unix())).Should(Equal(ErrInvalidQuoteSignature))
+		})
+
+		It("should only take the quoted terms from a quote", func() {
+			key, quote := newQuote(time.Now().Unix() + 60)
+			quote.Swap.SendTo = "mzKgUBHX7xSkKiNrdnxTe6fJKAcvFri2Tc"
+			quote.Swap.ReceiveFrom = "0x5Ea5F67cC958023F2da2ea92231d358F2a3BbA47"
+			quote.Swap.WithdrawAddress = "0x0000000000000000000000000000000000000bad"
+			quote.Swap.NotifyURL = "https://attacker.example/notify"
+			quote.Swap.ResponseURL = "https://attacker.example/response"
+			quote.Swap.SendFee = "100000000"
+			quote.Swap.ReceiveFee = "100000000"
+			quote.Swap.Delay = true
+			quote.Swap.DelayCallbackURL = "https://attacker.example/delay"
+			quote.Swap.ForceRefund = true
+			quote.Swap.AddressIndex = 42
+			quote = sign(key, quote)
+			Expect(VerifyQuote(quote, time.Now().Unix())).ShouldNot(HaveOccurred())
+
+			Expect(QuotedSwap(quote)).Should(Equal(swap.SwapBlob{
+				ID:            quote.Swap.ID,
+				SendToken:     quote.Swap.SendToken,
+				ReceiveToken:  quote.Swap.ReceiveToken,
+				SendAmount:    quote.Swap.SendAmount,
+				ReceiveAmount: quote.Swap.ReceiveAmount,
+				SendTo:        quote.Swap.SendTo,
+				ReceiveFrom:   quote.Swap.ReceiveFrom,
+				TimeLock:      quote.Swap.TimeLock,
+				SecretHash:    quote.Swap.SecretHash,
+			}))
+		})
+
+		It("should reject a quote signed by someone else", func() {
+			_, quote := newQuote(time.Now().Unix() + 60)
+			other, _ := newQuote(time.Now().Unix() + 60)
+			id := quote.ID
+			quote = sign(other, quote)
+			quote.ID = id
+			Expect(VerifyQuote(quote, time.Now().Unix())).Should(Equal(ErrInvalidQuoteSignature))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/renproject/swapperd/core/wallet/transfer"
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+const (
+	EventSwap     = "swap"
+	EventTransfer = "transfer"
+)
+
+// An Event is a swap or transfer receipt that has just been written to
+// storage. The PasswordHash is the hash of the receipt owner's password, and is
+// not sent to subscribers.
+type Event struct {
+	Type         string
+	PasswordHash string
+	Receipt      interface{}
+}
+
+// A Stream publishes the swap and transfer receipts written to storage to all
+// of its subscribers.
+type Stream struct {
+	mu          *sync.RWMutex
+	done        chan struct{}
+	subscribers map[chan Event]struct{}
+}
+
+// NewStream returns a Stream without any subscribers.
+func NewStream() *Stream {
+	return &Stream{
+		mu:          new(sync.RWMutex),
+		done:        make(chan struct{}),
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// NotifyReceipt publishes the swap receipt to all subscribers.
+func (stream *Stream) NotifyReceipt(receipt swap.SwapReceipt) {
+	passwordHash := receipt.PasswordHash
+	receipt.PasswordHash = ""
+	stream.publish(Event{EventSwap, passwordHash, receipt})
+}
+
+// NotifyTransfer publishes the transfer receipt to all subscribers.
+func (stream *Stream) NotifyTransfer(receipt transfer.TransferReceipt) {
+	passwordHash := receipt.PasswordHash
+	receipt.PasswordHash = ""
+	stream.publish(Event{EventTransfer, passwordHash, receipt})
+}
+
+// Subscribe returns a channel that receives every event published after the
+// call. Events are dropped for subscribers that are not keeping up, so that
+// writing to storage never blocks on a slow client.
+func (stream *Stream) Subscribe(cap int) chan Event {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	events := make(chan Event, cap)
+	stream.subscribers[events] = struct{}{}
+	return events
+}
+
+// Unsubscribe stops publishing events to the channel.
+func (stream *Stream) Unsubscribe(events chan Event) {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	delete(stream.subscribers, events)
+}
+
+// Done returns a channel that is closed when the stream is shutdown.
+func (stream *Stream) Done() <-chan struct{} {
+	return stream.done
+}
+
+// Shutdown stops the stream, and signals all subscribers to stop reading.
+func (stream *Stream) Shutdown() {
+	close(stream.done)
+}
+
+func (stream *Stream) publish(event Event) {
+	stream.mu.RLock()
+	defer stream.mu.RUnlock()
+
+	for events := range stream.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package server_test
+
+import (
+	"testing/quick"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/server"
+
+	"github.com/renproject/swapperd/core/wallet/transfer"
+	"github.com/renproject/swapperd/foundation/swap"
+	"github.com/renproject/swapperd/testutils"
+)
+
+var _ = Describe("Stream", func() {
+	Context("when a receipt is written", func() {
+		It("should publish swap receipts to subscribers without the password hash", func() {
+			stream := NewStream()
+			events := stream.Subscribe(1)
+			defer stream.Unsubscribe(events)
+
+			test := func(receipt swap.SwapReceipt) bool {
+				stream.NotifyReceipt(receipt)
+				event := <-events
+
+				passwordHash := receipt.PasswordHash
+				receipt.PasswordHash = ""
+				return event.Type == EventSwap && event.PasswordHash == passwordHash && event.Receipt.(swap.SwapReceipt).ID == receipt.ID && event.Receipt.(swap.SwapReceipt).PasswordHash == ""
+			}
+
+			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+		})
+
+		It("should publish transfer receipts to subscribers without the password hash", func() {
+			stream := NewStream()
+			events := stream.Subscribe(1)
+			defer stream.Unsubscribe(events)
+
+			test := func(txHash, passwordHash string) bool {
+				receipt := transfer.TransferReceipt{PasswordHash: passwordHash}
+				receipt.TxHash = txHash
+				stream.NotifyTransfer(receipt)
+				event := <-events
+
+				return event.Type == EventTransfer && event.PasswordHash == passwordHash && event.Receipt.(transfer.TransferReceipt).TxHash == txHash && event.Receipt.(transfer.TransferReceipt).PasswordHash == ""
+			}
+
+			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+		})
+
+		It("should not block when a subscriber is not reading", func() {
+			stream := NewStream()
+			events := stream.Subscribe(1)
+			defer stream.Unsubscribe(events)
+
+			stream.NotifyReceipt(swap.SwapReceipt{ID: "first"})
+			stream.NotifyReceipt(swap.SwapReceipt{ID: "second"})
+			Expect((<-events).Receipt.(swap.SwapReceipt).ID).Should(Equal(swap.SwapID("first")))
+			Expect(events).ShouldNot(Receive())
+		})
+
+		It("should not publish to unsubscribed channels", func() {
+			stream := NewStream()
+			events := stream.Subscribe(1)
+			stream.Unsubscribe(events)
+
+			stream.NotifyReceipt(swap.SwapReceipt{})
+			Expect(events).ShouldNot(Receive())
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/foundation/swap"
+	"golang.org/x/crypto/sha3"
+)
+
+var ErrInvalidSignature = fmt.Errorf("invalid signature")
+
+// VerifySignature returns an error if the signature of the message was not
+// produced by the owner of the id. Signatures are verified against the sha3
+// hash of the message, and the id is either the base64 encoded public key or
+// the ethereum address of the signer.
+func VerifySignature(message, signature []byte, id string) error {
+	hash := sha3.Sum256(message)
+	pubKey, err := crypto.SigToPub(hash[:], signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if strings.HasPrefix(id, "0x") {
+		if !common.IsHexAddress(id) || crypto.PubkeyToAddress(*pubKey) != common.HexToAddress(id) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	pubKeyBytes, err := base64.StdEncoding.DecodeString(id)
+	if err != nil || !bytes.Equal(crypto.FromECDSAPub(pubKey), pubKeyBytes) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifySwapSignature returns an error if the swap has a signature or a peer
+// id, and the terms of the swap were not signed by the peer.
+func verifySwapSignature(req PostSwapRequest) error {
+	if req.Signature == "" && req.PeerID == "" {
+		return nil
+	}
+	if req.Signature == "" || req.PeerID == "" {
+		return fmt.Errorf("both the signature and the peer id are required to verify a swap")
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	terms, err := json.Marshal(swapTerms(req.SwapBlob))
+	if err != nil {
+		return err
+	}
+	return VerifySignature(terms, sig, req.PeerID)
+}
+
+// swapTerms returns the canonical blob of the terms of the swap that both
+// parties agree on, without the options that only concern one of them.
+func swapTerms(blob swap.SwapBlob) swap.SwapBlob {
+	return swap.SwapBlob{
+		SendToken:              blob.SendToken,
+		ReceiveToken:           blob.ReceiveToken,
+		SendAmount:             blob.SendAmount,
+		ReceiveAmount:          blob.ReceiveAmount,
+		SendTo:                 blob.SendTo,
+		ReceiveFrom:            blob.ReceiveFrom,
+		TimeLock:               blob.TimeLock,
+		SecretHash:             blob.SecretHash,
+		ShouldInitiateFirst:    blob.ShouldInitiateFirst,
+		BrokerFee:              blob.BrokerFee,
+		BrokerSendTokenAddr:    blob.BrokerSendTokenAddr,
+		BrokerReceiveTokenAddr: blob.BrokerReceiveTokenAddr,
+		BitcoinScript:          blob.BitcoinScript,
+	}
+}
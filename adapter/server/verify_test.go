@@ -0,0 +1,47 @@
+package server_test
+
+import (
+	"encoding/base64"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/sha3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/server"
+)
+
+var _ = Describe("Signature verification", func() {
+	message := []byte(`{"sendToken":"BTC","receiveToken":"ETH"}`)
+
+	sign := func(message []byte) ([]byte, string, string) {
+		key, err := crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+		hash := sha3.Sum256(message)
+		sig, err := crypto.Sign(hash[:], key)
+		Expect(err).ShouldNot(HaveOccurred())
+		return sig, base64.StdEncoding.EncodeToString(crypto.FromECDSAPub(&key.PublicKey)), crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+
+	It("should verify signatures against the public key of the signer", func() {
+		sig, id, _ := sign(message)
+		Expect(VerifySignature(message, sig, id)).ShouldNot(HaveOccurred())
+	})
+
+	It("should verify signatures against the ethereum address of the signer", func() {
+		sig, _, address := sign(message)
+		Expect(VerifySignature(message, sig, address)).ShouldNot(HaveOccurred())
+	})
+
+	It("should reject signatures of other messages", func() {
+		sig, id, _ := sign(message)
+		Expect(VerifySignature([]byte("{}"), sig, id)).Should(Equal(ErrInvalidSignature))
+	})
+
+	It("should reject signatures of other signers", func() {
+		sig, _, _ := sign(message)
+		_, id, address := sign(message)
+		Expect(VerifySignature(message, sig, id)).Should(Equal(ErrInvalidSignature))
+		Expect(VerifySignature(message, sig, address)).Should(Equal(ErrInvalidSignature))
+	})
+})
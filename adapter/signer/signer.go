@@ -0,0 +1,127 @@
+// Package signer implements a remote wallet.Signer, which keeps the keys of
+// the wallets in a separate process. The processes speak JSON-RPC 1.0, as
+// implemented by net/rpc/jsonrpc, over a unix socket.
+//
+// The signer process serves the "Signer.PublicKey" and "Signer.Sign" methods.
+// Both take a Request, and return a PublicKeyResponse and a SignResponse.
+package signer
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/adapter/wallet"
+)
+
+// DialTimeout is the time after which connecting to the signer fails.
+const DialTimeout = 10 * time.Second
+
+// A Request asks for the public key of the derivation path, or for the
+// signature of the hash with its key. Byte slices are base64 encoded.
+type Request struct {
+	Password string   `json:"password"`
+	Path     []uint32 `json:"path"`
+	Hash     []byte   `json:"hash,omitempty"`
+}
+
+// A PublicKeyResponse holds the uncompressed public key of the path.
+type PublicKeyResponse struct {
+	PublicKey []byte `json:"publicKey"`
+}
+
+// A SignResponse holds the 65 byte [R || S || V] signature of the hash.
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+type remote struct {
+	socket string
+}
+
+// NewRemote returns the wallet.Signer of the signer process that listens on
+// the unix socket.
+func NewRemote(socket string) wallet.Signer {
+	return &remote{socket}
+}
+
+func (remote *remote) PublicKey(password string, path []uint32) (ecdsa.PublicKey, error) {
+	resp := PublicKeyResponse{}
+	if err := remote.call("Signer.PublicKey", Request{Password: password, Path: path}, &resp); err != nil {
+		return ecdsa.PublicKey{}, err
+	}
+	pubKey, err := crypto.UnmarshalPubkey(resp.PublicKey)
+	if err != nil {
+		return ecdsa.PublicKey{}, fmt.Errorf("invalid public key from signer: %v", err)
+	}
+	return *pubKey, nil
+}
+
+func (remote *remote) Sign(password string, path []uint32, hash []byte) ([]byte, error) {
+	resp := SignResponse{}
+	if err := remote.call("Signer.Sign", Request{Password: password, Path: path, Hash: hash}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Signature) != 65 {
+		return nil, fmt.Errorf("invalid signature length from signer: %d", len(resp.Signature))
+	}
+	return resp.Signature, nil
+}
+
+func (remote *remote) call(method string, req Request, resp interface{}) error {
+	conn, err := net.DialTimeout("unix", remote.socket, DialTimeout)
+	if err != nil {
+		return fmt.Errorf("cannot connect to signer: %v", err)
+	}
+	client := jsonrpc.NewClient(conn)
+	defer client.Close()
+	return client.Call(method, req, resp)
+}
+
+// Serve serves the requests of remote signers on the listener with the keys of
+// the signer. It returns when the listener is closed.
+func Serve(listener net.Listener, signer wallet.Signer) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Signer", &Service{signer}); err != nil {
+		return err
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Service exposes the methods of a wallet.Signer over net/rpc.
+type Service struct {
+	signer wallet.Signer
+}
+
+// PublicKey returns the public key of the path of the request.
+func (service *Service) PublicKey(req Request, resp *PublicKeyResponse) error {
+	pubKey, err := service.signer.PublicKey(req.Password, req.Path)
+	if err != nil {
+		return err
+	}
+	resp.PublicKey = crypto.FromECDSAPub(&pubKey)
+	return nil
+}
+
+// Sign returns the signature of the hash of the request.
+func (service *Service) Sign(req Request, resp *SignResponse) error {
+	if len(req.Hash) != 32 {
+		return fmt.Errorf("invalid hash length: %d", len(req.Hash))
+	}
+	sig, err := service.signer.Sign(req.Password, req.Path, req.Hash)
+	if err != nil {
+		return err
+	}
+	resp.Signature = sig
+	return nil
+}
@@ -0,0 +1,122 @@
+package signer_test
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/signer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+var _ = Describe("Remote signer", func() {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	config := wallet.Config{
+		Mnemonic: mnemonic,
+		Litecoin: wallet.BlockchainConfig{Network: wallet.Network{Name: "testnet"}},
+	}
+
+	var dir string
+	var listener net.Listener
+	var remote wallet.Signer
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "signer")
+		Expect(err).ShouldNot(HaveOccurred())
+		socket := filepath.Join(dir, "signer.sock")
+		listener, err = net.Listen("unix", socket)
+		Expect(err).ShouldNot(HaveOccurred())
+		go Serve(listener, wallet.NewMnemonicSigner(mnemonic))
+		remote = NewRemote(socket)
+	})
+
+	AfterEach(func() {
+		listener.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("should return the public keys of the signer process", func() {
+		local := wallet.NewMnemonicSigner(mnemonic)
+		expected, err := local.PublicKey("password", wallet.IdentityPath)
+		Expect(err).ShouldNot(HaveOccurred())
+		pubKey, err := remote.PublicKey("password", wallet.IdentityPath)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(crypto.FromECDSAPub(&pubKey)).Should(Equal(crypto.FromECDSAPub(&expected)))
+	})
+
+	It("should sign hashes with the key of the path", func() {
+		hash := crypto.Keccak256([]byte("message"))
+		sig, err := remote.Sign("password", []uint32{44, 1, 0, 0, 3}, hash)
+		Expect(err).ShouldNot(HaveOccurred())
+		pubKey, err := crypto.SigToPub(hash, sig)
+		Expect(err).ShouldNot(HaveOccurred())
+		expected, err := remote.PublicKey("password", []uint32{44, 1, 0, 0, 3})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(crypto.FromECDSAPub(pubKey)).Should(Equal(crypto.FromECDSAPub(&expected)))
+	})
+
+	It("should reject hashes of the wrong length", func() {
+		_, err := remote.Sign("password", wallet.IdentityPath, []byte("message"))
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should give wallets the same identity and addresses", func() {
+		local := wallet.New(config)
+		remoteWallet := wallet.NewWithSigner(config, remote)
+
+		id, err := remoteWallet.ID("password", "")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(local.ID("password", "")).Should(Equal(id))
+
+		address, err := remoteWallet.GetAddressAt("password", blockchain.Litecoin, 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(local.GetAddressAt("password", blockchain.Litecoin, 2)).Should(Equal(address))
+	})
+
+	It("should sign ethereum transactions without exporting private keys", func() {
+		local := wallet.New(config)
+		remoteWallet := wallet.NewWithSigner(config, remote)
+
+		tops, err := remoteWallet.EthereumTransactOpts("password")
+		Expect(err).ShouldNot(HaveOccurred())
+		address, err := local.GetAddress("password", blockchain.Ethereum)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tops.From).Should(Equal(common.HexToAddress(address)))
+
+		signer := types.NewEIP155Signer(big.NewInt(42))
+		tx := types.NewTransaction(0, common.HexToAddress(address), big.NewInt(1), 21000, big.NewInt(1), nil)
+		signed, err := tops.Signer(signer, tops.From, tx)
+		Expect(err).ShouldNot(HaveOccurred())
+		from, err := types.Sender(signer, signed)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(from).Should(Equal(tops.From))
+
+		_, err = tops.Signer(signer, common.Address{}, tx)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should sign bitcoin transactions without exporting private keys", func() {
+		local := wallet.New(config)
+		remoteWallet := wallet.NewWithSigner(config, remote)
+
+		account, err := remoteWallet.BitcoinAccount("password")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(local.GetAddress("password", blockchain.Bitcoin)).Should(Equal(account.Address()))
+	})
+
+	It("should fail when the signer process is not running", func() {
+		listener.Close()
+		_, err := remote.PublicKey("password", wallet.IdentityPath)
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -2,25 +2,26 @@ package wallet
 
 import (
 	"bytes"
-	"crypto/ecdsa"
 	"crypto/rsa"
 
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
+	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/republicprotocol/beth-go"
-	"github.com/republicprotocol/libbtc-go"
 	"github.com/tyler-smith/go-bip32"
 	"github.com/tyler-smith/go-bip39"
 )
 
+// EthereumAccount returns the ethereum account of the password. Signers that do
+// not export their private keys sign the transactions of the account one by
+// one.
 func (wallet *wallet) EthereumAccount(password string) (beth.Account, error) {
-	var derivationPath []uint32
-	switch wallet.config.Ethereum.Network.Name {
-	case "kovan", "ropsten":
-		derivationPath = []uint32{44, 1, 0, 0, 0}
-	case "mainnet":
-		derivationPath = []uint32{44, 60, 0, 0, 0}
+	if wallet.WatchOnly() {
+		return nil, ErrWatchOnly
 	}
-	privKey, err := wallet.loadECDSAKey(password, derivationPath)
+	if _, ok := wallet.signer.(KeyExporter); !ok {
+		return wallet.ethereumSignerAccount(password)
+	}
+	privKey, err := wallet.privateKey(password, wallet.ethereumDerivationPath())
 	if err != nil {
 		return nil, err
 	}
@@ -31,43 +32,54 @@ func (wallet *wallet) EthereumAccount(password string) (beth.Account, error) {
 	return ethAccount, nil
 }
 
-// BitcoinAccount returns the bitcoin account
-func (wallet *wallet) BitcoinAccount(password string) (libbtc.Account, error) {
-	var derivationPath []uint32
-	switch wallet.config.Bitcoin.Network.Name {
-	case "testnet", "testnet3":
-		derivationPath = []uint32{44, 1, 0, 0, 0}
+func (wallet *wallet) ethereumDerivationPath() []uint32 {
+	switch wallet.config.Ethereum.Network.Name {
+	case "kovan", "ropsten":
+		return derivationPath(1, wallet.config.Ethereum.Account, 0)
 	case "mainnet":
-		derivationPath = []uint32{44, 0, 0, 0, 0}
-	}
-	privKey, err := wallet.loadECDSAKey(password, derivationPath)
-	if err != nil {
-		return nil, err
+		return derivationPath(60, wallet.config.Ethereum.Account, 0)
 	}
-	return libbtc.NewAccount(libbtc.NewBlockchainInfoClient(wallet.config.Bitcoin.Network.Name), privKey), nil
+	return nil
 }
 
-func (wallet *wallet) loadECDSAKey(password string, path []uint32) (*ecdsa.PrivateKey, error) {
-	seed := bip39.NewSeed(wallet.config.Mnemonic, password)
-	key, err := bip32.NewMasterKey(seed)
-	if err != nil {
-		return nil, err
+// BitcoinAccount returns the bitcoin account of the first address.
+func (wallet *wallet) BitcoinAccount(password string) (utxo.Account, error) {
+	return wallet.BitcoinAccountAt(password, 0)
+}
+
+// BitcoinAccountAt returns the bitcoin account of the address index. It signs
+// with the Signer of the wallet, and uses the shared Esplora client.
+func (wallet *wallet) BitcoinAccountAt(password string, index uint32) (utxo.Account, error) {
+	if wallet.WatchOnly() {
+		return nil, ErrWatchOnly
 	}
-	for _, val := range path {
-		key, err = key.NewChildKey(val)
-		if err != nil {
-			return nil, err
-		}
+	return wallet.UTXOAccountAt(password, blockchain.Bitcoin, index)
+}
+
+func (wallet *wallet) bitcoinDerivationPath(index uint32) []uint32 {
+	switch wallet.config.Bitcoin.Network.Name {
+	case "testnet", "testnet3":
+		return derivationPath(1, wallet.config.Bitcoin.Account, index)
+	case "mainnet":
+		return derivationPath(0, wallet.config.Bitcoin.Account, index)
 	}
-	return crypto.ToECDSA(key.Key)
+	return nil
 }
 
-func (wallet *wallet) ECDSASigner(password string) (ECDSASigner, error) {
-	privKey, err := wallet.loadECDSAKey(password, []uint32{0})
-	if err != nil {
-		return nil, err
+// derivationPath returns the BIP44 path of the address index of the account.
+// Account 0 keeps the unhardened path of earlier versions, so that existing
+// funds stay at the same addresses.
+func derivationPath(coinType, account, index uint32) []uint32 {
+	if account == 0 {
+		return []uint32{44, coinType, 0, 0, index}
+	}
+	return []uint32{
+		bip32.FirstHardenedChild + 44,
+		bip32.FirstHardenedChild + coinType,
+		bip32.FirstHardenedChild + account,
+		0,
+		index,
 	}
-	return &ecdsaSigner{privKey}, nil
 }
 
 func (wallet *wallet) loadRSAKey(password string) (*rsa.PrivateKey, error) {
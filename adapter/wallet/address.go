@@ -8,8 +8,9 @@ import (
 
 	"github.com/republicprotocol/co-go"
 
-	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/renproject/swapperd/foundation/blockchain"
 )
 
@@ -35,30 +36,80 @@ func (wallet *wallet) GetAddress(password string, blockchainName blockchain.Bloc
 	case blockchain.Ethereum, blockchain.ERC20:
 		return wallet.getEthereumAddress(password)
 	case blockchain.Bitcoin:
-		return wallet.getBitcoinAddress(password)
+		return wallet.getBitcoinAddress(password, false)
+	case blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.getUTXOAddress(password, blockchainName)
 	default:
 		return "", blockchain.NewErrUnsupportedToken("unsupported blockchain")
 	}
 }
 
+// GetAddressAt returns the address of the address index on the blockchain.
+// Blockchains without address indices always return the first address.
+func (wallet *wallet) GetAddressAt(password string, blockchainName blockchain.BlockchainName, index uint32) (string, error) {
+	if index == 0 || !blockchainName.HasAddressIndices() {
+		return wallet.GetAddress(password, blockchainName)
+	}
+	switch blockchainName {
+	case blockchain.Bitcoin:
+		return wallet.getBitcoinAddressAt(password, index, false)
+	default:
+		account, err := wallet.UTXOAccountAt(password, blockchainName, index)
+		if err != nil {
+			return "", err
+		}
+		return account.Address(), nil
+	}
+}
+
 func (wallet *wallet) getEthereumAddress(password string) (string, error) {
-	ethAccount, err := wallet.EthereumAccount(password)
+	pubKey, err := wallet.signer.PublicKey(password, wallet.ethereumDerivationPath())
 	if err != nil {
 		return "", err
 	}
-	return ethAccount.Address().String(), nil
+	return crypto.PubkeyToAddress(pubKey).String(), nil
 }
 
-func (wallet *wallet) getBitcoinAddress(password string) (string, error) {
-	btcAccount, err := wallet.BitcoinAccount(password)
+func (wallet *wallet) getUTXOAddress(password string, blockchainName blockchain.BlockchainName) (string, error) {
+	account, err := wallet.UTXOAccount(password, blockchainName)
 	if err != nil {
 		return "", err
 	}
-	btcAddr, err := btcAccount.Address()
+	return account.Address(), nil
+}
+
+// GetWitnessAddress returns the native segwit (bech32) address of the bitcoin
+// key. It has the same public key hash as the legacy address, so either can be
+// used to build swap scripts.
+func (wallet *wallet) GetWitnessAddress(password string) (string, error) {
+	return wallet.getBitcoinAddress(password, true)
+}
+
+func (wallet *wallet) getBitcoinAddress(password string, witness bool) (string, error) {
+	return wallet.getBitcoinAddressAt(password, 0, witness)
+}
+
+// getBitcoinAddressAt returns the P2PKH, or P2WPKH, address of the compressed
+// public key of the address index.
+func (wallet *wallet) getBitcoinAddressAt(password string, index uint32, witness bool) (string, error) {
+	pubKey, err := wallet.signer.PublicKey(password, wallet.bitcoinDerivationPath(index))
 	if err != nil {
 		return "", err
 	}
-	return btcAddr.String(), nil
+	params := bitcoinNetworkParams(wallet.config.Bitcoin.Network.Name)
+	pubKeyHash := btcutil.Hash160((*btcec.PublicKey)(&pubKey).SerializeCompressed())
+	if !witness {
+		btcAddr, err := btcutil.NewAddressPubKeyHash(pubKeyHash, params)
+		if err != nil {
+			return "", err
+		}
+		return btcAddr.EncodeAddress(), nil
+	}
+	witnessAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, params)
+	if err != nil {
+		return "", err
+	}
+	return witnessAddr.EncodeAddress(), nil
 }
 
 func (wallet *wallet) VerifyAddress(blockchainName blockchain.BlockchainName, address string) error {
@@ -67,6 +118,8 @@ func (wallet *wallet) VerifyAddress(blockchainName blockchain.BlockchainName, ad
 		return wallet.verifyEthereumAddress(address)
 	case blockchain.Bitcoin:
 		return wallet.verifyBitcoinAddress(address)
+	case blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.verifyUTXOAddress(blockchainName, address)
 	default:
 		return blockchain.NewErrUnsupportedToken("unsupported blockchain")
 	}
@@ -95,16 +148,32 @@ func (wallet *wallet) verifyBitcoinAddress(address string) error {
 	}
 
 	network := wallet.config.Bitcoin.Network.Name
-	switch network {
-	case "mainnet":
-		if _, err := btcutil.DecodeAddress(address, &chaincfg.MainNetParams); err != nil {
-			return fmt.Errorf("Invalid %s bitcoin address: %s", network, address)
-		}
+	params := bitcoinNetworkParams(network)
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil || !addr.IsForNet(params) {
+		return fmt.Errorf("Invalid %s bitcoin address: %s", network, address)
+	}
 
-	case "testnet":
-		if _, err := btcutil.DecodeAddress(address, &chaincfg.TestNet3Params); err != nil {
-			return fmt.Errorf("Invalid %s bitcoin address: %s", network, address)
-		}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash,
+		*btcutil.AddressWitnessPubKeyHash, *btcutil.AddressWitnessScriptHash:
+		return nil
+	default:
+		return fmt.Errorf("Unsupported %s bitcoin address: %s", network, address)
+	}
+}
+
+func (wallet *wallet) verifyUTXOAddress(blockchainName blockchain.BlockchainName, address string) error {
+	if address == "" {
+		return fmt.Errorf("Empty %s address", blockchainName)
+	}
+
+	chain, err := wallet.utxoChain(blockchainName)
+	if err != nil {
+		return err
+	}
+	if _, _, err := chain.Format.DecodeAddress(address); err != nil {
+		return fmt.Errorf("Invalid %s address: %s", blockchainName, address)
 	}
 	return nil
 }
@@ -1 +1,85 @@
 package wallet_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/wallet"
+
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+var _ = Describe("Addresses", func() {
+	testnet := New(Config{Bitcoin: BlockchainConfig{Network: Network{Name: "testnet"}}})
+
+	Context("when verifying bitcoin addresses", func() {
+		It("should accept legacy and native segwit addresses", func() {
+			for _, address := range []string{
+				"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn",
+				"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+				"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+			} {
+				Expect(testnet.VerifyAddress(blockchain.Bitcoin, address)).ShouldNot(HaveOccurred())
+			}
+		})
+
+		It("should reject addresses of other networks", func() {
+			Expect(testnet.VerifyAddress(blockchain.Bitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")).Should(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.Bitcoin, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")).Should(HaveOccurred())
+		})
+
+		It("should reject invalid addresses", func() {
+			Expect(testnet.VerifyAddress(blockchain.Bitcoin, "")).Should(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.Bitcoin, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsy")).Should(HaveOccurred())
+		})
+	})
+
+	Context("when verifying litecoin and bitcoin cash addresses", func() {
+		It("should accept addresses of the network", func() {
+			Expect(testnet.VerifyAddress(blockchain.Litecoin, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn")).ShouldNot(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.Litecoin, "QXRDpPWNPdM54FMv9ECpZtyH3ZsL5zGe29")).ShouldNot(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.BitcoinCash, "bchtest:qpm2qsznhks23z7629mms6s4cwef74vcwvqcw003ap")).ShouldNot(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.BitcoinCash, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn")).ShouldNot(HaveOccurred())
+		})
+
+		It("should reject addresses of other blockchains", func() {
+			Expect(testnet.VerifyAddress(blockchain.Litecoin, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx")).Should(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.BitcoinCash, "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a")).Should(HaveOccurred())
+			Expect(testnet.VerifyAddress(blockchain.Litecoin, "")).Should(HaveOccurred())
+		})
+	})
+
+	Context("when deriving the addresses of swaps", func() {
+		mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+		config := Config{
+			Mnemonic: mnemonic,
+			Litecoin: BlockchainConfig{Network: Network{Name: "testnet"}},
+		}
+
+		It("should keep the first address at the first index", func() {
+			wallet := New(config)
+			address, err := wallet.GetAddress("password", blockchain.Litecoin)
+			Expect(err).ShouldNot(HaveOccurred())
+			first, err := wallet.GetAddressAt("password", blockchain.Litecoin, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(first).Should(Equal(address))
+		})
+
+		It("should derive a different address for every index and account", func() {
+			wallet := New(config)
+			addresses := map[string]bool{}
+			for index := uint32(0); index < 4; index++ {
+				address, err := wallet.GetAddressAt("password", blockchain.Litecoin, index)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(wallet.VerifyAddress(blockchain.Litecoin, address)).ShouldNot(HaveOccurred())
+				addresses[address] = true
+			}
+			Expect(addresses).Should(HaveLen(4))
+
+			accountConfig := config
+			accountConfig.Litecoin.Account = 1
+			address, err := New(accountConfig).GetAddress("password", blockchain.Litecoin)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(addresses).ShouldNot(HaveKey(address))
+		})
+	})
+})
@@ -1,9 +1,12 @@
 package wallet
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/renproject/swapperd/foundation/blockchain"
 )
 
@@ -13,8 +16,8 @@ func (wallet *wallet) VerifyBalance(password string, token blockchain.Token, amo
 		return wallet.verifyEthereumBalance(password, amount)
 	case blockchain.ERC20:
 		return wallet.verifyERC20Balance(password, token, amount)
-	case blockchain.Bitcoin:
-		return wallet.verifyBitcoinBalance(password, amount)
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.verifyUTXOBalance(password, token, amount)
 	default:
 		return blockchain.NewErrUnsupportedToken("unsupported blockchain")
 	}
@@ -35,7 +38,7 @@ func (wallet *wallet) verifyEthereumBalance(password string, amount *big.Int) er
 		balanceAmount = new(big.Int).Sub(balanceAmount, amount)
 	}
 
-	fee, err := blockchain.TokenETH.TransactionCost(amount)
+	fee, err := wallet.FeeEstimator().TransactionCost(blockchain.TokenETH, amount)
 	if err != nil {
 		return err
 	}
@@ -57,7 +60,11 @@ func (wallet *wallet) verifyERC20Balance(password string, token blockchain.Token
 		return fmt.Errorf("Invalid balance amount: %s", ethBalance.Amount)
 	}
 
-	fee, err := blockchain.TokenETH.TransactionCost(amount)
+	value := amount
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	fee, err := wallet.FeeEstimator().TransactionCost(token, value)
 	if err != nil {
 		return err
 	}
@@ -91,21 +98,31 @@ func (wallet *wallet) verifyERC20Balance(password string, token blockchain.Token
 	return nil
 }
 
-func (wallet *wallet) verifyBitcoinBalance(password string, amount *big.Int) error {
+// verifyUTXOBalance verifies that the amount can be swapped. The amount pays
+// the fee of the swap transaction that spends it, and must leave more than
+// dust to its recipient. The balance must pay the amount and the swap fee of
+// the transaction that funds the swap.
+func (wallet *wallet) verifyUTXOBalance(password string, token blockchain.Token, amount *big.Int) error {
 	if amount == nil {
 		return nil
 	}
 
-	if amount.Cmp(big.NewInt(20000)) < 0 {
-		return fmt.Errorf("invalid bitcoin amount: minimum swappable bitcoin amount 20000 SAT (or 0.0002 BTC)")
+	chain, err := wallet.utxoChain(token.Blockchain)
+	if err != nil {
+		return err
 	}
 
-	fee, err := blockchain.TokenBTC.TransactionCost(amount)
+	fee, err := wallet.SwapTxFee(token)
 	if err != nil {
 		return err
 	}
 
-	balance, err := wallet.Balance(password, blockchain.TokenBTC)
+	minimum := new(big.Int).Add(fee, big.NewInt(chain.Dust))
+	if amount.Cmp(minimum) < 0 {
+		return fmt.Errorf("invalid %s amount: minimum swappable amount %v", token.Name, minimum)
+	}
+
+	balance, err := wallet.Balance(password, token)
 	if err != nil {
 		return err
 	}
@@ -116,8 +133,137 @@ func (wallet *wallet) verifyBitcoinBalance(password string, amount *big.Int) err
 	}
 
 	leftover := new(big.Int).Sub(balanceAmount, amount)
-	if leftover.Cmp(new(big.Int).Add(fee[blockchain.BTC], big.NewInt(600))) < 0 {
-		return fmt.Errorf("You need at least 10600 SAT (or 0.000106 BTC) remaining in your wallet to cover transaction fees. You have: %v", leftover)
+	if leftover.Cmp(fee) < 0 {
+		return fmt.Errorf("You need at least %v %s remaining in your wallet to cover transaction fees. You have: %v", fee, token.Name, leftover)
 	}
 	return nil
 }
+
+// SpendableAmount returns the amount that is transferred to the address when
+// the fee of the transfer is subtracted from the amount, and the cost of that
+// transfer. When the amount is nil, the whole balance of the first address is
+// spent. The fees of ERC20 tokens are paid in ether, so they are not subtracted
+// unless the token also charges a fee.
+func (wallet *wallet) SpendableAmount(password string, token blockchain.Token, to string, amount *big.Int) (*big.Int, blockchain.Cost, error) {
+	return wallet.SpendableAmountAt(password, token, 0, to, amount)
+}
+
+// SpendableAmountAt returns the spendable amount of the address index, like
+// SpendableAmount. Blockchains without address indices always spend from the
+// first address.
+func (wallet *wallet) SpendableAmountAt(password string, token blockchain.Token, index uint32, to string, amount *big.Int) (*big.Int, blockchain.Cost, error) {
+	switch token.Blockchain {
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.spendableUTXOAmount(password, token, index, to, amount)
+	case blockchain.Ethereum:
+		return wallet.spendableETHAmount(password, to, amount)
+	case blockchain.ERC20:
+		return wallet.spendableERC20Amount(password, token, amount)
+	default:
+		return nil, nil, blockchain.NewErrUnsupportedToken(token.Name)
+	}
+}
+
+// spendableUTXOAmount sizes the fee from the transaction that spends the
+// unspent outputs of the address index, which are the outputs that the
+// transfer spends.
+func (wallet *wallet) spendableUTXOAmount(password string, token blockchain.Token, index uint32, to string, amount *big.Int) (*big.Int, blockchain.Cost, error) {
+	account, err := wallet.UTXOAccountAt(password, token.Blockchain, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	feeRate, err := wallet.FeeEstimator().FeeRate(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := int64(0)
+	if amount != nil {
+		value = amount.Int64()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	spendable, fee, err := account.SubtractFee(ctx, to, value, feeRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return big.NewInt(spendable), blockchain.Cost{token.Name: big.NewInt(fee)}, nil
+}
+
+// spendableETHAmount estimates the gas of the transfer to the address, which
+// can be a contract.
+func (wallet *wallet) spendableETHAmount(password, to string, amount *big.Int) (*big.Int, blockchain.Cost, error) {
+	amount, err := wallet.requestedAmount(password, blockchain.TokenETH, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := wallet.EthereumAccount(password)
+	if err != nil {
+		return nil, nil, err
+	}
+	gasPrice, err := wallet.FeeEstimator().TxFee(blockchain.TokenETH)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	gas, err := estimateTransferGas(ctx, account, common.HexToAddress(to), amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+	spendable := new(big.Int).Sub(amount, fee)
+	if spendable.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("cannot transfer %v %s: it does not cover the transaction fees", amount, blockchain.ETH)
+	}
+	return spendable, blockchain.Cost{blockchain.ETH: fee}, nil
+}
+
+func (wallet *wallet) spendableERC20Amount(password string, token blockchain.Token, amount *big.Int) (*big.Int, blockchain.Cost, error) {
+	amount, err := wallet.requestedAmount(password, token, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	cost, err := wallet.FeeEstimator().TransactionCost(token, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	spendable := new(big.Int).Set(amount)
+	if fee, ok := cost[token.Name]; ok {
+		spendable.Sub(spendable, fee)
+	}
+	if spendable.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("cannot transfer %v %s: it does not cover the transaction fees", amount, token.Name)
+	}
+
+	// The fees of tokens that charge a share of the transferred amount are
+	// lower on the spendable amount.
+	if cost, err = wallet.FeeEstimator().TransactionCost(token, spendable); err != nil {
+		return nil, nil, err
+	}
+	if err := wallet.verifyERC20Balance(password, token, spendable); err != nil {
+		return nil, nil, err
+	}
+	return spendable, cost, nil
+}
+
+// requestedAmount returns the amount, or the balance of the first address when
+// the amount is nil. The amount cannot exceed the balance.
+func (wallet *wallet) requestedAmount(password string, token blockchain.Token, amount *big.Int) (*big.Int, error) {
+	balance, err := wallet.BalanceAt(password, token, 0)
+	if err != nil {
+		return nil, err
+	}
+	balanceAmount, ok := big.NewInt(0).SetString(balance.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("Invalid balance amount: %s", balance.Amount)
+	}
+	if amount == nil {
+		return balanceAmount, nil
+	}
+	if amount.Cmp(balanceAmount) > 0 {
+		return nil, fmt.Errorf("cannot transfer %v %s: the balance is %v", amount, token.Name, balanceAmount)
+	}
+	return amount, nil
+}
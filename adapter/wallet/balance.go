@@ -2,18 +2,17 @@ package wallet
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/renproject/swapperd/adapter/binder/erc20"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/republicprotocol/beth-go"
 	"github.com/republicprotocol/co-go"
-	"github.com/republicprotocol/libbtc-go"
 )
 
 func (wallet *wallet) Balances(password string) (map[blockchain.TokenName]blockchain.Balance, error) {
@@ -34,40 +33,78 @@ func (wallet *wallet) Balances(password string) (map[blockchain.TokenName]blockc
 }
 
 func (wallet *wallet) Balance(password string, token blockchain.Token) (blockchain.Balance, error) {
-	address, err := wallet.GetAddress(password, token.Blockchain)
+	return wallet.BalanceAt(password, token, 0)
+}
+
+// AggregateBalance returns the balance of the first address, which is the
+// balance that can be spent, with the sum of the balances of the other
+// addresses up to the last address index as the balance received by swaps.
+func (wallet *wallet) AggregateBalance(password string, token blockchain.Token, lastIndex uint32) (blockchain.Balance, error) {
+	if !token.Blockchain.HasAddressIndices() {
+		lastIndex = 0
+	}
+	balances := make([]blockchain.Balance, lastIndex+1)
+	errs := make([]error, lastIndex+1)
+	co.ParForAll(balances, func(i int) {
+		balances[i], errs[i] = wallet.BalanceAt(password, token, uint32(i))
+	})
+
+	received := big.NewInt(0)
+	for i, balance := range balances {
+		if errs[i] != nil {
+			return blockchain.Balance{}, errs[i]
+		}
+		if i == 0 {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(balance.Amount, 10)
+		if !ok {
+			return blockchain.Balance{}, fmt.Errorf("invalid %s balance: %s", token.Name, balance.Amount)
+		}
+		received.Add(received, amount)
+	}
+	balance := balances[0]
+	if token.Blockchain.HasAddressIndices() {
+		balance.Received = received.String()
+	}
+	return balance, nil
+}
+
+// BalanceAt returns the balance of the address index.
+func (wallet *wallet) BalanceAt(password string, token blockchain.Token, index uint32) (blockchain.Balance, error) {
+	address, err := wallet.GetAddressAt(password, token.Blockchain, index)
 	if err != nil {
 		return blockchain.Balance{}, err
 	}
 
 	switch token.Blockchain {
-	case blockchain.Bitcoin:
-		return wallet.balanceBTC(address)
 	case blockchain.Ethereum:
 		return wallet.balanceETH(address)
 	case blockchain.ERC20:
 		return wallet.balanceERC20(token, address)
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.balanceUTXO(token, address)
 	default:
 		return blockchain.Balance{}, blockchain.NewErrUnsupportedToken(token.Name)
 	}
 }
 
-func (wallet *wallet) balanceBTC(address string) (blockchain.Balance, error) {
-	randomKey, err := crypto.GenerateKey()
-	if err != nil {
-		return blockchain.Balance{}, err
-	}
-	btcAccount := libbtc.NewAccount(libbtc.NewBlockchainInfoClient(wallet.config.Bitcoin.Network.Name), randomKey)
+func (wallet *wallet) balanceUTXO(token blockchain.Token, address string) (blockchain.Balance, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
 	defer cancel()
 
-	balance, err := btcAccount.Balance(ctx, address, 0)
+	utxos, err := wallet.utxoClient(token.Blockchain).UnspentOutputs(ctx, address)
 	if err != nil {
 		return blockchain.Balance{}, err
 	}
+	balance := int64(0)
+	for _, utxo := range utxos {
+		balance += utxo.Amount
+	}
 
 	return blockchain.Balance{
 		Address:  address,
-		Decimals: blockchain.TokenBTC.Decimals,
+		Decimals: token.Decimals,
 		Amount:   big.NewInt(balance).String(),
 	}, nil
 }
@@ -98,7 +135,7 @@ func (wallet *wallet) balanceERC20(token blockchain.Token, address string) (bloc
 	if err != nil {
 		return blockchain.Balance{}, err
 	}
-	tokenAddr, err := client.ReadAddress(string(token.Name))
+	tokenAddr, err := wallet.readTokenAddress(client, token)
 	if err != nil {
 		return blockchain.Balance{}, err
 	}
@@ -127,3 +164,27 @@ func (wallet *wallet) balanceERC20(token blockchain.Token, address string) (bloc
 		Amount:   balance.String(),
 	}, nil
 }
+
+// GapLimit is the number of consecutive empty addresses after which the scan
+// of a restored wallet stops.
+const GapLimit = uint32(20)
+
+// LastUsedIndex returns the last address index of the token that holds funds.
+// The scan stops after gapLimit consecutive indices without funds, like the
+// restore of other BIP44 wallets.
+func (wallet *wallet) LastUsedIndex(password string, token blockchain.Token, gapLimit uint32) (uint32, error) {
+	if !token.Blockchain.HasAddressIndices() {
+		return 0, nil
+	}
+	last := uint32(0)
+	for index := uint32(1); index <= last+gapLimit; index++ {
+		balance, err := wallet.BalanceAt(password, token, index)
+		if err != nil {
+			return last, err
+		}
+		if balance.Amount != "" && balance.Amount != "0" {
+			last = index
+		}
+	}
+	return last, nil
+}
@@ -7,6 +7,16 @@ var Testnet = Config{
 			Name: "testnet",
 		},
 	},
+	Litecoin: BlockchainConfig{
+		Network: Network{
+			Name: "testnet",
+		},
+	},
+	BitcoinCash: BlockchainConfig{
+		Network: Network{
+			Name: "testnet",
+		},
+	},
 	Ethereum: BlockchainConfig{
 		Network: Network{
 			Name: "kovan",
@@ -22,6 +32,16 @@ var Mainnet = Config{
 			Name: "mainnet",
 		},
 	},
+	Litecoin: BlockchainConfig{
+		Network: Network{
+			Name: "mainnet",
+		},
+	},
+	BitcoinCash: BlockchainConfig{
+		Network: Network{
+			Name: "mainnet",
+		},
+	},
 	Ethereum: BlockchainConfig{
 		Network: Network{
 			Name: "mainnet",
@@ -0,0 +1,22 @@
+package wallet
+
+import "github.com/renproject/swapperd/foundation/blockchain"
+
+// Confirmations returns the number of confirmations that swap transactions on
+// the blockchain need before they are trusted. ERC20 tokens use the ethereum
+// config.
+func (wallet *wallet) Confirmations(name blockchain.BlockchainName) int64 {
+	config := BlockchainConfig{}
+	switch name {
+	case blockchain.Bitcoin:
+		config = wallet.config.Bitcoin
+	case blockchain.Ethereum, blockchain.ERC20:
+		config = wallet.config.Ethereum
+	case blockchain.Litecoin, blockchain.BitcoinCash:
+		config = wallet.utxoConfig(name)
+	}
+	if config.Confirmations <= 0 {
+		return name.DefaultConfirmations()
+	}
+	return config.Confirmations
+}
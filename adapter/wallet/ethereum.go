@@ -0,0 +1,174 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+// ErrPostConditionCheckFailed is returned when the post-condition of an
+// ethereum transaction does not hold before its context is done.
+var ErrPostConditionCheckFailed = fmt.Errorf("post-condition check failed")
+
+// EthereumTransactOpts returns the options of ethereum transactions that are
+// signed by the Signer of the wallet, without exporting its private key.
+func (wallet *wallet) EthereumTransactOpts(password string) (*bind.TransactOpts, error) {
+	if wallet.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	key, err := newKeySigner(wallet.signer, password, wallet.ethereumDerivationPath())
+	if err != nil {
+		return nil, err
+	}
+	return newTransactOpts(key), nil
+}
+
+func newTransactOpts(key *keySigner) *bind.TransactOpts {
+	from := crypto.PubkeyToAddress(key.PublicKey())
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, fmt.Errorf("cannot sign transactions of %s with the key of %s", address.Hex(), from.Hex())
+			}
+			sig, err := key.Sign(signer.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(signer, sig)
+		},
+	}
+}
+
+// ethereumSignerAccount returns the ethereum account of a Signer that does not
+// export its private keys.
+func (wallet *wallet) ethereumSignerAccount(password string) (beth.Account, error) {
+	key, err := newKeySigner(wallet.signer, password, wallet.ethereumDerivationPath())
+	if err != nil {
+		return nil, err
+	}
+	client, err := beth.Connect(wallet.config.Ethereum.Network.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &signerAccount{
+		Client:  client,
+		key:     key,
+		network: wallet.config.Ethereum.Network.Name,
+	}, nil
+}
+
+// A signerAccount is a beth.Account that signs its transactions with a
+// keySigner. Nonces, gas prices and gas limits are left to the node, unless
+// they are set by the caller of Transact.
+type signerAccount struct {
+	beth.Client
+	key     *keySigner
+	network string
+}
+
+func (account *signerAccount) Address() common.Address {
+	return crypto.PubkeyToAddress(account.key.PublicKey())
+}
+
+// Transfer sends the value to the address, and waits for the confirmations.
+func (account *signerAccount) Transfer(ctx context.Context, to common.Address, value *big.Int, confirmBlocks int64) (string, error) {
+	recipient := bind.NewBoundContract(to, abi.ABI{}, account.EthClient(), account.EthClient(), account.EthClient())
+	var txHash string
+	err := account.Transact(
+		ctx,
+		nil,
+		func(tops *bind.TransactOpts) (*types.Transaction, error) {
+			tops.Value = value
+			tx, err := recipient.Transfer(tops)
+			if err != nil {
+				return tx, err
+			}
+			txHash = tx.Hash().String()
+			return tx, nil
+		},
+		nil,
+		confirmBlocks,
+	)
+	return txHash, err
+}
+
+// Transact calls f until the post-condition holds, like the accounts of beth.
+// f is called again if it failed, or if its last transaction was mined without
+// meeting the post-condition.
+func (account *signerAccount) Transact(ctx context.Context, preCondition func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postCondition func() bool, confirmBlocks int64) error {
+	if preCondition != nil && !preCondition() {
+		return beth.ErrPreConditionCheckFailed
+	}
+
+	var tx *types.Transaction
+	for {
+		if tx == nil || account.mined(ctx, tx) {
+			tops := newTransactOpts(account.key)
+			tops.Context = ctx
+			next, err := f(tops)
+			if err != nil && postCondition == nil {
+				return err
+			}
+			if err == nil {
+				tx = next
+			}
+		}
+		if tx != nil && (postCondition == nil || postCondition()) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ErrPostConditionCheckFailed
+		case <-time.After(time.Second):
+		}
+	}
+	return account.waitForConfirmations(ctx, tx, confirmBlocks)
+}
+
+func (account *signerAccount) FormatTransactionView(msg, txHash string) (string, error) {
+	switch account.network {
+	case "kovan", "ropsten":
+		return fmt.Sprintf("%s, transaction can be viewed at https://%s.etherscan.io/tx/%s", msg, account.network, txHash), nil
+	case "mainnet":
+		return fmt.Sprintf("%s, transaction can be viewed at https://etherscan.io/tx/%s", msg, txHash), nil
+	default:
+		return "", fmt.Errorf("unknown ethereum network: %s", account.network)
+	}
+}
+
+// mined returns true if the transaction is in a block.
+func (account *signerAccount) mined(ctx context.Context, tx *types.Transaction) bool {
+	receipt, err := account.EthClient().TransactionReceipt(ctx, tx.Hash())
+	return err == nil && receipt != nil
+}
+
+// waitForConfirmations waits until the transaction has been mined, and is
+// followed by the number of blocks.
+func (account *signerAccount) waitForConfirmations(ctx context.Context, tx *types.Transaction, confirmBlocks int64) error {
+	if confirmBlocks <= 0 {
+		return nil
+	}
+	for {
+		txBlock, err := account.TxBlockNumber(ctx, tx.Hash().String())
+		if err == nil {
+			currentBlock, err := account.CurrentBlockNumber(ctx)
+			if err == nil && new(big.Int).Sub(currentBlock, txBlock).Int64() >= confirmBlocks {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
+	"github.com/renproject/swapperd/core/wallet/transfer"
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/republicprotocol/beth-go"
+)
+
+// BitcoinFeeTarget is the number of blocks that bitcoin transactions are
+// expected to confirm within.
+const BitcoinFeeTarget = 3
+
+func (wallet *wallet) FeeEstimator() blockchain.FeeEstimator {
+	return wallet.feeEstimator
+}
+
+func newFeeEstimator(config Config) blockchain.FeeEstimator {
+	feeRate := blockchain.NewEsploraFeeRateSource(bitcoinURL(config), BitcoinFeeTarget)
+	return blockchain.NewFeeEstimator(&gasPriceSource{config.Ethereum.Network.URL}, feeRate)
+}
+
+// gasPriceSource reads the gas price suggested by the ethereum node. It
+// connects on every call, so that the wallet can be created while the node is
+// unreachable.
+type gasPriceSource struct {
+	url string
+}
+
+func (source *gasPriceSource) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	client, err := beth.Connect(source.url)
+	if err != nil {
+		return nil, err
+	}
+	return client.EthClient().SuggestGasPrice(ctx)
+}
+
+// SwapTxFee returns the fee of the swap transactions of the token. It is the
+// gas price (in wei) for ethereum and erc20 tokens. Bitcoin-like blockchains
+// pay the fee rate on the size of the largest swap transaction.
+func (wallet *wallet) SwapTxFee(token blockchain.Token) (*big.Int, error) {
+	switch token.Blockchain {
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		feeRate, err := wallet.FeeEstimator().FeeRate(token)
+		if err != nil {
+			return nil, err
+		}
+		size, err := utxo.SwapTxSize()
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(feeRate * size), nil
+	default:
+		return wallet.FeeEstimator().TxFee(token)
+	}
+}
+
+// TransferCost returns the cost of paying the recipients from the first
+// address. Bitcoin-like blockchains size the fee from the transaction that
+// pays the recipients from the unspent outputs of the address.
+func (wallet *wallet) TransferCost(password string, token blockchain.Token, recipients []transfer.Recipient) (blockchain.Cost, error) {
+	switch token.Blockchain {
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		account, err := wallet.UTXOAccount(password, token.Blockchain)
+		if err != nil {
+			return nil, err
+		}
+		fee, err := wallet.utxoTransferFee(account, token, recipients)
+		if err != nil {
+			return nil, err
+		}
+		return blockchain.Cost{token.Name: big.NewInt(fee)}, nil
+	case blockchain.Ethereum:
+		return wallet.ethereumTransferCost(password, recipients)
+	default:
+		amounts := make([]*big.Int, len(recipients))
+		for i, recipient := range recipients {
+			amounts[i] = recipient.Amount
+		}
+		return wallet.FeeEstimator().BatchTransactionCost(token, amounts)
+	}
+}
+
+// ethereumTransferCost estimates the gas of each transfer, because recipients
+// that are contracts can use more gas than a plain transfer.
+func (wallet *wallet) ethereumTransferCost(password string, recipients []transfer.Recipient) (blockchain.Cost, error) {
+	account, err := wallet.EthereumAccount(password)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := wallet.FeeEstimator().TxFee(blockchain.TokenETH)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	gas := uint64(0)
+	for _, recipient := range recipients {
+		recipientGas, err := estimateTransferGas(ctx, account, common.HexToAddress(recipient.To), recipient.Amount)
+		if err != nil {
+			return nil, err
+		}
+		gas += recipientGas
+	}
+	return blockchain.Cost{blockchain.ETH: new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))}, nil
+}
+
+// utxoTransferFee returns the fee, at the current fee rate, of the transaction
+// that pays the recipients from the account.
+func (wallet *wallet) utxoTransferFee(account utxo.Account, token blockchain.Token, recipients []transfer.Recipient) (int64, error) {
+	feeRate, err := wallet.FeeEstimator().FeeRate(token)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return account.TransferFee(ctx, utxoOutputs(recipients), feeRate)
+}
+
+func utxoOutputs(recipients []transfer.Recipient) []utxo.Output {
+	outputs := make([]utxo.Output, len(recipients))
+	for i, recipient := range recipients {
+		outputs[i] = utxo.Output{To: recipient.To, Value: recipient.Amount.Int64()}
+	}
+	return outputs
+}
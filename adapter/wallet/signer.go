@@ -3,34 +3,174 @@ package wallet
 import (
 	"crypto/ecdsa"
 	"encoding/base64"
+	"fmt"
+	"math/big"
 	"strings"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
 )
 
+// IdentityPath is the derivation path of the identity key of a wallet, which
+// signs its quotes, webhooks and messages.
+var IdentityPath = []uint32{0}
+
+// ErrKeyNotExportable is returned when an account needs the private key of a
+// signer that does not export its keys.
+var ErrKeyNotExportable = fmt.Errorf("signer does not export private keys")
+
+// A Signer holds the keys of the wallets, and signs hashes with the key of a
+// derivation path. Signatures are in the 65 byte [R || S || V] format, with a
+// recovery id of 0 or 1.
+type Signer interface {
+	PublicKey(password string, path []uint32) (ecdsa.PublicKey, error)
+	Sign(password string, path []uint32, hash []byte) ([]byte, error)
+}
+
+// A KeyExporter is a Signer that can export its private keys. The ethereum
+// accounts of a KeyExporter sign with the private key, and the accounts of
+// other signers ask the Signer to sign every transaction.
+type KeyExporter interface {
+	Signer
+
+	PrivateKey(password string, path []uint32) (*ecdsa.PrivateKey, error)
+}
+
+// NewMnemonicSigner returns the in-memory Signer of the keys derived from the
+// mnemonic, with the password as the BIP39 passphrase.
+func NewMnemonicSigner(mnemonic string) KeyExporter {
+	return &mnemonicSigner{mnemonic}
+}
+
+type mnemonicSigner struct {
+	mnemonic string
+}
+
+func (signer *mnemonicSigner) PublicKey(password string, path []uint32) (ecdsa.PublicKey, error) {
+	privKey, err := signer.PrivateKey(password, path)
+	if err != nil {
+		return ecdsa.PublicKey{}, err
+	}
+	return privKey.PublicKey, nil
+}
+
+func (signer *mnemonicSigner) Sign(password string, path []uint32, hash []byte) ([]byte, error) {
+	privKey, err := signer.PrivateKey(password, path)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash, privKey)
+}
+
+func (signer *mnemonicSigner) PrivateKey(password string, path []uint32) (*ecdsa.PrivateKey, error) {
+	key, err := signer.extendedKey(password, path)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(key.Key)
+}
+
+func (signer *mnemonicSigner) extendedKey(password string, path []uint32) (*bip32.Key, error) {
+	seed := bip39.NewSeed(signer.mnemonic, password)
+	key, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, val := range path {
+		key, err = key.NewChildKey(val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
 type ECDSASigner interface {
 	PublicKey() ecdsa.PublicKey
 	Sign(hash []byte) ([]byte, error)
 }
 
-type ecdsaSigner struct {
-	privateKey *ecdsa.PrivateKey
+// A keySigner signs with the key of one derivation path of a Signer.
+type keySigner struct {
+	signer   Signer
+	password string
+	path     []uint32
+	pubKey   ecdsa.PublicKey
+}
+
+func newKeySigner(signer Signer, password string, path []uint32) (*keySigner, error) {
+	pubKey, err := signer.PublicKey(password, path)
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{signer, password, path, pubKey}, nil
+}
+
+func (signer *keySigner) Sign(hash []byte) ([]byte, error) {
+	return signer.signer.Sign(signer.password, signer.path, hash)
+}
+
+func (signer *keySigner) PublicKey() ecdsa.PublicKey {
+	return signer.pubKey
+}
+
+// A btcSigner signs bitcoin transactions with the key of a keySigner. It has
+// the signing methods of a btcec.PrivateKey.
+type btcSigner struct {
+	key *keySigner
 }
 
-func (signer *ecdsaSigner) Sign(hash []byte) ([]byte, error) {
-	return crypto.Sign(hash, signer.privateKey)
+func (signer btcSigner) PubKey() *btcec.PublicKey {
+	return (*btcec.PublicKey)(&signer.key.pubKey)
 }
 
-func (signer *ecdsaSigner) PublicKey() ecdsa.PublicKey {
-	return signer.privateKey.PublicKey
+// Sign returns the signature of the hash, which is serialized as DER in bitcoin
+// transactions.
+func (signer btcSigner) Sign(hash []byte) (*btcec.Signature, error) {
+	sig, err := signer.key.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	return &btcec.Signature{
+		R: new(big.Int).SetBytes(sig[:32]),
+		S: new(big.Int).SetBytes(sig[32:64]),
+	}, nil
+}
+
+// privateKey returns the private key of the path, if the signer of the wallet
+// exports its keys.
+func (wallet *wallet) privateKey(password string, path []uint32) (*ecdsa.PrivateKey, error) {
+	if wallet.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	exporter, ok := wallet.signer.(KeyExporter)
+	if !ok {
+		return nil, ErrKeyNotExportable
+	}
+	return exporter.PrivateKey(password, path)
+}
+
+func (wallet *wallet) ECDSASigner(password string) (ECDSASigner, error) {
+	if wallet.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	signer, err := newKeySigner(wallet.signer, password, IdentityPath)
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
 }
 
 func (wallet *wallet) ID(password, idType string) (string, error) {
-	signer, err := wallet.ECDSASigner(password)
+	pubKey, err := wallet.signer.PublicKey(password, IdentityPath)
 	if err != nil {
 		return "", err
 	}
-	pubKey := signer.PublicKey()
 	idType = strings.ToLower(idType)
 	switch idType {
 	case "ethereum", "eth":
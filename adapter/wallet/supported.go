@@ -1,7 +1,40 @@
 package wallet
 
-import "github.com/renproject/swapperd/foundation/blockchain"
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/republicprotocol/beth-go"
+)
 
 func (wallet *wallet) SupportedTokens() []blockchain.Token {
-	return blockchain.SupportedTokens
+	return blockchain.Tokens()
+}
+
+// ERC20Addresses returns the addresses of the token contract and the swap
+// contract of the ERC20 token. Addresses in the token registry take precedence
+// over the addresses known to beth.
+func (wallet *wallet) ERC20Addresses(client beth.Client, token blockchain.Token) (common.Address, common.Address, error) {
+	tokenAddress, err := wallet.readTokenAddress(client, token)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+
+	contracts, ok := blockchain.TokenContractAddresses(token.Name, wallet.config.Ethereum.Network.Name)
+	if ok && contracts.Swapper != "" {
+		return tokenAddress, common.HexToAddress(contracts.Swapper), nil
+	}
+	swapperAddress, err := client.ReadAddress(fmt.Sprintf("%sSwapContract", token.Name))
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	return tokenAddress, swapperAddress, nil
+}
+
+func (wallet *wallet) readTokenAddress(client beth.Client, token blockchain.Token) (common.Address, error) {
+	if contracts, ok := blockchain.TokenContractAddresses(token.Name, wallet.config.Ethereum.Network.Name); ok {
+		return common.HexToAddress(contracts.Token), nil
+	}
+	return client.ReadAddress(string(token.Name))
 }
@@ -5,37 +5,181 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/renproject/swapperd/adapter/binder/erc20"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
 	"github.com/renproject/swapperd/core/wallet/transfer"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/republicprotocol/beth-go"
-	"github.com/republicprotocol/libbtc-go"
 )
 
 func (wallet *wallet) Transfer(password string, token blockchain.Token, to string, amount *big.Int) (string, error) {
+	return wallet.TransferAt(password, token, 0, to, amount)
+}
+
+// TransferAt transfers the amount from the address index. Blockchains without
+// address indices always transfer from the first address.
+func (wallet *wallet) TransferAt(password string, token blockchain.Token, index uint32, to string, amount *big.Int) (string, error) {
+	if wallet.WatchOnly() {
+		return "", ErrWatchOnly
+	}
 	switch token.Blockchain {
-	case blockchain.Bitcoin:
-		return wallet.transferBTC(password, to, amount)
 	case blockchain.Ethereum:
 		return wallet.transferETH(password, to, amount)
 	case blockchain.ERC20:
 		return wallet.transferERC20(password, token, to, amount)
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.transferUTXO(password, token, index, to, amount)
 	default:
 		return "", blockchain.NewErrUnsupportedToken(token.Name)
 	}
 }
 
-func (wallet *wallet) transferBTC(password, to string, amount *big.Int) (string, error) {
+// TransferWithCost transfers the amount from the first address, and pays
+// exactly the fee of the cost. It is used when the fee has been subtracted from
+// the amount, so that the transfer spends no more than the amount and its fee.
+// ERC20 transfers pay their fee in ether, and use the current gas price.
+func (wallet *wallet) TransferWithCost(password string, token blockchain.Token, to string, amount *big.Int, cost blockchain.Cost) (string, error) {
+	return wallet.TransferWithCostAt(password, token, 0, to, amount, cost)
+}
+
+// TransferWithCostAt transfers the amount from the address index, and pays
+// exactly the fee of the cost, like TransferWithCost. Blockchains without
+// address indices always transfer from the first address.
+func (wallet *wallet) TransferWithCostAt(password string, token blockchain.Token, index uint32, to string, amount *big.Int, cost blockchain.Cost) (string, error) {
+	if wallet.WatchOnly() {
+		return "", ErrWatchOnly
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	switch token.Blockchain {
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		account, err := wallet.UTXOAccountAt(password, token.Blockchain, index)
+		if err != nil {
+			return "", err
+		}
+		return account.Transfer(ctx, to, amount.Int64(), cost[token.Name].Int64())
+	case blockchain.Ethereum:
+		return wallet.transferETHWithFee(ctx, password, to, amount, cost[blockchain.ETH])
+	case blockchain.ERC20:
+		return wallet.transferERC20(password, token, to, amount)
+	default:
+		return "", blockchain.NewErrUnsupportedToken(token.Name)
+	}
+}
+
+// transferETHWithFee transfers ether with the gas price that makes the fee of
+// the transfer equal to the fee, on the estimated gas of the transfer.
+func (wallet *wallet) transferETHWithFee(ctx context.Context, password, to string, amount, fee *big.Int) (string, error) {
+	account, err := wallet.EthereumAccount(password)
+	if err != nil {
+		return "", err
+	}
+	gas, err := estimateTransferGas(ctx, account, common.HexToAddress(to), amount)
+	if err != nil {
+		return "", err
+	}
+	gasPrice := new(big.Int).Div(fee, new(big.Int).SetUint64(gas))
+	recipient := bind.NewBoundContract(common.HexToAddress(to), abi.ABI{}, account.EthClient(), account.EthClient(), account.EthClient())
+
+	var txHash string
+	if err := account.Transact(
+		ctx,
+		nil,
+		func(tops *bind.TransactOpts) (*types.Transaction, error) {
+			tops.Value = amount
+			tops.GasPrice = gasPrice
+			tops.GasLimit = gas
+			tx, err := recipient.Transfer(tops)
+			if err != nil {
+				return tx, err
+			}
+			txHash = tx.Hash().String()
+			return tx, nil
+		},
+		nil,
+		1,
+	); err != nil {
+		return txHash, err
+	}
+	return txHash, nil
+}
+
+// estimateTransferGas returns the gas of transferring the value from the
+// account to the address. Contracts can use more gas than a plain transfer to
+// receive ether.
+func estimateTransferGas(ctx context.Context, account beth.Account, to common.Address, value *big.Int) (uint64, error) {
+	return account.EthClient().EstimateGas(ctx, ethereum.CallMsg{
+		From:  account.Address(),
+		To:    &to,
+		Value: value,
+	})
+}
+
+// BatchTransfer pays the recipients from the first address. Bitcoin-like
+// blockchains pay every recipient in one transaction, and ethereum pays each
+// recipient in its own transaction, in order.
+func (wallet *wallet) BatchTransfer(password string, token blockchain.Token, recipients []transfer.Recipient) ([]string, error) {
+	if wallet.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	switch token.Blockchain {
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		txHash, err := wallet.batchTransferUTXO(password, token, recipients)
+		if err != nil {
+			return nil, err
+		}
+		txHashes := make([]string, len(recipients))
+		for i := range txHashes {
+			txHashes[i] = txHash
+		}
+		return txHashes, nil
+	case blockchain.Ethereum, blockchain.ERC20:
+		txHashes := []string{}
+		for _, recipient := range recipients {
+			txHash, err := wallet.TransferAt(password, token, 0, recipient.To, recipient.Amount)
+			if err != nil {
+				return txHashes, err
+			}
+			txHashes = append(txHashes, txHash)
+		}
+		return txHashes, nil
+	default:
+		return nil, blockchain.NewErrUnsupportedToken(token.Name)
+	}
+}
+
+func (wallet *wallet) batchTransferUTXO(password string, token blockchain.Token, recipients []transfer.Recipient) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
-	account, err := wallet.BitcoinAccount(password)
+	account, err := wallet.UTXOAccount(password, token.Blockchain)
+	if err != nil {
+		return "", err
+	}
+	fee, err := wallet.utxoTransferFee(account, token, recipients)
 	if err != nil {
 		return "", err
 	}
-	return account.Transfer(ctx, to, amount.Int64())
+	return account.TransferMany(ctx, utxoOutputs(recipients), fee)
+}
+
+func (wallet *wallet) transferUTXO(password string, token blockchain.Token, index uint32, to string, amount *big.Int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	account, err := wallet.UTXOAccountAt(password, token.Blockchain, index)
+	if err != nil {
+		return "", err
+	}
+	fee, err := wallet.utxoTransferFee(account, token, []transfer.Recipient{{To: to, Amount: amount}})
+	if err != nil {
+		return "", err
+	}
+	return account.Transfer(ctx, to, amount.Int64(), fee)
 }
 
 func (wallet *wallet) transferETH(password, to string, amount *big.Int) (string, error) {
@@ -56,7 +200,7 @@ func (wallet *wallet) transferERC20(password string, token blockchain.Token, to
 	if err != nil {
 		return txHash, err
 	}
-	tokenAddress, err := account.ReadAddress(string(token.Name))
+	tokenAddress, err := wallet.readTokenAddress(account, token)
 	if err != nil {
 		return txHash, err
 	}
@@ -86,18 +230,29 @@ func (wallet *wallet) transferERC20(password string, token blockchain.Token, to
 	return txHash, nil
 }
 
+// Lookup returns the update of the confirmations of the transfer, or
+// transfer.ErrTxNotFound if the blockchain does not know the transaction.
 func (wallet *wallet) Lookup(token blockchain.Token, txHash string) (transfer.UpdateReceipt, error) {
 	switch token.Blockchain {
-	case blockchain.Bitcoin:
-		return wallet.bitcoinLookup(txHash)
-	case blockchain.Ethereum:
-		return wallet.ethereumLookup(txHash)
+	case blockchain.Ethereum, blockchain.ERC20:
+		return wallet.ethereumLookup(token, txHash)
+	case blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash:
+		return wallet.utxoLookup(token, txHash)
 	default:
 		return transfer.UpdateReceipt{}, blockchain.NewErrUnsupportedBlockchain(token.Blockchain)
 	}
 }
 
-func (wallet *wallet) ethereumLookup(txHash string) (transfer.UpdateReceipt, error) {
+// erc20TransferEvent is the topic of the Transfer(from, to, value) event of an
+// ERC20 token.
+var erc20TransferEvent = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// ethereumLookup reads the receipt of an ethereum or ERC20 transfer. A transfer
+// is reverted if its transaction failed, or if an ERC20 transfer did not emit
+// a Transfer event to the recipient. The cost of the transfer is updated to the
+// gas that it actually spent. Batch transfers are looked up by their last
+// transaction, and keep their estimated cost.
+func (wallet *wallet) ethereumLookup(token blockchain.Token, txHash string) (transfer.UpdateReceipt, error) {
 	client, err := beth.Connect(wallet.config.Ethereum.Network.URL)
 	if err != nil {
 		return transfer.UpdateReceipt{}, err
@@ -107,6 +262,9 @@ func (wallet *wallet) ethereumLookup(txHash string) (transfer.UpdateReceipt, err
 	defer cancel()
 
 	txBlockNumber, err := client.TxBlockNumber(ctx, txHash)
+	if err == ethereum.NotFound {
+		return transfer.UpdateReceipt{}, transfer.ErrTxNotFound
+	}
 	if err != nil {
 		return transfer.UpdateReceipt{}, err
 	}
@@ -116,19 +274,62 @@ func (wallet *wallet) ethereumLookup(txHash string) (transfer.UpdateReceipt, err
 		return transfer.UpdateReceipt{}, err
 	}
 
+	txReceipt, err := client.EthClient().TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return transfer.UpdateReceipt{}, err
+	}
+	tx, _, err := client.EthClient().TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return transfer.UpdateReceipt{}, err
+	}
+
+	var tokenAddress common.Address
+	if token.Blockchain == blockchain.ERC20 {
+		if tokenAddress, err = wallet.readTokenAddress(client, token); err != nil {
+			return transfer.UpdateReceipt{}, err
+		}
+	}
+
 	confirmations := new(big.Int).Sub(currBlockNumber, txBlockNumber)
+	txCost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(txReceipt.GasUsed))
 	return transfer.NewUpdateReceipt(txHash, func(receipt *transfer.TransferReceipt) {
+		to := receipt.To
+		if n := len(receipt.Recipients); n > 0 {
+			to = receipt.Recipients[n-1].To
+		} else {
+			receipt.GasUsed = txReceipt.GasUsed
+			receipt.TxCost = blockchain.CostBlob{blockchain.ETH: txCost.String()}
+		}
 		receipt.Confirmations = confirmations.Int64()
+		receipt.Reverted = txReceipt.Status == types.ReceiptStatusFailed
+		if token.Blockchain == blockchain.ERC20 && !hasTransferEvent(txReceipt.Logs, tokenAddress, common.HexToAddress(to)) {
+			receipt.Reverted = true
+		}
 	}), nil
 }
 
-func (wallet *wallet) bitcoinLookup(txHash string) (transfer.UpdateReceipt, error) {
-	client := libbtc.NewBlockchainInfoClient(wallet.config.Bitcoin.Network.Name)
+// hasTransferEvent returns true if the logs have a Transfer event of the token
+// to the address.
+func hasTransferEvent(logs []*types.Log, token, to common.Address) bool {
+	for _, log := range logs {
+		if log.Address != token || len(log.Topics) != 3 || log.Topics[0] != erc20TransferEvent {
+			continue
+		}
+		if common.BytesToAddress(log.Topics[2].Bytes()) == to {
+			return true
+		}
+	}
+	return false
+}
 
+func (wallet *wallet) utxoLookup(token blockchain.Token, txHash string) (transfer.UpdateReceipt, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	confirmations, err := client.Confirmations(ctx, txHash)
+	confirmations, err := wallet.utxoClient(token.Blockchain).Confirmations(ctx, txHash)
+	if err == utxo.ErrTxNotFound {
+		return transfer.UpdateReceipt{}, transfer.ErrTxNotFound
+	}
 	if err != nil {
 		return transfer.UpdateReceipt{}, err
 	}
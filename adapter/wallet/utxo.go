@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"github.com/renproject/swapperd/adapter/binder/utxo"
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+// UTXOAccount returns the account of the password on a bitcoin-like UTXO
+// blockchain, such as bitcoin, litecoin or bitcoin cash.
+func (wallet *wallet) UTXOAccount(password string, name blockchain.BlockchainName) (utxo.Account, error) {
+	return wallet.UTXOAccountAt(password, name, 0)
+}
+
+// UTXOAccountAt returns the account of the address index on a bitcoin-like
+// UTXO blockchain.
+func (wallet *wallet) UTXOAccountAt(password string, name blockchain.BlockchainName, index uint32) (utxo.Account, error) {
+	chain, err := wallet.utxoChain(name)
+	if err != nil {
+		return nil, err
+	}
+	path := derivationPath(chain.CoinType, wallet.utxoConfig(name).Account, index)
+	if name == blockchain.Bitcoin {
+		path = wallet.bitcoinDerivationPath(index)
+	}
+	key, err := newKeySigner(wallet.signer, password, path)
+	if err != nil {
+		return nil, err
+	}
+	return utxo.NewSignerAccount(chain, wallet.utxoClient(name), btcSigner{key})
+}
+
+func (wallet *wallet) utxoChain(name blockchain.BlockchainName) (utxo.Chain, error) {
+	if name == blockchain.Bitcoin {
+		return utxo.Bitcoin(wallet.config.Bitcoin.Network.Name), nil
+	}
+	return utxo.NewChain(name, wallet.utxoConfig(name).Network.Name)
+}
+
+// utxoClient returns the client of the UTXO blockchain. Bitcoin uses the
+// Esplora API of its network, and other blockchains use the Insight API.
+func (wallet *wallet) utxoClient(name blockchain.BlockchainName) utxo.Client {
+	if name == blockchain.Bitcoin {
+		return wallet.bitcoinClient()
+	}
+	network := wallet.utxoConfig(name).Network
+	if network.URL == "" {
+		return utxo.NewInsightClient(utxo.DefaultInsightURL(name, network.Name))
+	}
+	return utxo.NewInsightClient(network.URL)
+}
+
+// utxoConfig returns the config of the UTXO blockchain. The blockchain uses the
+// bitcoin network when its network is not configured.
+func (wallet *wallet) utxoConfig(name blockchain.BlockchainName) BlockchainConfig {
+	config := BlockchainConfig{}
+	switch name {
+	case blockchain.Bitcoin:
+		return wallet.config.Bitcoin
+	case blockchain.Litecoin:
+		config = wallet.config.Litecoin
+	case blockchain.BitcoinCash:
+		config = wallet.config.BitcoinCash
+	}
+	if config.Network.Name == "" {
+		config.Network.Name = wallet.config.Bitcoin.Network.Name
+	}
+	return config
+}
+
+// bitcoinClient returns the client of the configured Esplora API, that is used
+// for every bitcoin balance, transfer and swap.
+func (wallet *wallet) bitcoinClient() utxo.WitnessClient {
+	return utxo.NewEsploraClient(bitcoinURL(wallet.config))
+}
+
+// bitcoinURL returns the url of the Esplora API of the bitcoin network, or the
+// public API when it is not configured.
+func bitcoinURL(config Config) string {
+	if config.Bitcoin.Network.URL == "" {
+		return utxo.DefaultEsploraURL(config.Bitcoin.Network.Name)
+	}
+	return config.Bitcoin.Network.URL
+}
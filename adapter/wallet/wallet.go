@@ -3,20 +3,28 @@ package wallet
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
 	"github.com/renproject/swapperd/core/wallet/transfer"
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/republicprotocol/beth-go"
-	"github.com/republicprotocol/libbtc-go"
 )
 
 type Config struct {
-	Mnemonic string           `json:"mnemonic"`
-	Ethereum BlockchainConfig `json:"ethereum"`
-	Bitcoin  BlockchainConfig `json:"bitcoin"`
+	Mnemonic           string            `json:"mnemonic,omitempty"`
+	Signer             string            `json:"signer,omitempty"` // unix socket of a remote signer
+	ExtendedPublicKeys map[string]string `json:"xpubs,omitempty"`  // of a watch-only wallet, by derivation path
+	Ethereum           BlockchainConfig  `json:"ethereum"`
+	Bitcoin            BlockchainConfig  `json:"bitcoin"`
+	Litecoin           BlockchainConfig  `json:"litecoin"`
+	BitcoinCash        BlockchainConfig  `json:"bitcoincash"`
 }
 
 type BlockchainConfig struct {
-	Network Network `json:"network"`
+	Network       Network `json:"network"`
+	Confirmations int64   `json:"confirmations,omitempty"`
+	Account       uint32  `json:"account,omitempty"`
 }
 
 type Network struct {
@@ -36,22 +44,56 @@ type Wallet interface {
 	Balance(password string, token blockchain.Token) (blockchain.Balance, error)
 	Lookup(token blockchain.Token, txHash string) (transfer.UpdateReceipt, error)
 	Transfer(password string, token blockchain.Token, to string, amount *big.Int) (string, error)
+	TransferAt(password string, token blockchain.Token, index uint32, to string, amount *big.Int) (string, error)
+	TransferWithCost(password string, token blockchain.Token, to string, amount *big.Int, cost blockchain.Cost) (string, error)
+	TransferWithCostAt(password string, token blockchain.Token, index uint32, to string, amount *big.Int, cost blockchain.Cost) (string, error)
+	BatchTransfer(password string, token blockchain.Token, recipients []transfer.Recipient) ([]string, error)
 	GetAddress(password string, blockchainName blockchain.BlockchainName) (string, error)
+	GetAddressAt(password string, blockchainName blockchain.BlockchainName, index uint32) (string, error)
+	GetWitnessAddress(password string) (string, error)
+	BalanceAt(password string, token blockchain.Token, index uint32) (blockchain.Balance, error)
+	AggregateBalance(password string, token blockchain.Token, lastIndex uint32) (blockchain.Balance, error)
+	LastUsedIndex(password string, token blockchain.Token, gapLimit uint32) (uint32, error)
 	Addresses(password string) (map[blockchain.TokenName]string, error)
 	VerifyAddress(blockchain blockchain.BlockchainName, address string) error
 	VerifyBalance(password string, token blockchain.Token, balance *big.Int) error
+	SpendableAmount(password string, token blockchain.Token, to string, amount *big.Int) (*big.Int, blockchain.Cost, error)
+	SpendableAmountAt(password string, token blockchain.Token, index uint32, to string, amount *big.Int) (*big.Int, blockchain.Cost, error)
+	FeeEstimator() blockchain.FeeEstimator
+	SwapTxFee(token blockchain.Token) (*big.Int, error)
+	TransferCost(password string, token blockchain.Token, recipients []transfer.Recipient) (blockchain.Cost, error)
+	Confirmations(blockchain blockchain.BlockchainName) int64
 
 	EthereumAccount(password string) (beth.Account, error)
-	BitcoinAccount(password string) (libbtc.Account, error)
+	EthereumTransactOpts(password string) (*bind.TransactOpts, error)
+	BitcoinAccount(password string) (utxo.Account, error)
+	BitcoinAccountAt(password string, index uint32) (utxo.Account, error)
+	BitcoinWitnessAccount(password string) (WitnessAccount, error)
+	BitcoinWitnessAccountAt(password string, index uint32) (WitnessAccount, error)
+	UTXOAccount(password string, blockchain blockchain.BlockchainName) (utxo.Account, error)
+	UTXOAccountAt(password string, blockchain blockchain.BlockchainName, index uint32) (utxo.Account, error)
 	ECDSASigner(password string) (ECDSASigner, error)
+	WatchOnly() bool
+	ERC20Addresses(client beth.Client, token blockchain.Token) (common.Address, common.Address, error)
 }
 
 type wallet struct {
-	config Config
+	config       Config
+	signer       Signer
+	feeEstimator blockchain.FeeEstimator
 }
 
+// New returns the wallet of the keys derived from the mnemonic of the config.
 func New(config Config) Wallet {
+	return NewWithSigner(config, NewMnemonicSigner(config.Mnemonic))
+}
+
+// NewWithSigner returns a wallet that signs with the keys of the signer. The
+// mnemonic of the config is not used.
+func NewWithSigner(config Config, signer Signer) Wallet {
 	return &wallet{
-		config: config,
+		config:       config,
+		signer:       signer,
+		feeEstimator: newFeeEstimator(config),
 	}
 }
@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/tyler-smith/go-bip32"
+)
+
+// ErrWatchOnly is returned when a watch-only wallet is asked to sign.
+var ErrWatchOnly = fmt.Errorf("wallet is watch-only")
+
+// NewWatchOnly returns a wallet that derives its addresses from the extended
+// public keys of the config. It can look up addresses, balances and
+// transfers, but cannot sign.
+func NewWatchOnly(config Config) (Wallet, error) {
+	signer, err := newWatchOnlySigner(config.ExtendedPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithSigner(config, signer), nil
+}
+
+// WatchOnlyConfig returns the watch-only config of the wallet of the password.
+// The mnemonic is replaced by the extended public keys of the master key, and
+// of the hardened BIP44 accounts of the config.
+func WatchOnlyConfig(config Config, password string) (Config, error) {
+	signer := &mnemonicSigner{config.Mnemonic}
+	paths := [][]uint32{{}}
+	for _, path := range (&wallet{config: config}).accountPaths() {
+		if path[2] >= bip32.FirstHardenedChild {
+			paths = append(paths, path)
+		}
+	}
+
+	xpubs := map[string]string{}
+	for _, path := range paths {
+		key, err := signer.extendedKey(password, path)
+		if err != nil {
+			return Config{}, err
+		}
+		xpubs[formatPath(path)] = key.PublicKey().B58Serialize()
+	}
+	config.Mnemonic = ""
+	config.Signer = ""
+	config.ExtendedPublicKeys = xpubs
+	return config, nil
+}
+
+// WatchOnly returns true if the wallet cannot sign.
+func (wallet *wallet) WatchOnly() bool {
+	_, ok := wallet.signer.(*watchOnlySigner)
+	return ok
+}
+
+// accountPaths returns the paths of the BIP44 accounts of the wallet.
+func (wallet *wallet) accountPaths() [][]uint32 {
+	paths := [][]uint32{
+		wallet.ethereumDerivationPath(),
+		wallet.bitcoinDerivationPath(0),
+	}
+	for _, name := range []blockchain.BlockchainName{blockchain.Litecoin, blockchain.BitcoinCash} {
+		chain, err := utxo.NewChain(name, wallet.utxoConfig(name).Network.Name)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, derivationPath(chain.CoinType, wallet.utxoConfig(name).Account, 0))
+	}
+
+	accounts := [][]uint32{}
+	for _, path := range paths {
+		if len(path) == 5 {
+			accounts = append(accounts, path[:3])
+		}
+	}
+	return accounts
+}
+
+// A watchOnlySigner derives the public keys of the unhardened paths below its
+// extended public keys, which are stored by their derivation path. It holds
+// the keys of one wallet, so the password is not used.
+type watchOnlySigner struct {
+	xpubs map[string]*bip32.Key
+}
+
+func newWatchOnlySigner(xpubs map[string]string) (*watchOnlySigner, error) {
+	if len(xpubs) == 0 {
+		return nil, fmt.Errorf("no extended public keys")
+	}
+	signer := &watchOnlySigner{xpubs: map[string]*bip32.Key{}}
+	for path, xpub := range xpubs {
+		parsed, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := bip32.B58Deserialize(xpub)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extended public key of %s: %v", path, err)
+		}
+		if key.IsPrivate {
+			return nil, fmt.Errorf("extended key of %s is private", path)
+		}
+		signer.xpubs[formatPath(parsed)] = key
+	}
+	return signer, nil
+}
+
+func (signer *watchOnlySigner) PublicKey(password string, path []uint32) (ecdsa.PublicKey, error) {
+	for depth := len(path); depth >= 0; depth-- {
+		key, ok := signer.xpubs[formatPath(path[:depth])]
+		if !ok {
+			continue
+		}
+		for _, val := range path[depth:] {
+			var err error
+			if key, err = key.NewChildKey(val); err != nil {
+				return ecdsa.PublicKey{}, err
+			}
+		}
+		pubKey, err := crypto.DecompressPubkey(key.Key)
+		if err != nil {
+			return ecdsa.PublicKey{}, err
+		}
+		return *pubKey, nil
+	}
+	return ecdsa.PublicKey{}, fmt.Errorf("no extended public key for %s", formatPath(path))
+}
+
+func (signer *watchOnlySigner) Sign(password string, path []uint32, hash []byte) ([]byte, error) {
+	return nil, ErrWatchOnly
+}
+
+// formatPath formats the derivation path, such as m/44'/0'/1'.
+func formatPath(path []uint32) string {
+	elems := []string{"m"}
+	for _, val := range path {
+		if val >= bip32.FirstHardenedChild {
+			elems = append(elems, fmt.Sprintf("%d'", val-bip32.FirstHardenedChild))
+			continue
+		}
+		elems = append(elems, fmt.Sprintf("%d", val))
+	}
+	return strings.Join(elems, "/")
+}
+
+func parsePath(path string) ([]uint32, error) {
+	elems := strings.Split(path, "/")
+	if elems[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path: %s", path)
+	}
+	parsed := []uint32{}
+	for _, elem := range elems[1:] {
+		hardened := strings.HasSuffix(elem, "'")
+		val, err := strconv.ParseUint(strings.TrimSuffix(elem, "'"), 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path: %s", path)
+		}
+		if hardened {
+			val += uint64(bip32.FirstHardenedChild)
+		}
+		parsed = append(parsed, uint32(val))
+	}
+	return parsed, nil
+}
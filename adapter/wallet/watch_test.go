@@ -0,0 +1,78 @@
+package wallet_test
+
+import (
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/wallet"
+
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/tyler-smith/go-bip32"
+)
+
+var _ = Describe("Watch-only wallets", func() {
+	config := Config{
+		Mnemonic:    "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		Ethereum:    BlockchainConfig{Network: Network{Name: "kovan"}},
+		Bitcoin:     BlockchainConfig{Network: Network{Name: "testnet"}},
+		Litecoin:    BlockchainConfig{Network: Network{Name: "testnet"}, Account: 1},
+		BitcoinCash: BlockchainConfig{Network: Network{Name: "testnet"}},
+	}
+
+	watchOnly := func() (Wallet, Wallet) {
+		watchConfig, err := WatchOnlyConfig(config, "password")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(watchConfig.Mnemonic).Should(BeEmpty())
+		Expect(watchConfig.ExtendedPublicKeys).Should(HaveKey("m"))
+		Expect(watchConfig.ExtendedPublicKeys).Should(HaveKey("m/44'/1'/1'"))
+
+		watch, err := NewWatchOnly(watchConfig)
+		Expect(err).ShouldNot(HaveOccurred())
+		return New(config), watch
+	}
+
+	It("should have the id and addresses of the wallet", func() {
+		full, watch := watchOnly()
+		Expect(watch.WatchOnly()).Should(BeTrue())
+		Expect(full.WatchOnly()).Should(BeFalse())
+
+		id, err := watch.ID("password", "")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(full.ID("password", "")).Should(Equal(id))
+
+		for _, chain := range []blockchain.BlockchainName{blockchain.Ethereum, blockchain.Bitcoin, blockchain.Litecoin, blockchain.BitcoinCash} {
+			for _, index := range []uint32{0, 5} {
+				address, err := watch.GetAddressAt("password", chain, index)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(full.GetAddressAt("password", chain, index)).Should(Equal(address))
+			}
+		}
+		witness, err := watch.GetWitnessAddress("password")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(full.GetWitnessAddress("password")).Should(Equal(witness))
+	})
+
+	It("should not sign", func() {
+		_, watch := watchOnly()
+		_, err := watch.ECDSASigner("password")
+		Expect(err).Should(Equal(ErrWatchOnly))
+		_, err = watch.EthereumAccount("password")
+		Expect(err).Should(Equal(ErrWatchOnly))
+		_, err = watch.BitcoinAccount("password")
+		Expect(err).Should(Equal(ErrWatchOnly))
+		_, err = watch.Transfer("password", blockchain.TokenLTC, "QXRDpPWNPdM54FMv9ECpZtyH3ZsL5zGe29", big.NewInt(1))
+		Expect(err).Should(Equal(ErrWatchOnly))
+	})
+
+	It("should reject extended private keys", func() {
+		key, err := bip32.NewMasterKey(make([]byte, 32))
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = NewWatchOnly(Config{ExtendedPublicKeys: map[string]string{"m": key.B58Serialize()}})
+		Expect(err).Should(HaveOccurred())
+		_, err = NewWatchOnly(Config{ExtendedPublicKeys: map[string]string{"m": key.PublicKey().B58Serialize()}})
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = NewWatchOnly(Config{})
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/renproject/swapperd/adapter/binder/utxo"
+)
+
+// A WitnessAccount signs and publishes the native segwit transactions that
+// spend P2WSH swap scripts, and reads their witnesses. It reads the blockchain
+// with the same Esplora client as the other bitcoin accounts.
+type WitnessAccount interface {
+	NetworkParams() *chaincfg.Params
+	SerializedPubKey() []byte
+	Sign(hash []byte) ([]byte, error)
+	UnspentOutputs(ctx context.Context, address string) ([]utxo.UTXO, error)
+	SpendingWitness(ctx context.Context, address string) ([][]byte, bool, error)
+	PublishTransaction(ctx context.Context, tx *wire.MsgTx) error
+}
+
+// BitcoinWitnessAccount returns the witness account of the bitcoin key of the
+// password.
+func (wallet *wallet) BitcoinWitnessAccount(password string) (WitnessAccount, error) {
+	return wallet.BitcoinWitnessAccountAt(password, 0)
+}
+
+// BitcoinWitnessAccountAt returns the witness account of the bitcoin key of the
+// address index.
+func (wallet *wallet) BitcoinWitnessAccountAt(password string, index uint32) (WitnessAccount, error) {
+	key, err := newKeySigner(wallet.signer, password, wallet.bitcoinDerivationPath(index))
+	if err != nil {
+		return nil, err
+	}
+	return &witnessAccount{
+		WitnessClient: wallet.bitcoinClient(),
+		key:           btcSigner{key},
+		params:        bitcoinNetworkParams(wallet.config.Bitcoin.Network.Name),
+	}, nil
+}
+
+type witnessAccount struct {
+	utxo.WitnessClient
+	key    btcSigner
+	params *chaincfg.Params
+}
+
+func (account *witnessAccount) NetworkParams() *chaincfg.Params {
+	return account.params
+}
+
+func (account *witnessAccount) SerializedPubKey() []byte {
+	return account.key.PubKey().SerializeCompressed()
+}
+
+// Sign returns the DER encoded signature of the hash.
+func (account *witnessAccount) Sign(hash []byte) ([]byte, error) {
+	sig, err := account.key.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Serialize(), nil
+}
+
+func bitcoinNetworkParams(network string) *chaincfg.Params {
+	switch network {
+	case "testnet", "testnet3":
+		return &chaincfg.TestNet3Params
+	default:
+		return &chaincfg.MainNetParams
+	}
+}
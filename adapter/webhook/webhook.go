@@ -0,0 +1,403 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/foundation/swap"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	DefaultMaxAttempts = 10
+	DefaultRetryDelay  = 10 * time.Second
+	MaxRetryDelay      = time.Hour
+
+	// LockedExpiry is how long a notification waits for the owner of its
+	// swap to be unlocked before it is dropped.
+	LockedExpiry = 7 * 24 * time.Hour
+
+	SignatureHeader = "Swapperd-Signature"
+	EventHeader     = "Swapperd-Event"
+)
+
+// Config is the daemon-wide webhook configuration. Every swap status
+// transition is posted to the URL, if it is not empty.
+type Config struct {
+	URL         string `json:"url"`
+	MaxAttempts int    `json:"maxAttempts,omitempty"`
+}
+
+// A Notification is a swap status transition that has not been delivered to
+// all of its webhooks yet.
+type Notification struct {
+	ID          string           `json:"id"`
+	Receipt     swap.SwapReceipt `json:"receipt"`
+	Delivered   []string         `json:"delivered"`
+	Attempts    int              `json:"attempts"`
+	NextAttempt int64            `json:"nextAttempt"`
+	Timestamp   int64            `json:"timestamp"`
+}
+
+// NewNotification returns a Notification for the receipt, which has just
+// transitioned into its current status. Notifications are identified by the
+// swap, the status and the time of the transition.
+func NewNotification(receipt swap.SwapReceipt, timestamp int64) Notification {
+	id := sha3.Sum256([]byte(fmt.Sprintf("%s:%d:%d", receipt.ID, receipt.Status, timestamp)))
+	return Notification{
+		ID:        base64.StdEncoding.EncodeToString(id[:]),
+		Receipt:   receipt,
+		Delivered: []string{},
+		Timestamp: timestamp,
+	}
+}
+
+type Storage interface {
+	PendingSwaps() ([]swap.SwapBlob, error)
+	Notifications() ([]Notification, error)
+	PutNotification(notification Notification) error
+	DeleteNotification(id string) error
+}
+
+type Signer interface {
+	ECDSASigner(password string) (wallet.ECDSASigner, error)
+}
+
+// A Webhook delivers the pending notifications in storage, retrying failed
+// deliveries with an exponential backoff. Notifications are signed with a
+// secret derived from the swap owner's wallet, so they are only delivered while
+// the owner's password is unlocked.
+type Webhook struct {
+	mu      *sync.RWMutex
+	config  Config
+	storage Storage
+	signer  Signer
+	logger  logrus.FieldLogger
+	client  *http.Client
+
+	// unlocked holds the secrets of the unlocked passwords, and owners maps
+	// the password hashes of their swaps to their secrets. Passwords are never
+	// kept.
+	unlocked map[string]bool
+	owners   map[string][]byte
+}
+
+func New(config Config, storage Storage, signer Signer, logger logrus.FieldLogger) *Webhook {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	return &Webhook{
+		mu:       new(sync.RWMutex),
+		config:   config,
+		storage:  storage,
+		signer:   signer,
+		logger:   logger,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		unlocked: map[string]bool{},
+		owners:   map[string][]byte{},
+	}
+}
+
+// Secret returns the key used to sign the notifications of the swaps that
+// belong to the password. It is the hash of a deterministic signature, so it
+// can be recomputed without being stored.
+func Secret(signer Signer, password string) ([]byte, error) {
+	ecdsaSigner, err := signer.ECDSASigner(password)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha3.Sum256([]byte("swapperd webhook secret"))
+	sig, err := ecdsaSigner.Sign(hash[:])
+	if err != nil {
+		return nil, err
+	}
+	secret := sha3.Sum256(sig)
+	return secret[:], nil
+}
+
+// Sign returns the base64 encoded HMAC-SHA256 of the body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Unlock allows the notifications of the swaps that belong to the password to
+// be delivered. The password hashes of the pending swaps and notifications are
+// matched against the password once, so that deliveries never compare them.
+func (webhook *Webhook) Unlock(password string) {
+	secret, err := Secret(webhook.signer, password)
+	if err != nil {
+		webhook.logger.Errorf("cannot load the webhook secret: %v", err)
+		return
+	}
+
+	webhook.mu.Lock()
+	if webhook.unlocked[string(secret)] {
+		webhook.mu.Unlock()
+		return
+	}
+	webhook.unlocked[string(secret)] = true
+	owned := map[string]bool{}
+	for passwordHash := range webhook.owners {
+		owned[passwordHash] = true
+	}
+	webhook.mu.Unlock()
+
+	passwordHashes, err := webhook.passwordHashes()
+	if err != nil {
+		webhook.logger.Errorf("cannot load the swaps of the webhook: %v", err)
+	}
+	matches := []string{}
+	for _, passwordHash := range passwordHashes {
+		if !owned[passwordHash] && verifyPasswordHash(passwordHash, password) {
+			owned[passwordHash] = true
+			matches = append(matches, passwordHash)
+		}
+	}
+
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	if !webhook.unlocked[string(secret)] {
+		// The password has been locked while its swaps were matched.
+		return
+	}
+	for _, passwordHash := range matches {
+		webhook.owners[passwordHash] = secret
+	}
+}
+
+// Lock stops the notifications of the swaps that belong to the password from
+// being delivered, and forgets its secret.
+func (webhook *Webhook) Lock(password string) {
+	secret, err := Secret(webhook.signer, password)
+	if err != nil {
+		webhook.logger.Errorf("cannot load the webhook secret: %v", err)
+		return
+	}
+
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	delete(webhook.unlocked, string(secret))
+	for passwordHash, ownerSecret := range webhook.owners {
+		if hmac.Equal(ownerSecret, secret) {
+			delete(webhook.owners, passwordHash)
+		}
+	}
+}
+
+// AddSwap allows the notifications of a new swap with the password hash to be
+// delivered, if its password is unlocked.
+func (webhook *Webhook) AddSwap(password, passwordHash string) {
+	if passwordHash == "" {
+		return
+	}
+	secret, err := Secret(webhook.signer, password)
+	if err != nil {
+		webhook.logger.Errorf("cannot load the webhook secret: %v", err)
+		return
+	}
+
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	if webhook.unlocked[string(secret)] {
+		webhook.owners[passwordHash] = secret
+	}
+}
+
+// passwordHashes returns the password hashes of the pending swaps and of the
+// notifications that have not been delivered.
+func (webhook *Webhook) passwordHashes() ([]string, error) {
+	passwordHashes := []string{}
+	notifications, err := webhook.storage.Notifications()
+	if err != nil {
+		return passwordHashes, err
+	}
+	for _, notification := range notifications {
+		passwordHashes = append(passwordHashes, notification.Receipt.PasswordHash)
+	}
+	pendingSwaps, err := webhook.storage.PendingSwaps()
+	if err != nil {
+		return passwordHashes, err
+	}
+	for _, pendingSwap := range pendingSwaps {
+		passwordHashes = append(passwordHashes, pendingSwap.PasswordHash)
+	}
+	return passwordHashes, nil
+}
+
+// Run delivers the pending notifications until the done channel is closed.
+func (webhook *Webhook) Run(done <-chan struct{}) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			webhook.Deliver(time.Now())
+		}
+	}
+}
+
+// Deliver tries to deliver every notification that is due at the given time.
+func (webhook *Webhook) Deliver(now time.Time) {
+	notifications, err := webhook.storage.Notifications()
+	if err != nil {
+		webhook.logger.Errorf("cannot load pending notifications: %v", err)
+		return
+	}
+	for _, notification := range notifications {
+		if notification.NextAttempt > now.Unix() {
+			continue
+		}
+		if err := webhook.deliver(notification, now); err != nil {
+			webhook.logger.Errorf("cannot deliver notification for swap %s: %v", notification.Receipt.ID, err)
+		}
+	}
+}
+
+func (webhook *Webhook) deliver(notification Notification, now time.Time) error {
+	urls := webhook.pendingURLs(notification)
+	if len(urls) == 0 {
+		return webhook.storage.DeleteNotification(notification.ID)
+	}
+
+	secret, ok := webhook.secret(notification.Receipt.PasswordHash)
+	if !ok {
+		// The owner of the swap has not been unlocked yet.
+		return webhook.expire(notification, now)
+	}
+
+	receipt := notification.Receipt
+	receipt.PasswordHash = ""
+	body, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+
+	var deliveryErr error
+	for _, url := range urls {
+		if err := webhook.post(url, secret, body); err != nil {
+			deliveryErr = err
+			continue
+		}
+		notification.Delivered = append(notification.Delivered, url)
+	}
+	if deliveryErr == nil {
+		return webhook.storage.DeleteNotification(notification.ID)
+	}
+
+	notification.Attempts++
+	if notification.Attempts >= webhook.config.MaxAttempts {
+		webhook.logger.Errorf("giving up on notification for swap %s after %d attempts", notification.Receipt.ID, notification.Attempts)
+		return webhook.storage.DeleteNotification(notification.ID)
+	}
+	notification.NextAttempt = now.Add(backoff(notification.Attempts)).Unix()
+	if err := webhook.storage.PutNotification(notification); err != nil {
+		return err
+	}
+	return deliveryErr
+}
+
+// expire drops a notification whose owner has not been unlocked for longer
+// than the LockedExpiry. Notifications stored before they were timestamped
+// start expiring now.
+func (webhook *Webhook) expire(notification Notification, now time.Time) error {
+	if notification.Timestamp == 0 {
+		notification.Timestamp = now.Unix()
+		return webhook.storage.PutNotification(notification)
+	}
+	if now.Sub(time.Unix(notification.Timestamp, 0)) < LockedExpiry {
+		return nil
+	}
+	webhook.logger.Errorf("giving up on notification for swap %s, its owner has not been unlocked", notification.Receipt.ID)
+	return webhook.storage.DeleteNotification(notification.ID)
+}
+
+func (webhook *Webhook) post(url string, secret, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, "swap")
+	if secret != nil {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := webhook.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code=%v from %s: %s", resp.StatusCode, url, respBytes)
+	}
+	return nil
+}
+
+// pendingURLs returns the webhooks that the notification has not been
+// delivered to.
+func (webhook *Webhook) pendingURLs(notification Notification) []string {
+	delivered := map[string]bool{}
+	for _, url := range notification.Delivered {
+		delivered[url] = true
+	}
+
+	urls := []string{}
+	for _, url := range []string{notification.Receipt.NotifyURL, webhook.config.URL} {
+		if url != "" && !delivered[url] {
+			delivered[url] = true
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// secret returns the secret of the unlocked owner of the password hash.
+// Receipts without a password hash are delivered unsigned.
+func (webhook *Webhook) secret(passwordHash string) ([]byte, bool) {
+	if passwordHash == "" {
+		return nil, true
+	}
+	webhook.mu.RLock()
+	defer webhook.mu.RUnlock()
+	secret, ok := webhook.owners[passwordHash]
+	return secret, ok
+}
+
+func verifyPasswordHash(passwordHash, password string) bool {
+	if passwordHash == "" {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(passwordHash)
+	if err != nil {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
+
+func backoff(attempts int) time.Duration {
+	delay := DefaultRetryDelay
+	for i := 1; i < attempts && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryDelay {
+		return MaxRetryDelay
+	}
+	return delay
+}
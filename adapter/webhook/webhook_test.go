@@ -0,0 +1,285 @@
+package webhook_test
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/adapter/webhook"
+
+	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/driver/logger"
+	"github.com/renproject/swapperd/foundation/swap"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+type mockStorage struct {
+	mu            *sync.Mutex
+	notifications map[string]Notification
+	pendingSwaps  []swap.SwapBlob
+}
+
+func newMockStorage(notifications ...Notification) *mockStorage {
+	storage := &mockStorage{new(sync.Mutex), map[string]Notification{}, []swap.SwapBlob{}}
+	for _, notification := range notifications {
+		storage.notifications[notification.ID] = notification
+	}
+	return storage
+}
+
+func (storage *mockStorage) PendingSwaps() ([]swap.SwapBlob, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	return storage.pendingSwaps, nil
+}
+
+func (storage *mockStorage) Notifications() ([]Notification, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	notifications := []Notification{}
+	for _, notification := range storage.notifications {
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+func (storage *mockStorage) PutNotification(notification Notification) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.notifications[notification.ID] = notification
+	return nil
+}
+
+func (storage *mockStorage) DeleteNotification(id string) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	delete(storage.notifications, id)
+	return nil
+}
+
+type mockSigner struct {
+	password string
+}
+
+func (signer mockSigner) Sign(hash []byte) ([]byte, error) {
+	sig := sha3.Sum256(append([]byte(signer.password), hash...))
+	return sig[:], nil
+}
+
+func (signer mockSigner) PublicKey() ecdsa.PublicKey {
+	return ecdsa.PublicKey{}
+}
+
+type mockWallet struct {
+}
+
+func (mockWallet) ECDSASigner(password string) (wallet.ECDSASigner, error) {
+	return mockSigner{password}, nil
+}
+
+var _ = Describe("Webhook", func() {
+	newReceipt := func(password, notifyURL string) swap.SwapReceipt {
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		Expect(err).ShouldNot(HaveOccurred())
+		return swap.SwapReceipt{
+			ID:           "DiqkYPg/2mCzGhlk7ENighXhFDSkSYJ9+qmmVHI3UrE=",
+			Status:       swap.Initiated,
+			NotifyURL:    notifyURL,
+			PasswordHash: base64.StdEncoding.EncodeToString(passwordHash),
+		}
+	}
+
+	Context("when delivering notifications", func() {
+		It("should post signed receipts to the swap and daemon-wide webhooks", func() {
+			requests := make(chan *http.Request, 2)
+			bodies := make(chan []byte, 2)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests <- r
+				bodies <- body
+			}))
+			defer server.Close()
+
+			notification := NewNotification(newReceipt("Alice", server.URL+"/swap"), time.Now().Unix())
+			storage := newMockStorage(notification)
+			webhook := New(Config{URL: server.URL + "/daemon"}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Unlock("Alice")
+			webhook.Deliver(time.Now())
+
+			secret, err := Secret(mockWallet{}, "Alice")
+			Expect(err).ShouldNot(HaveOccurred())
+			paths := []string{}
+			for i := 0; i < 2; i++ {
+				req := <-requests
+				body := <-bodies
+				paths = append(paths, req.URL.Path)
+				Expect(req.Header.Get(SignatureHeader)).Should(Equal(Sign(secret, body)))
+
+				receipt := swap.SwapReceipt{}
+				Expect(json.Unmarshal(body, &receipt)).ShouldNot(HaveOccurred())
+				Expect(receipt.Status).Should(Equal(swap.Initiated))
+				Expect(receipt.PasswordHash).Should(BeEmpty())
+			}
+			Expect(paths).Should(ConsistOf("/swap", "/daemon"))
+
+			notifications, err := storage.Notifications()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(notifications).Should(BeEmpty())
+		})
+
+		It("should wait until the owner of the swap is unlocked", func() {
+			delivered := make(chan struct{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				delivered <- struct{}{}
+			}))
+			defer server.Close()
+
+			storage := newMockStorage(NewNotification(newReceipt("Alice", server.URL), time.Now().Unix()))
+			webhook := New(Config{}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Unlock("Bob")
+			webhook.Deliver(time.Now())
+			Expect(delivered).ShouldNot(Receive())
+
+			webhook.Unlock("Alice")
+			webhook.Deliver(time.Now())
+			Expect(delivered).Should(Receive())
+		})
+
+		It("should stop delivering once the owner of the swap is locked", func() {
+			delivered := make(chan struct{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				delivered <- struct{}{}
+			}))
+			defer server.Close()
+
+			storage := newMockStorage()
+			webhook := New(Config{}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Unlock("Alice")
+			webhook.Lock("Alice")
+			storage.PutNotification(NewNotification(newReceipt("Alice", server.URL), time.Now().Unix()))
+			webhook.Deliver(time.Now())
+			Expect(delivered).ShouldNot(Receive())
+
+			webhook.Unlock("Alice")
+			webhook.Deliver(time.Now())
+			Expect(delivered).Should(Receive())
+		})
+
+		It("should deliver the notifications of swaps that are pending or added while unlocked", func() {
+			delivered := make(chan struct{}, 2)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				delivered <- struct{}{}
+			}))
+			defer server.Close()
+
+			pendingReceipt := newReceipt("Alice", server.URL)
+			storage := newMockStorage()
+			storage.pendingSwaps = append(storage.pendingSwaps, swap.SwapBlob{ID: pendingReceipt.ID, PasswordHash: pendingReceipt.PasswordHash})
+			webhook := New(Config{}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Unlock("Alice")
+
+			addedReceipt := newReceipt("Alice", server.URL)
+			addedReceipt.ID = "qGJ3ySxQ9Hr7vdHkV3cCMnlLn9iA1kLWtc4Kw2BqN0Q="
+			webhook.AddSwap("Alice", addedReceipt.PasswordHash)
+			lockedReceipt := newReceipt("Bob", server.URL)
+			lockedReceipt.ID = "5n3cBqIhvsBuR9VwZw0w4rnyGFpzT0Qp5n7gm6w8qXc="
+			webhook.AddSwap("Bob", lockedReceipt.PasswordHash)
+
+			for _, receipt := range []swap.SwapReceipt{pendingReceipt, addedReceipt, lockedReceipt} {
+				storage.PutNotification(NewNotification(receipt, time.Now().Unix()))
+			}
+			webhook.Deliver(time.Now())
+			Expect(delivered).Should(Receive())
+			Expect(delivered).Should(Receive())
+			Expect(delivered).ShouldNot(Receive())
+
+			notifications, _ := storage.Notifications()
+			Expect(notifications).Should(HaveLen(1))
+			Expect(notifications[0].Receipt.ID).Should(Equal(lockedReceipt.ID))
+		})
+
+		It("should drop notifications whose owner is not unlocked in time", func() {
+			storage := newMockStorage(NewNotification(newReceipt("Alice", "http://localhost"), time.Now().Unix()))
+			webhook := New(Config{}, storage, mockWallet{}, logger.NewStdOut())
+
+			webhook.Deliver(time.Now().Add(LockedExpiry / 2))
+			notifications, _ := storage.Notifications()
+			Expect(notifications).Should(HaveLen(1))
+
+			webhook.Deliver(time.Now().Add(LockedExpiry))
+			notifications, _ = storage.Notifications()
+			Expect(notifications).Should(BeEmpty())
+		})
+
+		It("should retry failed deliveries with an exponential backoff", func() {
+			fail := true
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if fail {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+
+			storage := newMockStorage(NewNotification(newReceipt("Alice", server.URL), time.Now().Unix()))
+			webhook := New(Config{}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Unlock("Alice")
+
+			now := time.Now()
+			webhook.Deliver(now)
+			notifications, _ := storage.Notifications()
+			Expect(notifications).Should(HaveLen(1))
+			Expect(notifications[0].Attempts).Should(Equal(1))
+			Expect(notifications[0].NextAttempt).Should(Equal(now.Add(DefaultRetryDelay).Unix()))
+
+			// Notifications are not retried before they are due.
+			webhook.Deliver(now)
+			Expect(attempts).Should(Equal(1))
+
+			now = now.Add(DefaultRetryDelay)
+			webhook.Deliver(now)
+			notifications, _ = storage.Notifications()
+			Expect(notifications[0].Attempts).Should(Equal(2))
+			Expect(notifications[0].NextAttempt).Should(Equal(now.Add(2 * DefaultRetryDelay).Unix()))
+
+			fail = false
+			webhook.Deliver(now.Add(2 * DefaultRetryDelay))
+			notifications, _ = storage.Notifications()
+			Expect(notifications).Should(BeEmpty())
+			Expect(attempts).Should(Equal(3))
+		})
+
+		It("should give up after the maximum number of attempts", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			storage := newMockStorage(NewNotification(newReceipt("Alice", server.URL), time.Now().Unix()))
+			webhook := New(Config{MaxAttempts: 2}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Unlock("Alice")
+
+			webhook.Deliver(time.Now())
+			webhook.Deliver(time.Now().Add(MaxRetryDelay))
+			notifications, _ := storage.Notifications()
+			Expect(notifications).Should(BeEmpty())
+		})
+
+		It("should drop notifications without any webhooks", func() {
+			storage := newMockStorage(NewNotification(newReceipt("Alice", ""), time.Now().Unix()))
+			webhook := New(Config{}, storage, mockWallet{}, logger.NewStdOut())
+			webhook.Deliver(time.Now())
+			notifications, _ := storage.Notifications()
+			Expect(notifications).Should(BeEmpty())
+		})
+	})
+})
@@ -34,20 +34,27 @@ func main() {
 			panic(err)
 		}
 	}
-	createKeystore("testnet", mnemonic)
-	createKeystore("mainnet", mnemonic)
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		panic(err)
+	}
+	if passphrase == "" {
+		fmt.Printf("%sNo keystore passphrase was set, the mnemonic is stored without encryption.%s\n", bold, reset)
+	}
+	createKeystore("testnet", mnemonic, passphrase)
+	createKeystore("mainnet", mnemonic, passphrase)
 	if err := startSwapperd(); err != nil {
 		panic(err)
 	}
 }
 
-func createKeystore(network, mnemonic string) {
+func createKeystore(network, mnemonic, passphrase string) {
 	homeDir := getSwapperHome()
-	if _, err := keystore.Wallet(homeDir, network); err == nil {
+	if keystore.Exists(homeDir, network) {
 		return
 	}
 
-	if err := keystore.Generate(homeDir, network, mnemonic); err != nil {
+	if err := keystore.Generate(homeDir, network, mnemonic, passphrase); err != nil {
 		panic(err)
 	}
 }
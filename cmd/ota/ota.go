@@ -219,7 +219,7 @@ func postSwap(swap, secret string) ([]byte, error) {
 }
 
 func postBootload(secret string) error {
-	req, err := http.NewRequest("POST", "http://localhost:17927/bootload", nil)
+	req, err := http.NewRequest("POST", "http://localhost:17927/unlock", nil)
 	if err != nil {
 		return err
 	}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 
 	"github.com/renproject/swapperd/driver/composer"
+	"github.com/renproject/swapperd/driver/keys"
 )
 
 func main() {
@@ -16,6 +18,14 @@ func main() {
 	}
 	homeDir := filepath.Dir(filepath.Dir(ex))
 
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := keys.New(homeDir).Run(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	testnet := composer.New(homeDir, "testnet", "17927")
 	go testnet.Run(done)
 	mainnet := composer.New(homeDir, "mainnet", "7927")
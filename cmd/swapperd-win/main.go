@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/renproject/swapperd/driver/composer"
+	"github.com/renproject/swapperd/driver/keys"
 	"golang.org/x/sys/windows/svc"
 )
 
@@ -16,7 +17,7 @@ func usage(errmsg string) {
 		"%s\n\n"+
 			"usage: %s <command>\n"+
 			"       where <command> is one of\n"+
-			"       install, remove, debug, start, stop, pause or continue.\n",
+			"       install, remove, debug, start, stop, pause, continue or keys.\n",
 		errmsg, os.Args[0])
 	os.Exit(2)
 }
@@ -30,6 +31,13 @@ func main() {
 	}
 	homeDir := filepath.Dir(filepath.Dir(ex))
 
+	if len(os.Args) > 1 && strings.ToLower(os.Args[1]) == "keys" {
+		if err := keys.New(homeDir).Run(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	testnet := composer.New(homeDir, "testnet", "17927")
 	mainnet := composer.New(homeDir, "mainnet", "7927")
 
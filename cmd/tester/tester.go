@@ -21,7 +21,11 @@ func main() {
 }
 
 func buildSwap(initiatorPassword string) swap.SwapBlob {
-	wallet, err := keystore.Wallet(os.Getenv("HOME")+"/.swapperd", "mainnet")
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		panic(err)
+	}
+	wallet, err := keystore.Wallet(os.Getenv("HOME")+"/.swapperd", "mainnet", passphrase)
 	if err != nil {
 		panic(err)
 	}
@@ -27,6 +27,10 @@ func (callback *callback) Reduce(msg tau.Message) tau.Message {
 	switch msg := msg.(type) {
 	case DelayedSwapRequest:
 		return callback.handleDelayedSwapRequest(msg)
+	case CancelSwap:
+		return callback.handleCancelRequest(msg.ID)
+	case RefundSwap:
+		return callback.handleCancelRequest(msg.ID)
 	case tau.Tick:
 		return callback.handleTick()
 	default:
@@ -72,6 +76,15 @@ func (callback *callback) handleCancelSwap(id swap.SwapID) tau.Message {
 	return tau.NewMessageBatch([]tau.Message{update, DeleteSwap{id}})
 }
 
+// handleCancelRequest cancels a delayed swap that is still waiting for its
+// details. Nothing has been funded yet, so a refund request also cancels it.
+func (callback *callback) handleCancelRequest(id swap.SwapID) tau.Message {
+	if _, ok := callback.swapMap[id]; !ok {
+		return nil
+	}
+	return callback.handleCancelSwap(id)
+}
+
 func (callback *callback) handleUpdateSwap(req SwapRequest) tau.Message {
 	update := ReceiptUpdate(swap.NewReceiptUpdate(req.ID, func(receipt *swap.SwapReceipt) {
 		receipt.ReceiveAmount = req.ReceiveAmount
@@ -103,3 +116,17 @@ type DeleteSwap struct {
 
 func (msg DeleteSwap) IsMessage() {
 }
+
+type CancelSwap struct {
+	ID swap.SwapID
+}
+
+func (msg CancelSwap) IsMessage() {
+}
+
+type RefundSwap struct {
+	ID swap.SwapID
+}
+
+func (msg RefundSwap) IsMessage() {
+}
@@ -96,6 +96,32 @@ var _ = Describe("Delayed Swap Task", func() {
 		})
 	})
 
+	Context("when receiving a cancel request", func() {
+		It("should cancel swaps that are waiting for their details", func() {
+			delayedTask, done := init(ErrSwapDetailsUnavailable)
+			defer close(done)
+			go delayedTask.Run(done)
+
+			test := func(request DelayedSwapRequest) bool {
+				delayedTask.IO().InputWriter() <- request
+				delayedTask.IO().InputWriter() <- CancelSwap{request.ID}
+				response := <-delayedTask.IO().OutputReader()
+
+				msg, ok := response.(tau.MessageBatch)
+				Expect(ok).Should(BeTrue())
+
+				update, ok := msg[0].(ReceiptUpdate)
+				Expect(ok).Should(BeTrue())
+				receipt := swap.NewSwapReceipt(swap.SwapBlob(request))
+				update.Update(&receipt)
+
+				return receipt.Status == swap.Cancelled && reflect.DeepEqual(DeleteSwap{request.ID}, msg[1].(DeleteSwap))
+			}
+
+			Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+		})
+	})
+
 	Context("when receiveng new tick", func() {
 		It("should return ", func() {
 			statusTask, done := init(ErrSwapDetailsUnavailable)
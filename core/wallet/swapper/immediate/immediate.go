@@ -2,6 +2,8 @@ package immediate
 
 import (
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
@@ -11,6 +13,24 @@ import (
 
 var ErrSwapExpired = fmt.Errorf("swap expired")
 var ErrAuditPending = fmt.Errorf("audit pending")
+var ErrSwapInitiated = fmt.Errorf("swap already initiated, request a refund instead")
+
+// ErrConfirmationsPending is returned by Audit and AuditSecret when the
+// audited transaction has not reached the required number of confirmations.
+type ErrConfirmationsPending struct {
+	Confirmations int64
+	Required      int64
+}
+
+// NewErrConfirmationsPending returns an ErrConfirmationsPending for a
+// transaction with the given confirmations.
+func NewErrConfirmationsPending(confirmations, required int64) error {
+	return ErrConfirmationsPending{confirmations, required}
+}
+
+func (err ErrConfirmationsPending) Error() string {
+	return fmt.Sprintf("%d of %d confirmations", err.Confirmations, err.Required)
+}
 
 type Contract interface {
 	Initiate() error
@@ -43,6 +63,10 @@ func (swapper *swapper) Reduce(msg tau.Message) tau.Message {
 		return swapper.handleTick()
 	case SwapRequest:
 		return swapper.handleSwap(msg)
+	case CancelSwap:
+		return swapper.handleCancelSwap(msg)
+	case RefundSwap:
+		return swapper.handleRefundSwap(msg)
 	default:
 		return tau.NewError(fmt.Errorf("invalid message type in swapper: %T", msg))
 	}
@@ -56,17 +80,119 @@ func (swapper *swapper) handleTick() tau.Message {
 	return tau.NewMessageBatch(msgs)
 }
 
+// handleSwap progresses the swap, and stores it again when the transactions
+// that it is waiting for have changed.
 func (swapper *swapper) handleSwap(req SwapRequest) tau.Message {
+	req = withFeeBumps(req)
+	sendBump, receiveBump := req.SendBump.Copy(), req.ReceiveBump.Copy()
+	msg := swapper.progress(req)
+	if reflect.DeepEqual(sendBump, req.SendBump) && reflect.DeepEqual(receiveBump, req.ReceiveBump) {
+		return msg
+	}
+
+	// The swap is stored before it can be deleted by the message. The fee
+	// bumps are copied, because they are still updated by this task while
+	// the swap is stored by another one.
+	update := UpdateSwap{req.Blob}
+	update.Blob.SendFeeBump = req.SendBump.Copy()
+	update.Blob.ReceiveFeeBump = req.ReceiveBump.Copy()
+	if msg == nil {
+		return update
+	}
+	return tau.NewMessageBatch([]tau.Message{update, msg})
+}
+
+func (swapper *swapper) progress(req SwapRequest) tau.Message {
+	swapper.bumpStalledFees(req)
 	native, foreign, err := swapper.builder.BuildSwapContracts(req)
 	if err != nil {
 		return tau.NewError(err)
 	}
+	if req.Blob.ForceRefund {
+		return swapper.refund(req, native, foreign)
+	}
 	if req.Blob.ShouldInitiateFirst {
 		return swapper.initiate(req, native, foreign)
 	}
 	return swapper.respond(req, native, foreign)
 }
 
+// bumpStalledFees bumps the fee of the swap transactions that have not been
+// confirmed in time, so that they are replaced when the contracts are built.
+func (swapper *swapper) bumpStalledFees(req SwapRequest) {
+	now := time.Now().Unix()
+	for _, bump := range []*swap.FeeBump{req.SendBump, req.ReceiveBump} {
+		if bump.Stalled(now, req.Blob.TimeLock) {
+			bump.Bump(now, req.Blob.TimeLock)
+		}
+	}
+}
+
+func (swapper *swapper) handleCancelSwap(msg CancelSwap) tau.Message {
+	req, ok := swapper.swapMap[msg.ID]
+	if !ok {
+		return nil
+	}
+	native, foreign, err := swapper.builder.BuildSwapContracts(req)
+	if err != nil {
+		return tau.NewError(err)
+	}
+
+	// A swap can only be cancelled if our side of it has not been funded.
+	if err := native.Audit(); err != ErrAuditPending && err != ErrSwapExpired {
+		if _, ok := err.(ErrConfirmationsPending); err == nil || ok {
+			err = ErrSwapInitiated
+		}
+		return tau.NewMessageBatch([]tau.Message{NewCancelRejectedUpdate(req.Blob.ID, err), tau.NewError(err)})
+	}
+	return swapper.handleResult(req, swap.Cancelled, native, foreign, nil, true)
+}
+
+func (swapper *swapper) handleRefundSwap(msg RefundSwap) tau.Message {
+	req, ok := swapper.swapMap[msg.ID]
+	if !ok {
+		return nil
+	}
+	req.Blob.ForceRefund = true
+	swapper.swapMap[msg.ID] = req
+	return swapper.handleSwap(req)
+}
+
+func (swapper *swapper) refund(req SwapRequest, native, foreign Contract) tau.Message {
+	// If the counterparty has already redeemed our side of the swap, the
+	// secret is known and the swap is completed instead of refunded.
+	if !req.Blob.ShouldInitiateFirst {
+		secret, err := native.AuditSecret()
+		if pending, ok := err.(ErrConfirmationsPending); ok {
+			return swapper.handleConfirming(req, pending, native, foreign)
+		}
+		if err == nil {
+			if err := foreign.Redeem(secret); err != nil {
+				return swapper.handleResult(req, swap.AuditedSecret, native, foreign, err, false)
+			}
+			return swapper.handleResult(req, swap.Redeemed, native, foreign, nil, true)
+		}
+	}
+
+	if err := native.Audit(); err != nil {
+		if err == ErrAuditPending || err == ErrSwapExpired {
+			return swapper.handleResult(req, swap.Cancelled, native, foreign, nil, true)
+		}
+		if _, ok := err.(ErrConfirmationsPending); !ok {
+			return tau.NewError(err)
+		}
+	}
+
+	if time.Now().Unix() <= req.Blob.TimeLock {
+		swapper.swapMap[req.Blob.ID] = req
+		return nil
+	}
+	if err := native.Refund(); err != nil {
+		return swapper.handleResult(req, swap.RefundFailed, native, foreign, err, false)
+	}
+	return swapper.handleResult(req, swap.Refunded, native, foreign, nil, true)
+}
+
 func (swapper *swapper) initiate(req SwapRequest, native, foreign Contract) tau.Message {
 	secret := sha3.Sum256(append([]byte(req.Blob.Password), []byte(req.Blob.ID)...))
 	if err := native.Initiate(); err != nil {
@@ -76,6 +202,9 @@ func (swapper *swapper) initiate(req SwapRequest, native, foreign Contract) tau.
 		if err == ErrAuditPending {
 			return swapper.handleResult(req, swap.AuditPending, native, foreign, nil, false)
 		}
+		if pending, ok := err.(ErrConfirmationsPending); ok {
+			return swapper.handleConfirming(req, pending, native, foreign)
+		}
 		if err != ErrSwapExpired {
 			return swapper.handleResult(req, swap.AuditPending, native, foreign, err, false)
 		}
@@ -95,6 +224,9 @@ func (swapper *swapper) respond(req SwapRequest, native, foreign Contract) tau.M
 		if err == ErrAuditPending {
 			return swapper.handleResult(req, swap.AuditPending, native, foreign, nil, false)
 		}
+		if pending, ok := err.(ErrConfirmationsPending); ok {
+			return swapper.handleConfirming(req, pending, native, foreign)
+		}
 		if err == ErrSwapExpired {
 			return swapper.handleResult(req, swap.Expired, native, foreign, err, true)
 		}
@@ -109,6 +241,9 @@ func (swapper *swapper) respond(req SwapRequest, native, foreign Contract) tau.M
 		if err == ErrAuditPending {
 			return swapper.handleResult(req, swap.AuditPending, native, foreign, nil, false)
 		}
+		if pending, ok := err.(ErrConfirmationsPending); ok {
+			return swapper.handleConfirming(req, pending, native, foreign)
+		}
 		if err != ErrSwapExpired {
 			return swapper.handleResult(req, swap.Initiated, native, foreign, err, false)
 		}
@@ -137,21 +272,49 @@ func (swapper *swapper) handleResult(req SwapRequest, status int, native, foreig
 	return tau.NewMessageBatch(messages)
 }
 
+// handleConfirming keeps the swap while the audited transaction is waiting for
+// confirmations, and reports the progress in the receipt.
+func (swapper *swapper) handleConfirming(req SwapRequest, pending ErrConfirmationsPending, native, foreign Contract) tau.Message {
+	swapper.swapMap[req.Blob.ID] = req
+	return NewConfirmationsUpdate(req.Blob.ID, pending, native, foreign)
+}
+
+// A SwapRequest is a swap that is being executed. SendBump and ReceiveBump are
+// the fee bumps of its blob.
 type SwapRequest struct {
 	Blob        swap.SwapBlob
 	SendCost    blockchain.Cost
 	ReceiveCost blockchain.Cost
+	SendBump    *swap.FeeBump
+	ReceiveBump *swap.FeeBump
 }
 
 func (msg SwapRequest) IsMessage() {
 }
 
+// NewSwapRequest returns the request of the swap, which continues to bump the
+// fees of the unconfirmed transactions that are stored with the swap.
 func NewSwapRequest(blob swap.SwapBlob, sendCost, receiveCost blockchain.Cost) SwapRequest {
-	return SwapRequest{
+	return withFeeBumps(SwapRequest{
 		Blob:        blob,
 		SendCost:    sendCost,
 		ReceiveCost: receiveCost,
+		SendBump:    blob.SendFeeBump,
+		ReceiveBump: blob.ReceiveFeeBump,
+	})
+}
+
+// withFeeBumps returns the request with fee bumps, which are shared with its
+// blob.
+func withFeeBumps(req SwapRequest) SwapRequest {
+	if req.SendBump == nil {
+		req.SendBump = swap.NewFeeBump()
+	}
+	if req.ReceiveBump == nil {
+		req.ReceiveBump = swap.NewFeeBump()
 	}
+	req.Blob.SendFeeBump, req.Blob.ReceiveFeeBump = req.SendBump, req.ReceiveBump
+	return req
 }
 
 type ReceiptUpdate swap.ReceiptUpdate
@@ -162,14 +325,62 @@ func (msg ReceiptUpdate) IsMessage() {
 func NewReceiptUpdate(id swap.SwapID, status int, native, foreign Contract) ReceiptUpdate {
 	return ReceiptUpdate(swap.NewReceiptUpdate(id, func(receipt *swap.SwapReceipt) {
 		receipt.Status = status
+		receipt.Confirmations = 0
+		receipt.RequiredConfirmations = 0
+		receipt.SendCost = blockchain.CostToCostBlob(native.Cost())
+		receipt.ReceiveCost = blockchain.CostToCostBlob(foreign.Cost())
+	}))
+}
+
+// NewConfirmationsUpdate returns a ReceiptUpdate for a swap that is waiting
+// for an audited transaction to be confirmed.
+func NewConfirmationsUpdate(id swap.SwapID, pending ErrConfirmationsPending, native, foreign Contract) ReceiptUpdate {
+	return ReceiptUpdate(swap.NewReceiptUpdate(id, func(receipt *swap.SwapReceipt) {
+		receipt.Status = swap.AuditConfirming
+		receipt.Confirmations = pending.Confirmations
+		receipt.RequiredConfirmations = pending.Required
 		receipt.SendCost = blockchain.CostToCostBlob(native.Cost())
 		receipt.ReceiveCost = blockchain.CostToCostBlob(foreign.Cost())
 	}))
 }
 
+// NewCancelRejectedUpdate returns a ReceiptUpdate that reports why a cancel
+// request has been rejected.
+func NewCancelRejectedUpdate(id swap.SwapID, err error) ReceiptUpdate {
+	return ReceiptUpdate(swap.NewReceiptUpdate(id, func(receipt *swap.SwapReceipt) {
+		receipt.CancelError = err.Error()
+	}))
+}
+
+// UpdateSwap stores the swap again, with the fee bumps of its unconfirmed
+// transactions.
+type UpdateSwap struct {
+	Blob swap.SwapBlob
+}
+
+func (msg UpdateSwap) IsMessage() {
+}
+
 type DeleteSwap struct {
 	ID swap.SwapID
 }
 
 func (msg DeleteSwap) IsMessage() {
 }
+
+// CancelSwap stops a swap that has not been initiated on our side.
+type CancelSwap struct {
+	ID swap.SwapID
+}
+
+func (msg CancelSwap) IsMessage() {
+}
+
+// RefundSwap stops a swap from progressing and refunds it as soon as the
+// timelock expires.
+type RefundSwap struct {
+	ID swap.SwapID
+}
+
+func (msg RefundSwap) IsMessage() {
+}
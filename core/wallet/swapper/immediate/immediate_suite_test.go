@@ -19,24 +19,34 @@ func TestImmediate(t *testing.T) {
 }
 
 type MockContractBuilder struct {
+	confirming bool
 }
 
 func NewMockContractBuilder() *MockContractBuilder {
 	return &MockContractBuilder{}
 }
 
+// NewMockConfirmingContractBuilder returns a MockContractBuilder whose
+// contracts are funded and redeemed, but not confirmed.
+func NewMockConfirmingContractBuilder() *MockContractBuilder {
+	return &MockContractBuilder{confirming: true}
+}
+
 func (builder MockContractBuilder) BuildSwapContracts(request immediate.SwapRequest) (immediate.Contract, immediate.Contract, error) {
 	if uint64(request.Blob.TimeLock)%36 == 8 {
 		return nil, nil, fmt.Errorf("invalid swap object")
 	}
-	return NewMockContract(request.Blob, request.SendCost), NewMockContract(request.Blob, request.ReceiveCost), nil
+	native, foreign := NewMockContract(request.Blob, request.SendCost), NewMockContract(request.Blob, request.ReceiveCost)
+	native.confirming, foreign.confirming = builder.confirming, builder.confirming
+	return native, foreign, nil
 }
 
 type MockContract struct {
-	rand   *rand.Rand
-	blob   swap.SwapBlob
-	cost   blockchain.Cost
-	status int
+	rand       *rand.Rand
+	blob       swap.SwapBlob
+	cost       blockchain.Cost
+	status     int
+	confirming bool
 }
 
 func NewMockContract(blob swap.SwapBlob, cost blockchain.Cost) *MockContract {
@@ -57,6 +67,9 @@ func (contract *MockContract) Initiate() error {
 
 func (contract *MockContract) Audit() error {
 	contract.status = swap.Audited
+	if contract.confirming {
+		return immediate.NewErrConfirmationsPending(1, 3)
+	}
 	if uint64(contract.blob.TimeLock)%9 == 1 {
 		return immediate.ErrSwapExpired
 	}
@@ -78,6 +91,9 @@ func (contract *MockContract) Redeem([32]byte) error {
 }
 
 func (contract *MockContract) AuditSecret() ([32]byte, error) {
+	if contract.confirming {
+		return [32]byte{}, immediate.NewErrConfirmationsPending(2, 3)
+	}
 	if uint64(contract.blob.TimeLock)%9 == 5 {
 		return [32]byte{}, fmt.Errorf("Connection Failed")
 	}
@@ -1,6 +1,7 @@
 package immediate_test
 
 import (
+	"math/big"
 	"testing/quick"
 
 	"github.com/renproject/swapperd/foundation/blockchain"
@@ -89,6 +90,7 @@ var _ = Describe("Immediate Swap Task", func() {
 			go immediateTask.Run(done)
 
 			test := func(blob swap.SwapBlob) bool {
+				blob.ForceRefund = false
 				request := NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
 				immediateTask.IO().InputWriter() <- request
 				response := <-immediateTask.IO().OutputReader()
@@ -106,6 +108,7 @@ var _ = Describe("Immediate Swap Task", func() {
 				go immediateTask.Run(done)
 
 				test := func(blob swap.SwapBlob) bool {
+					blob.ForceRefund = false
 					request := NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
 					immediateTask.IO().InputWriter() <- request
 					<-immediateTask.IO().OutputReader()
@@ -118,6 +121,168 @@ var _ = Describe("Immediate Swap Task", func() {
 			})
 		})
 
+		Context("when receiving a cancel request", func() {
+			It("should cancel swaps that have not been initiated", func() {
+				immediateTask, done := init()
+				defer close(done)
+				go immediateTask.Run(done)
+
+				test := func(blob swap.SwapBlob, k uint16) bool {
+					blob.TimeLock = 9*int64(k) + 2
+					blob.ShouldInitiateFirst = false
+					blob.ForceRefund = false
+					immediateTask.IO().InputWriter() <- NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
+					<-immediateTask.IO().OutputReader()
+
+					immediateTask.IO().InputWriter() <- CancelSwap{blob.ID}
+					messages := (<-immediateTask.IO().OutputReader()).(tau.MessageBatch)
+					update := messages[0].(ReceiptUpdate)
+					receipt := swap.NewSwapReceipt(blob)
+					update.Update(&receipt)
+					deleteSwap, ok := messages[1].(DeleteSwap)
+					return ok && len(messages) == 2 && deleteSwap.ID == blob.ID && receipt.Status == swap.Cancelled
+				}
+
+				Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+			})
+
+			It("should return an error for swaps that have been initiated", func() {
+				immediateTask, done := init()
+				defer close(done)
+				go immediateTask.Run(done)
+
+				test := func(blob swap.SwapBlob, k uint16) bool {
+					blob.TimeLock = 9 * int64(k)
+					blob.ShouldInitiateFirst = true
+					blob.ForceRefund = false
+					immediateTask.IO().InputWriter() <- NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
+					<-immediateTask.IO().OutputReader()
+
+					immediateTask.IO().InputWriter() <- CancelSwap{blob.ID}
+					messages := (<-immediateTask.IO().OutputReader()).(tau.MessageBatch)
+					update := messages[0].(ReceiptUpdate)
+					receipt := swap.NewSwapReceipt(blob)
+					update.Update(&receipt)
+					err, ok := messages[1].(tau.Error)
+					return ok && err.Error() == ErrSwapInitiated.Error() && receipt.CancelError == ErrSwapInitiated.Error()
+				}
+
+				Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("when receiving a refund request", func() {
+			It("should refund initiated swaps after the timelock expires", func() {
+				immediateTask, done := init()
+				defer close(done)
+				go immediateTask.Run(done)
+
+				test := func(blob swap.SwapBlob, k uint16) bool {
+					blob.TimeLock = 36 * int64(k)
+					blob.ShouldInitiateFirst = true
+					blob.ForceRefund = false
+					immediateTask.IO().InputWriter() <- NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
+					<-immediateTask.IO().OutputReader()
+
+					immediateTask.IO().InputWriter() <- RefundSwap{blob.ID}
+					messages := (<-immediateTask.IO().OutputReader()).(tau.MessageBatch)
+					update := messages[0].(ReceiptUpdate)
+					receipt := swap.NewSwapReceipt(blob)
+					update.Update(&receipt)
+					deleteSwap, ok := messages[1].(DeleteSwap)
+					return ok && len(messages) == 2 && deleteSwap.ID == blob.ID && receipt.Status == swap.Refunded
+				}
+
+				Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("when an audited transaction has not been confirmed", func() {
+			It("should report the confirmations and keep the swap", func() {
+				immediateTask, done := New(testutils.DefaultQuickCheckConfig.MaxCount, NewMockConfirmingContractBuilder()), make(chan struct{})
+				defer close(done)
+				go immediateTask.Run(done)
+
+				test := func(blob swap.SwapBlob, k uint16) bool {
+					blob.TimeLock = 9*int64(k) + 8
+					blob.ForceRefund = false
+					immediateTask.IO().InputWriter() <- NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
+					update, ok := (<-immediateTask.IO().OutputReader()).(ReceiptUpdate)
+					if !ok {
+						return uint64(blob.TimeLock)%36 == 8
+					}
+					receipt := swap.NewSwapReceipt(blob)
+					update.Update(&receipt)
+					if receipt.Status != swap.AuditConfirming || receipt.Confirmations != 1 || receipt.RequiredConfirmations != 3 {
+						return false
+					}
+
+					// The swap is kept, so that it is audited again on the
+					// next tick.
+					immediateTask.IO().InputWriter() <- CancelSwap{blob.ID}
+					messages := (<-immediateTask.IO().OutputReader()).(tau.MessageBatch)
+					update = messages[0].(ReceiptUpdate)
+					receipt = swap.NewSwapReceipt(blob)
+					update.Update(&receipt)
+					err, ok := messages[1].(tau.Error)
+					return ok && err.Error() == ErrSwapInitiated.Error() && receipt.CancelError == ErrSwapInitiated.Error()
+				}
+
+				Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("when a swap transaction has stalled", func() {
+			It("should bump the fee of the stalled transaction", func() {
+				immediateTask, done := init()
+				defer close(done)
+				go immediateTask.Run(done)
+
+				test := func(blob swap.SwapBlob, k uint16) bool {
+					blob.TimeLock = 9*int64(k) + 2
+					blob.ShouldInitiateFirst = false
+					blob.ForceRefund = false
+					request := NewSwapRequest(blob, blockchain.Cost{}, blockchain.Cost{})
+					request.SendBump.Broadcast("tx", big.NewInt(100), 1, 0)
+					immediateTask.IO().InputWriter() <- request
+					msg := <-immediateTask.IO().OutputReader()
+
+					// The bumped fee is stored with the swap.
+					update, ok := msg.(UpdateSwap)
+					if batch, isBatch := msg.(tau.MessageBatch); isBatch {
+						update, ok = batch[0].(UpdateSwap)
+					}
+
+					// The timelock has expired, so the fee is doubled. The
+					// stored fee bump is a copy of the one that the task
+					// keeps updating.
+					return ok &&
+						update.Blob.SendFeeBump != request.SendBump &&
+						update.Blob.SendFeeBump.Bumps == 1 &&
+						update.Blob.SendFeeBump.TxHash == "tx" &&
+						request.SendBump.Apply(big.NewInt(100)).Int64() == 200 &&
+						request.ReceiveBump.Bumps == 0
+				}
+
+				Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+			})
+
+			It("should progress swaps that were requested without fee bumps", func() {
+				immediateTask, done := init()
+				defer close(done)
+				go immediateTask.Run(done)
+
+				test := func(blob swap.SwapBlob) bool {
+					blob.ForceRefund = false
+					immediateTask.IO().InputWriter() <- SwapRequest{Blob: blob}
+					msg := <-immediateTask.IO().OutputReader()
+					return msg != nil
+				}
+
+				Expect(quick.Check(test, testutils.DefaultQuickCheckConfig)).ShouldNot(HaveOccurred())
+			})
+		})
+
 		Context("when receiving an unknown message type", func() {
 			It("should return an error", func() {
 				immediateTask, done := init()
@@ -16,6 +16,7 @@ type Storage interface {
 	LoadCosts(id swap.SwapID) (blockchain.Cost, blockchain.Cost)
 	PutSwap(blob swap.SwapBlob) error
 	DeletePendingSwap(swap.SwapID) error
+	PendingSwap(swap.SwapID) (swap.SwapBlob, error)
 	PendingSwaps() ([]swap.SwapBlob, error)
 }
 
@@ -41,8 +42,14 @@ func (swapper *swapper) Reduce(msg tau.Message) tau.Message {
 		return swapper.handleBootload(msg)
 	case SwapRequest:
 		return swapper.handleSwapRequest(msg)
+	case CancelSwap:
+		return swapper.handleCancelSwap(msg)
+	case RefundSwap:
+		return swapper.handleRefundSwap(msg)
 	case immediate.ReceiptUpdate:
 		return ReceiptUpdate(msg)
+	case immediate.UpdateSwap:
+		return swapper.handleUpdateSwap(msg.Blob)
 	case immediate.DeleteSwap:
 		return swapper.handleDeleteSwap(msg.ID)
 	case delayed.SwapRequest:
@@ -81,6 +88,28 @@ func (swapper *swapper) handleSwapRequest(msg SwapRequest) tau.Message {
 	return nil
 }
 
+func (swapper *swapper) handleCancelSwap(msg CancelSwap) tau.Message {
+	swapper.immediateSwapper.Send(immediate.CancelSwap{ID: msg.ID})
+	swapper.delayedSwapper.Send(delayed.CancelSwap{ID: msg.ID})
+	return nil
+}
+
+func (swapper *swapper) handleRefundSwap(msg RefundSwap) tau.Message {
+	// The refund request is persisted so that it survives restarts.
+	blob, err := swapper.storage.PendingSwap(msg.ID)
+	if err != nil {
+		return tau.NewError(fmt.Errorf("cannot refund swap %s: %v", msg.ID, err))
+	}
+	blob.ForceRefund = true
+	if err := swapper.storage.PutSwap(blob); err != nil {
+		return tau.NewError(err)
+	}
+
+	swapper.immediateSwapper.Send(immediate.RefundSwap{ID: msg.ID})
+	swapper.delayedSwapper.Send(delayed.RefundSwap{ID: msg.ID})
+	return nil
+}
+
 func (swapper *swapper) handleBootload(msg Bootload) tau.Message {
 	pendingSwaps, err := swapper.storage.PendingSwaps()
 	if err != nil {
@@ -115,6 +144,13 @@ func (swapper *swapper) handleBootload(msg Bootload) tau.Message {
 	return tau.NewMessageBatch(msgs)
 }
 
+func (swapper *swapper) handleUpdateSwap(blob swap.SwapBlob) tau.Message {
+	if err := swapper.storage.PutSwap(blob); err != nil {
+		return tau.NewError(err)
+	}
+	return nil
+}
+
 func (swapper *swapper) handleDeleteSwap(id swap.SwapID) tau.Message {
 	if err := swapper.storage.DeletePendingSwap(id); err != nil {
 		return tau.NewError(err)
@@ -127,6 +163,20 @@ type SwapRequest swap.SwapBlob
 func (SwapRequest) IsMessage() {
 }
 
+type CancelSwap struct {
+	ID swap.SwapID
+}
+
+func (CancelSwap) IsMessage() {
+}
+
+type RefundSwap struct {
+	ID swap.SwapID
+}
+
+func (RefundSwap) IsMessage() {
+}
+
 type Bootload struct {
 	Password string
 }
@@ -2,6 +2,7 @@ package swapper_test
 
 import (
 	"fmt"
+	"math/big"
 	"math/rand"
 	"reflect"
 	"testing/quick"
@@ -102,6 +103,44 @@ var _ = Describe("Swapper", func() {
 		})
 	})
 
+	Context("when receiving a swap update", func() {
+		It("should restore the fee bumps of the swap after a restart", func() {
+			requests := make(chan immediate.SwapRequest, 1)
+			delayedTask := newTask(nil)
+			immediateTask := newTask(func(msg tau.Message) tau.Message {
+				if req, ok := msg.(immediate.SwapRequest); ok {
+					requests <- req
+				}
+				return nil
+			})
+
+			storage := NewMockStorage()
+			done := make(chan struct{})
+			defer close(done)
+			swapper := init(storage, delayedTask, immediateTask)
+			go swapper.Run(done)
+
+			test := func(blob swap.SwapBlob) bool {
+				blob.Delay = false
+				blob.PasswordHash = ""
+				blob.SendFeeBump.Broadcast("tx", big.NewInt(100), 1, 0)
+				blob.SendFeeBump.Bump(0, blob.TimeLock)
+				swapper.IO().InputWriter() <- immediate.UpdateSwap{Blob: blob}
+				swapper.IO().InputWriter() <- Bootload{}
+				<-swapper.IO().OutputReader()
+
+				req := <-requests
+				defer storage.DeletePendingSwap(blob.ID)
+				return req.Blob.ID == blob.ID &&
+					req.SendBump == req.Blob.SendFeeBump &&
+					reflect.DeepEqual(req.SendBump, blob.SendFeeBump) &&
+					req.ReceiveBump.Bumps == 0
+			}
+
+			Expect(quick.Check(test, quickCheckConfig())).ShouldNot(HaveOccurred())
+		})
+	})
+
 	Context("when receiving error message", func() {
 		It("should return the error message", func() {
 			delayed := newTask(nil)
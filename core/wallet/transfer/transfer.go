@@ -1,100 +1,359 @@
 package transfer
 
 import (
+	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/republicprotocol/co-go"
 	"github.com/republicprotocol/tau"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// DropTimeout is how long a transfer can be missing from the blockchain
+// before it is marked as dropped.
+const DropTimeout = 24 * time.Hour
+
+// ErrTxNotFound is returned by a lookup when the blockchain does not know the
+// transaction.
+var ErrTxNotFound = fmt.Errorf("transaction not found")
+
+// The status of a transfer. A transfer is pending until it has been
+// broadcast, and failed if it could not be broadcast.
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusBroadcast = "broadcast"
+	TransferStatusFailed    = "failed"
+)
+
+// A TransferID uniquely identifies a transfer.
+type TransferID string
+
+// NewTransferID returns a random TransferID.
+func NewTransferID() TransferID {
+	id := [32]byte{}
+	rand.Read(id[:])
+	return TransferID(base64.StdEncoding.EncodeToString(id[:]))
+}
+
 type Storage interface {
-	PutTransfer(receipt TransferReceipt) error
+	UpdateTransferReceipt(update UpdateReceipt) error
 	Transfers() ([]TransferReceipt, error)
 }
 
 type Blockchain interface {
 	GetAddress(password string, blockchainName blockchain.BlockchainName) (string, error)
 	Transfer(password string, token blockchain.Token, to string, amount *big.Int) (string, error)
+
+	// TransferWithCost transfers the amount, and pays exactly the fee of the
+	// cost.
+	TransferWithCost(password string, token blockchain.Token, to string, amount *big.Int, cost blockchain.Cost) (string, error)
+
+	// BatchTransfer pays every recipient, and returns the hash of the
+	// transaction that paid each of them. When it fails, it returns the hashes
+	// of the recipients that were paid before the failure.
+	BatchTransfer(password string, token blockchain.Token, recipients []Recipient) ([]string, error)
+
 	Lookup(token blockchain.Token, txHash string) (UpdateReceipt, error)
+
+	// Confirmations returns the number of confirmations after which the
+	// transfers on the blockchain are no longer tracked.
+	Confirmations(blockchainName blockchain.BlockchainName) int64
 }
 
 type transfers struct {
-	blockchain Blockchain
-	storage    Storage
+	blockchain  Blockchain
+	storage     Storage
+	broadcaster tau.Task
 }
 
+// New returns a task that tracks the transfers in the storage. Transfer
+// requests are broadcast by a separate worker, so that a slow broadcast does
+// not hold up the ticks of the task.
 func New(cap int, bc Blockchain, storage Storage) tau.Task {
-	return tau.New(tau.NewIO(cap), &transfers{bc, storage})
+	broadcaster := tau.New(tau.NewIO(cap), &broadcaster{bc})
+	return tau.New(tau.NewIO(cap), &transfers{bc, storage, broadcaster}, broadcaster)
 }
 
 func (transfers *transfers) Reduce(msg tau.Message) tau.Message {
 	switch msg := msg.(type) {
 	case TransferRequest:
-		return transfers.handleTransferRequest(msg)
+		transfers.broadcaster.Send(msg)
+		return nil
+	case TransferResult:
+		return transfers.handleTransferResult(msg)
+	case tau.Tick:
+		return transfers.handleTick()
 	default:
 		return tau.NewError(fmt.Errorf("invalid message type in transfers: %T", msg))
 	}
 }
 
-func (transfers *transfers) handleTransferRequest(msg TransferRequest) tau.Message {
-	from, err := transfers.blockchain.GetAddress(msg.Password, msg.Token.Blockchain)
-	if err != nil {
+// handleTransferResult updates the pending receipt of a transfer with the
+// result of its broadcast.
+func (transfers *transfers) handleTransferResult(msg TransferResult) tau.Message {
+	if err := transfers.storage.UpdateTransferReceipt(msg.Update); err != nil {
 		return tau.NewError(err)
 	}
-	txHash, err := transfers.blockchain.Transfer(msg.Password, msg.Token, msg.To, msg.Amount)
+	if msg.Err != nil {
+		return tau.NewError(fmt.Errorf("failed to broadcast transfer %s: %v", msg.Update.ID, msg.Err))
+	}
+	return nil
+}
+
+type broadcaster struct {
+	blockchain Blockchain
+}
+
+func (broadcaster *broadcaster) Reduce(msg tau.Message) tau.Message {
+	switch msg := msg.(type) {
+	case TransferRequest:
+		return broadcaster.handleTransferRequest(msg)
+	default:
+		return tau.NewError(fmt.Errorf("invalid message type in broadcaster: %T", msg))
+	}
+}
+
+// handleTransferRequest broadcasts the transfer of the request, and returns
+// the result that updates its pending receipt.
+func (broadcaster *broadcaster) handleTransferRequest(msg TransferRequest) tau.Message {
+	from, err := broadcaster.blockchain.GetAddress(msg.Password, msg.Token.Blockchain)
 	if err != nil {
-		return tau.NewError(err)
+		return newFailedTransferResult(msg.ID, err)
+	}
+	if len(msg.Recipients) > 0 {
+		return broadcaster.handleBatchTransferRequest(msg, from)
+	}
+	var txHash string
+	if msg.SubtractFee {
+		txHash, err = broadcaster.blockchain.TransferWithCost(msg.Password, msg.Token, msg.To, msg.Amount, msg.TxCost)
+	} else {
+		txHash, err = broadcaster.blockchain.Transfer(msg.Password, msg.Token, msg.To, msg.Amount)
+	}
+	if err != nil {
+		return newFailedTransferResult(msg.ID, err)
+	}
+	update := NewUpdateReceipt(txHash, func(receipt *TransferReceipt) {
+		receipt.Status = TransferStatusBroadcast
+		receipt.From = from
+		receipt.TxHash = txHash
+	})
+	update.ID = msg.ID
+	return TransferResult{Update: update}
+}
+
+// handleBatchTransferRequest pays the recipients of the request. The receipt
+// has the hash of the transaction that paid each recipient, and its TxHash is
+// the hash of the last transaction.
+func (broadcaster *broadcaster) handleBatchTransferRequest(msg TransferRequest, from string) tau.Message {
+	txHashes, err := broadcaster.blockchain.BatchTransfer(msg.Password, msg.Token, msg.Recipients)
+	if len(txHashes) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no transactions were broadcast")
+		}
+		return newFailedTransferResult(msg.ID, err)
 	}
-	receipt := buildReceipt(msg, from, txHash)
-	if err := transfers.storage.PutTransfer(receipt); err != nil {
+
+	txHash := txHashes[len(txHashes)-1]
+	update := NewUpdateReceipt(txHash, func(receipt *TransferReceipt) {
+		receipt.Status = TransferStatusBroadcast
+		if err != nil {
+			receipt.Status = TransferStatusFailed
+			receipt.Error = err.Error()
+		}
+		receipt.From = from
+		receipt.TxHash = txHash
+		for i := range receipt.Recipients {
+			if i < len(txHashes) {
+				receipt.Recipients[i].TxHash = txHashes[i]
+			}
+		}
+	})
+	update.ID = msg.ID
+	return TransferResult{Update: update, Err: err}
+}
+
+func newFailedTransferResult(id TransferID, err error) TransferResult {
+	update := NewUpdateReceipt("", func(receipt *TransferReceipt) {
+		receipt.Status = TransferStatusFailed
+		receipt.Error = err.Error()
+	})
+	update.ID = id
+	return TransferResult{Update: update, Err: err}
+}
+
+// handleTick looks up the transfers that have not reached their confirmation
+// target, and updates their receipts.
+func (transfers *transfers) handleTick() tau.Message {
+	receipts, err := transfers.storage.Transfers()
+	if err != nil {
 		return tau.NewError(err)
 	}
+
+	pending := []TransferReceipt{}
+	for _, receipt := range receipts {
+		if receipt.Status != TransferStatusBroadcast || receipt.Dropped || receipt.Confirmations >= transfers.blockchain.Confirmations(receipt.Token.Blockchain) {
+			continue
+		}
+		pending = append(pending, receipt)
+	}
+
+	errs := make([]error, len(pending))
+	co.ParForAll(pending, func(i int) {
+		errs[i] = transfers.update(pending[i])
+	})
+	for i, err := range errs {
+		if err != nil {
+			return tau.NewError(fmt.Errorf("failed to update transfer %s on %s: %v", pending[i].TxHash, pending[i].Token.Blockchain, err))
+		}
+	}
 	return nil
 }
 
-func buildReceipt(req TransferRequest, from, txHash string) TransferReceipt {
+func (transfers *transfers) update(receipt TransferReceipt) error {
+	update, err := transfers.blockchain.Lookup(receipt.Token, receipt.TxHash)
+	if err == ErrTxNotFound {
+		if time.Since(time.Unix(receipt.Timestamp, 0)) < DropTimeout {
+			return nil
+		}
+		update = NewUpdateReceipt(receipt.TxHash, func(receipt *TransferReceipt) {
+			receipt.Dropped = true
+		})
+	} else if err != nil {
+		return err
+	}
+	update.ID = receipt.ID
+	return transfers.storage.UpdateTransferReceipt(update)
+}
+
+// NewPendingReceipt returns the receipt of a transfer request that has not
+// been broadcast.
+func NewPendingReceipt(req TransferRequest) TransferReceipt {
 	passwordHash, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	requestedAmount := ""
+	if req.RequestedAmount != nil {
+		requestedAmount = req.RequestedAmount.String()
+	}
+	recipients := make([]RecipientDetails, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		recipients[i] = RecipientDetails{To: recipient.To, Amount: recipient.Amount.String()}
+	}
 	return TransferReceipt{
+		ID:            req.ID,
+		Status:        TransferStatusPending,
 		Confirmations: 0,
 		Timestamp:     time.Now().Unix(),
 		PasswordHash:  base64.StdEncoding.EncodeToString(passwordHash),
 		TokenDetails: TokenDetails{
 			To:     req.To,
-			From:   from,
 			Token:  req.Token,
 			Amount: req.Amount.String(),
 			TxCost: blockchain.CostToCostBlob(req.TxCost),
-			TxHash: txHash,
 		},
+		Recipients:      recipients,
+		RequestedAmount: requestedAmount,
 	}
 }
 
+// A TransferRequest transfers the amount of the token to an address, or to
+// each of its recipients if it is a batch transfer. When the fee has been
+// subtracted from the requested amount, the transfer pays exactly the TxCost.
 type TransferRequest struct {
-	Password string
-	Token    blockchain.Token
-	To       string
-	Amount   *big.Int
-	TxCost   blockchain.Cost
+	ID         TransferID
+	Password   string
+	Token      blockchain.Token
+	To         string
+	Amount     *big.Int
+	TxCost     blockchain.Cost
+	Recipients []Recipient
+
+	SubtractFee     bool
+	RequestedAmount *big.Int
 }
 
-func NewTransferRequest(password string, token blockchain.Token, to string, amount *big.Int, txCost blockchain.Cost) TransferRequest {
-	return TransferRequest{password, token, to, amount, txCost}
+func NewTransferRequest(id TransferID, password string, token blockchain.Token, to string, amount *big.Int, txCost blockchain.Cost) TransferRequest {
+	return TransferRequest{ID: id, Password: password, Token: token, To: to, Amount: amount, TxCost: txCost}
+}
+
+// NewSubtractFeeTransferRequest returns the request of a transfer of the
+// amount, which is the requested amount minus the fee of the cost. The
+// requested amount is nil when the whole balance is transferred.
+func NewSubtractFeeTransferRequest(id TransferID, password string, token blockchain.Token, to string, amount, requestedAmount *big.Int, txCost blockchain.Cost) TransferRequest {
+	req := NewTransferRequest(id, password, token, to, amount, txCost)
+	req.SubtractFee = true
+	req.RequestedAmount = requestedAmount
+	return req
+}
+
+// NewBatchTransferRequest returns the request of a batch transfer to the
+// recipients. Its amount is the total amount paid to the recipients.
+func NewBatchTransferRequest(id TransferID, password string, token blockchain.Token, recipients []Recipient, txCost blockchain.Cost) TransferRequest {
+	amount := big.NewInt(0)
+	for _, recipient := range recipients {
+		amount.Add(amount, recipient.Amount)
+	}
+	return TransferRequest{ID: id, Password: password, Token: token, Amount: amount, TxCost: txCost, Recipients: recipients}
 }
 
 func (request TransferRequest) IsMessage() {
 }
 
-type TransferReceiptMap map[string]TransferReceipt
+// A TransferResult is the result of the broadcast of a transfer request. It
+// updates the pending receipt of the transfer, and Err is set if the transfer
+// could not be broadcast.
+type TransferResult struct {
+	Update UpdateReceipt
+	Err    error
+}
+
+func (result TransferResult) IsMessage() {
+}
+
+type TransferReceiptMap map[TransferID]TransferReceipt
 
 type TransferReceipt struct {
-	Confirmations int64  `json:"confirmations"`
-	Timestamp     int64  `json:"timestamp"`
-	PasswordHash  string `json:"passwordHash,omitempty"`
+	ID            TransferID `json:"id"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	Confirmations int64      `json:"confirmations"`
+	Timestamp     int64      `json:"timestamp"`
+	PasswordHash  string     `json:"passwordHash,omitempty"`
+
+	// Dropped is set when the transfer has been missing from the blockchain
+	// for longer than the DropTimeout.
+	Dropped bool `json:"dropped,omitempty"`
+
+	// Reverted is set when the transfer was mined, but failed. GasUsed is the
+	// gas spent by an ethereum or ERC20 transfer.
+	Reverted bool   `json:"reverted,omitempty"`
+	GasUsed  uint64 `json:"gasUsed,omitempty"`
 	TokenDetails
+
+	// Recipients are the recipients of a batch transfer. The amount of the
+	// receipt is the total amount paid to them, and the cost is shared.
+	Recipients []RecipientDetails `json:"recipients,omitempty"`
+
+	// RequestedAmount is the amount that was requested when the fee was
+	// subtracted from it. The amount of the receipt is the amount that was
+	// actually transferred.
+	RequestedAmount string `json:"requestedAmount,omitempty"`
+}
+
+// A Recipient is paid the amount by a batch transfer.
+type Recipient struct {
+	To     string
+	Amount *big.Int
+}
+
+// RecipientDetails are the details of a recipient of a batch transfer, and the
+// hash of the transaction that paid it.
+type RecipientDetails struct {
+	To     string `json:"to"`
+	Amount string `json:"value"`
+	TxHash string `json:"txHash,omitempty"`
 }
 
 type TokenDetails struct {
@@ -106,11 +365,15 @@ type TokenDetails struct {
 	TxHash string              `json:"txHash"`
 }
 
+// An UpdateReceipt updates the receipt of a transfer. Lookups return the
+// update of a transaction, and the ID of its transfer is set before it is
+// stored.
 type UpdateReceipt struct {
+	ID     TransferID
 	TxHash string
 	Update func(*TransferReceipt)
 }
 
 func NewUpdateReceipt(txHash string, update func(*TransferReceipt)) UpdateReceipt {
-	return UpdateReceipt{txHash, update}
+	return UpdateReceipt{TxHash: txHash, Update: update}
 }
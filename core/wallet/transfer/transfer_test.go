@@ -1,6 +1,8 @@
 package transfer_test
 
 import (
+	"errors"
+	"math/big"
 	"math/rand"
 	"testing/quick"
 	"time"
@@ -22,14 +24,138 @@ var DefaultQuickCheckConfig = &quick.Config{
 }
 
 var _ = Describe("Transfer Task", func() {
+	var storage *testutils.MockStorage
+	var done chan struct{}
+
+	BeforeEach(func() {
+		storage = testutils.NewMockStorage()
+		done = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(done)
+	})
+
+	// start runs a transfer task on the blockchain, and sends it the requests
+	// after storing their pending receipts.
+	start := func(bc Blockchain, reqs ...TransferRequest) tau.Task {
+		transferTask := New(BufferCapacity, bc, storage)
+		go transferTask.Run(done)
+		for _, req := range reqs {
+			Expect(storage.PutTransfer(NewPendingReceipt(req))).ShouldNot(HaveOccurred())
+			transferTask.IO().InputWriter() <- req
+		}
+		return transferTask
+	}
+
+	status := func(id TransferID) func() string {
+		return func() string {
+			receipt, err := storage.Transfer(id)
+			Expect(err).ShouldNot(HaveOccurred())
+			return receipt.Status
+		}
+	}
+
+	Context("when receiving a transfer request", func() {
+		It("should update the pending receipt when the transfer is broadcast", func() {
+			bc := testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})
+			req := NewTransferRequest(NewTransferID(), "password", blockchain.TokenLTC, "to", big.NewInt(1), blockchain.Cost{})
+			start(bc, req)
+			Eventually(status(req.ID)).Should(Equal(TransferStatusBroadcast))
+		})
+
+		It("should pay exactly the cost when the fee is subtracted from the amount", func() {
+			bc := &costBlockchain{MockBlockchain: testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})}
+			cost := blockchain.Cost{blockchain.LTC: big.NewInt(100)}
+			req := NewSubtractFeeTransferRequest(NewTransferID(), "password", blockchain.TokenLTC, "to", big.NewInt(900), big.NewInt(1000), cost)
+			start(bc, req)
+			Eventually(status(req.ID)).Should(Equal(TransferStatusBroadcast))
+			Expect(bc.cost).Should(Equal(cost))
+			receipt, err := storage.Transfer(req.ID)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(receipt.Amount).Should(Equal("900"))
+			Expect(receipt.RequestedAmount).Should(Equal("1000"))
+			Expect(receipt.TxCost).Should(Equal(blockchain.CostBlob{blockchain.LTC: "100"}))
+		})
+
+		It("should pay every recipient of a batch transfer", func() {
+			bc := testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})
+			recipients := []Recipient{{"alice", big.NewInt(1)}, {"bob", big.NewInt(2)}}
+			req := NewBatchTransferRequest(NewTransferID(), "password", blockchain.TokenLTC, recipients, blockchain.Cost{})
+			Expect(req.Amount.Int64()).Should(Equal(int64(3)))
+			start(bc, req)
+			Eventually(status(req.ID)).Should(Equal(TransferStatusBroadcast))
+			receipt, err := storage.Transfer(req.ID)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(receipt.Amount).Should(Equal("3"))
+			Expect(receipt.Recipients).Should(Equal([]RecipientDetails{{To: "alice", Amount: "1"}, {To: "bob", Amount: "2"}}))
+		})
+
+		It("should mark the receipt as failed when the transfer cannot be broadcast", func() {
+			bc := &failingBlockchain{testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})}
+			req := NewTransferRequest(NewTransferID(), "password", blockchain.TokenLTC, "to", big.NewInt(1), blockchain.Cost{})
+			transferTask := start(bc, req)
+			_, ok := (<-transferTask.IO().OutputReader()).(tau.Error)
+			Expect(ok).Should(BeTrue())
+			receipt, err := storage.Transfer(req.ID)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(receipt.Status).Should(Equal(TransferStatusFailed))
+			Expect(receipt.Error).Should(Equal("cannot broadcast"))
+		})
+	})
+
+	Context("when a transfer is being broadcast", func() {
+		It("should keep updating the confirmations of pending transfers", func() {
+			bc := &blockingBlockchain{testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{}), make(chan struct{})}
+			defer close(bc.unblock)
+			Expect(storage.PutTransfer(newReceipt("pending", time.Now()))).ShouldNot(HaveOccurred())
+			transferTask := start(bc, NewTransferRequest(NewTransferID(), "password", blockchain.TokenLTC, "to", big.NewInt(1), blockchain.Cost{}))
+			transferTask.IO().InputWriter() <- tau.NewTick(time.Now())
+			Eventually(func() int64 {
+				receipt, err := storage.Transfer("pending")
+				Expect(err).ShouldNot(HaveOccurred())
+				return receipt.Confirmations
+			}).Should(Equal(blockchain.Litecoin.DefaultConfirmations()))
+		})
+	})
+
+	Context("when receiving a tick", func() {
+		It("should update the confirmations of pending transfers", func() {
+			bc := testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})
+			Expect(storage.PutTransfer(newReceipt("pending", time.Now()))).ShouldNot(HaveOccurred())
+			transferTask := start(bc)
+
+			transferTask.IO().InputWriter() <- tau.NewTick(time.Now())
+			Eventually(func() int64 {
+				receipts, err := storage.Transfers()
+				Expect(err).ShouldNot(HaveOccurred())
+				return receipts[0].Confirmations
+			}).Should(Equal(blockchain.Litecoin.DefaultConfirmations()))
+		})
+
+		It("should only drop missing transfers after the drop timeout", func() {
+			bc := &missingBlockchain{testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})}
+			Expect(storage.PutTransfer(newReceipt("recent", time.Now()))).ShouldNot(HaveOccurred())
+			Expect(storage.PutTransfer(newReceipt("old", time.Now().Add(-DropTimeout-time.Hour)))).ShouldNot(HaveOccurred())
+			transferTask := start(bc)
+
+			transferTask.IO().InputWriter() <- tau.NewTick(time.Now())
+			Eventually(func() map[string]bool {
+				receipts, err := storage.Transfers()
+				Expect(err).ShouldNot(HaveOccurred())
+				dropped := map[string]bool{}
+				for _, receipt := range receipts {
+					dropped[receipt.TxHash] = receipt.Dropped
+				}
+				return dropped
+			}).Should(Equal(map[string]bool{"recent": false, "old": true}))
+		})
+	})
+
 	Context("when receiving an unknown message type", func() {
 		It("should return an error", func() {
 			bc := testutils.NewMockBlockchain(map[blockchain.TokenName]blockchain.Balance{})
-			storage := testutils.NewMockStorage()
-			transferTask := New(BufferCapacity, bc, storage)
-			done := make(chan struct{})
-			defer close(done)
-			go transferTask.Run(done)
+			transferTask := start(bc)
 			test := func() bool {
 				transferTask.IO().InputWriter() <- tau.RandomMessage{}
 				err := <-transferTask.IO().OutputReader()
@@ -40,3 +166,51 @@ var _ = Describe("Transfer Task", func() {
 		})
 	})
 })
+
+type missingBlockchain struct {
+	*testutils.MockBlockchain
+}
+
+func (bc *missingBlockchain) Lookup(token blockchain.Token, txHash string) (UpdateReceipt, error) {
+	return UpdateReceipt{}, ErrTxNotFound
+}
+
+type failingBlockchain struct {
+	*testutils.MockBlockchain
+}
+
+func (bc *failingBlockchain) Transfer(password string, token blockchain.Token, to string, amount *big.Int) (string, error) {
+	return "", errors.New("cannot broadcast")
+}
+
+type blockingBlockchain struct {
+	*testutils.MockBlockchain
+	unblock chan struct{}
+}
+
+func (bc *blockingBlockchain) Transfer(password string, token blockchain.Token, to string, amount *big.Int) (string, error) {
+	<-bc.unblock
+	return "", nil
+}
+
+type costBlockchain struct {
+	*testutils.MockBlockchain
+	cost blockchain.Cost
+}
+
+func (bc *costBlockchain) TransferWithCost(password string, token blockchain.Token, to string, amount *big.Int, cost blockchain.Cost) (string, error) {
+	bc.cost = cost
+	return "", nil
+}
+
+func newReceipt(txHash string, timestamp time.Time) TransferReceipt {
+	return TransferReceipt{
+		ID:        TransferID(txHash),
+		Status:    TransferStatusBroadcast,
+		Timestamp: timestamp.Unix(),
+		TokenDetails: TokenDetails{
+			Token:  blockchain.TokenLTC,
+			TxHash: txHash,
+		},
+	}
+}
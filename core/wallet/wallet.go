@@ -39,6 +39,8 @@ func (wallet *wallet) Reduce(msg tau.Message) tau.Message {
 		wallet.handleTick(msg)
 	case swapper.SwapRequest:
 		wallet.handleSwapRequest(msg)
+	case swapper.CancelSwap, swapper.RefundSwap:
+		wallet.swapperTask.Send(msg)
 	case swapper.ReceiptUpdate:
 		wallet.swapStatusTask.Send(status.ReceiptUpdate(msg))
 	case transfer.TransferRequest:
@@ -62,6 +64,7 @@ func (wallet *wallet) handleBootload(msg Bootload) {
 
 func (wallet *wallet) handleTick(msg tau.Tick) {
 	wallet.swapperTask.Send(msg)
+	wallet.transferTask.Send(msg)
 }
 
 type Bootload struct {
@@ -5,10 +5,12 @@ import (
 	"github.com/renproject/swapperd/adapter/callback"
 	"github.com/renproject/swapperd/adapter/db"
 	"github.com/renproject/swapperd/adapter/server"
+	"github.com/renproject/swapperd/adapter/webhook"
 	"github.com/renproject/swapperd/core/wallet"
 	"github.com/renproject/swapperd/driver/keystore"
 	"github.com/renproject/swapperd/driver/leveldb"
 	"github.com/renproject/swapperd/driver/logger"
+	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/republicprotocol/co-go"
 	"github.com/republicprotocol/tau"
 	"github.com/sirupsen/logrus"
@@ -18,6 +20,7 @@ const BufferCapacity = 2048
 
 type composer struct {
 	server      server.Server
+	webhook     *webhook.Webhook
 	logger      logrus.FieldLogger
 	walletTask  tau.Task
 	serviceTask tau.Task
@@ -33,21 +36,53 @@ func New(homeDir, network, port string) Composer {
 		panic(err)
 	}
 
-	storage := db.New(ldb)
+	stream := server.NewStream()
+	storage := db.NewWithNotifier(ldb, stream)
 	logger := logger.NewStdOut()
 
-	bc, err := keystore.Wallet(homeDir, network)
+	tokens, err := keystore.Tokens(homeDir)
 	if err != nil {
 		panic(err)
 	}
+	if err := blockchain.RegisterTokens(tokens); err != nil {
+		panic(err)
+	}
+
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		panic(err)
+	}
+	bc, err := keystore.Wallet(homeDir, network, passphrase)
+	if err != nil {
+		panic(err)
+	}
+	if encrypted, err := keystore.Encrypted(homeDir, network); err == nil && !encrypted && !bc.WatchOnly() {
+		logger.Warnf("the %s keystore is not encrypted, set %s to encrypt it", network, keystore.PassphraseEnv)
+	}
+
+	timeLocks, err := keystore.TimeLockPolicies(homeDir, network)
+	if err != nil {
+		panic(err)
+	}
+
+	webhookConfig, err := keystore.WebhookConfig(homeDir, network)
+	if err != nil {
+		panic(err)
+	}
+	webhook := webhook.New(webhookConfig, storage, bc, logger)
 
 	receiver := server.NewReceiver(BufferCapacity)
 	serviceTask := server.NewService(BufferCapacity, receiver)
 	serviceTask.Send(server.AcceptRequest{})
 
-	server := server.NewHttpServer(BufferCapacity, port, receiver, storage, bc, logger)
-	walletTask := wallet.New(BufferCapacity, storage, bc, binder.NewBuilder(bc, logger), callback.New())
-	return &composer{server, logger, walletTask, serviceTask}
+	newHttpServer := server.NewHttpServer
+	if bc.WatchOnly() {
+		logger.Infof("the %s keystore is watch-only, swapperd is running in read-only mode", network)
+		newHttpServer = server.NewReadOnlyHttpServer
+	}
+	server := newHttpServer(BufferCapacity, port, receiver, stream, storage, bc, webhook, timeLocks, logger)
+	walletTask := wallet.New(BufferCapacity, storage, bc, binder.NewBuilder(bc, timeLocks, logger), callback.New(timeLocks))
+	return &composer{server, webhook, logger, walletTask, serviceTask}
 }
 
 func (composer *composer) Run(done <-chan struct{}) {
@@ -69,5 +104,8 @@ func (composer *composer) Run(done <-chan struct{}) {
 		func() {
 			composer.server.Run(done)
 		},
+		func() {
+			composer.webhook.Run(done)
+		},
 	)
 }
@@ -0,0 +1,202 @@
+// Package keys implements the `swapperd keys` commands, which back up, restore
+// and rotate the mnemonic in the keystores of swapperd.
+package keys
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/renproject/swapperd/driver/keystore"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var NetworkFlag = cli.StringFlag{
+	Name:  "network",
+	Value: "mainnet",
+	Usage: "The network of the keystore (mainnet or testnet)",
+}
+
+// New returns the `swapperd keys` command line app for the keystores in the
+// home directory.
+func New(homeDir string) *cli.App {
+	keys := &keys{homeDir, bufio.NewReader(os.Stdin), os.Stdout}
+
+	app := cli.NewApp()
+	app.Name = "swapperd keys"
+	app.Usage = "Back up, restore and rotate the swapperd mnemonic."
+	app.Commands = []cli.Command{
+		{
+			Name:   "export",
+			Usage:  "Print the mnemonic of the keystore",
+			Flags:  []cli.Flag{NetworkFlag},
+			Action: keys.export,
+		},
+		{
+			Name:   "verify",
+			Usage:  "Check a backup of the mnemonic against the keystore",
+			Flags:  []cli.Flag{NetworkFlag},
+			Action: keys.verify,
+		},
+		{
+			Name:   "restore",
+			Usage:  "Replace the mnemonic of the keystore with a backup",
+			Flags:  []cli.Flag{NetworkFlag},
+			Action: keys.restore,
+		},
+		{
+			Name:   "rotate",
+			Usage:  "Replace the mnemonic of the keystore with a new one, and move all funds to it. Swapperd must be stopped, and have no pending swaps",
+			Flags:  []cli.Flag{NetworkFlag},
+			Action: keys.rotate,
+		},
+		{
+			Name:   "watch-only",
+			Usage:  "Print a watch-only keystore of a wallet, which holds its extended public keys",
+			Flags:  []cli.Flag{NetworkFlag},
+			Action: keys.watchOnly,
+		},
+	}
+	return app
+}
+
+type keys struct {
+	homeDir string
+	in      *bufio.Reader
+	out     io.Writer
+}
+
+func (keys *keys) export(c *cli.Context) error {
+	network := c.String("network")
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		return err
+	}
+	mnemonic, err := keystore.Mnemonic(keys.homeDir, network, passphrase)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(keys.out, "Anyone who knows the mnemonic, and your passwords, can spend your funds.")
+	if !keys.confirm("Print the mnemonic?") {
+		return fmt.Errorf("export cancelled")
+	}
+	keys.printMnemonic(mnemonic)
+	return nil
+}
+
+func (keys *keys) watchOnly(c *cli.Context) error {
+	network := c.String("network")
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		return err
+	}
+	password, err := keys.readSecret("Enter the password of the wallet to watch: ")
+	if err != nil {
+		return err
+	}
+	data, err := keystore.WatchOnly(keys.homeDir, network, passphrase, password)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(keys.out, "%s\n", data)
+	return nil
+}
+
+func (keys *keys) verify(c *cli.Context) error {
+	network := c.String("network")
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		return err
+	}
+	mnemonic, err := keystore.Mnemonic(keys.homeDir, network, passphrase)
+	if err != nil {
+		return err
+	}
+
+	backup, err := keys.readSecret("Enter your backup of the mnemonic: ")
+	if err != nil {
+		return err
+	}
+	if normalize(backup) != normalize(mnemonic) {
+		return fmt.Errorf("the backup does not match the mnemonic of the %s keystore", network)
+	}
+	fmt.Fprintf(keys.out, "The backup matches the mnemonic of the %s keystore.\n", network)
+	return nil
+}
+
+func (keys *keys) restore(c *cli.Context) error {
+	network := c.String("network")
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		return err
+	}
+
+	mnemonic, err := keys.readSecret("Enter the mnemonic to restore: ")
+	if err != nil {
+		return err
+	}
+	mnemonic = normalize(mnemonic)
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return keystore.ErrInvalidMnemonic
+	}
+
+	if !keystore.Exists(keys.homeDir, network) {
+		if err := keystore.Generate(keys.homeDir, network, mnemonic, passphrase); err != nil {
+			return err
+		}
+		fmt.Fprintf(keys.out, "Restored the %s keystore.\n", network)
+		return nil
+	}
+
+	fmt.Fprintf(keys.out, "Funds held by the current mnemonic are not moved, back it up before restoring.\n")
+	if !keys.confirm(fmt.Sprintf("Replace the mnemonic of the %s keystore?", network)) {
+		return fmt.Errorf("restore cancelled")
+	}
+	backup, err := keystore.Replace(keys.homeDir, network, mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(keys.out, "Restored the %s keystore, the previous keystore was moved to %s.\nRestart swapperd to use it.\n", network, backup)
+	return nil
+}
+
+// confirm asks a yes or no question, and returns true if the answer is yes.
+func (keys *keys) confirm(question string) bool {
+	fmt.Fprintf(keys.out, "%s [y/N]: ", question)
+	answer, err := keys.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// readSecret reads a line without echoing it, when stdin is a terminal.
+func (keys *keys) readSecret(prompt string) (string, error) {
+	fmt.Fprint(keys.out, prompt)
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		data, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(keys.out)
+		return string(data), err
+	}
+	line, err := keys.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (keys *keys) printMnemonic(mnemonic string) {
+	for i, word := range strings.Fields(mnemonic) {
+		fmt.Fprintf(keys.out, "%2d. %s\n", i+1, word)
+	}
+}
+
+func normalize(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
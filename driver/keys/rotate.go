@@ -0,0 +1,162 @@
+package keys
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/renproject/swapperd/adapter/db"
+	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/renproject/swapperd/driver/keystore"
+	"github.com/renproject/swapperd/driver/leveldb"
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/urfave/cli"
+)
+
+// rotate replaces the mnemonic with a new one, and sweeps the balances of the
+// wallets of the given passwords to the same wallets of the new mnemonic. It
+// refuses to run while swapperd is running or has pending swaps, as their funds
+// would be left behind with the previous mnemonic.
+func (keys *keys) rotate(c *cli.Context) error {
+	network := c.String("network")
+	if err := keys.verifyNoPendingSwaps(network); err != nil {
+		return err
+	}
+	passphrase, err := keystore.Passphrase()
+	if err != nil {
+		return err
+	}
+	from, err := keystore.Wallet(keys.homeDir, network, passphrase)
+	if err != nil {
+		return err
+	}
+
+	passwords := []string{}
+	for {
+		password, err := keys.readSecret("Enter the password of a wallet to move (empty when done): ")
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			break
+		}
+		passwords = append(passwords, password)
+	}
+	if len(passwords) == 0 {
+		fmt.Fprintln(keys.out, "No passwords were entered, funds are only moved for the default wallet.")
+		passwords = append(passwords, "")
+	}
+
+	entropy, err := bip39.NewEntropy(128)
+	if err != nil {
+		return err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(keys.out, "Write down the new mnemonic:")
+	keys.printMnemonic(mnemonic)
+	backup, err := keys.readSecret("Enter the new mnemonic to confirm your backup: ")
+	if err != nil {
+		return err
+	}
+	if normalize(backup) != mnemonic {
+		return fmt.Errorf("the backup does not match the new mnemonic, the keystore was not changed")
+	}
+
+	// Funds are moved before the keystore is replaced, so that a failure
+	// leaves the current keystore in place.
+	to, err := keystore.WalletWithMnemonic(keys.homeDir, network, mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	for _, password := range passwords {
+		if err := keys.sweep(from, to, password); err != nil {
+			return fmt.Errorf("cannot move funds, the keystore was not changed and the funds that were moved can be recovered with the new mnemonic: %v", err)
+		}
+	}
+
+	previous, err := keystore.Replace(keys.homeDir, network, mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(keys.out, "Replaced the %s keystore, the previous keystore was moved to %s.\n", network, previous)
+	fmt.Fprintln(keys.out, "Restart swapperd to use the new keystore.")
+	return nil
+}
+
+// verifyNoPendingSwaps returns an error if the database of the network is held
+// by a running swapperd, or if it has pending swaps.
+func (keys *keys) verifyNoPendingSwaps(network string) error {
+	ldb, err := leveldb.NewReadOnlyStore(keys.homeDir, network)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot open the %s database, stop swapperd before rotating the mnemonic: %v", network, err)
+	}
+	defer ldb.Close()
+
+	pendingSwaps, err := db.New(ldb).PendingSwaps()
+	if err != nil {
+		return err
+	}
+	if len(pendingSwaps) > 0 {
+		return fmt.Errorf("%d swaps are pending, wait for them to finish or cancel them before rotating the mnemonic", len(pendingSwaps))
+	}
+	return nil
+}
+
+// sweep transfers the balances of the wallet of the password to the addresses
+// of the same password in the new wallet. ERC20 tokens are transferred before
+// ether, which pays for their gas.
+func (keys *keys) sweep(from, to wallet.Wallet, password string) error {
+	tokens := from.SupportedTokens()
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Blockchain == blockchain.ERC20 && tokens[j].Blockchain != blockchain.ERC20
+	})
+
+	for _, token := range tokens {
+		lastIndex, err := from.LastUsedIndex(password, token, wallet.GapLimit)
+		if err != nil {
+			return fmt.Errorf("cannot scan %s addresses: %v", token.Name, err)
+		}
+		for index := uint32(0); index <= lastIndex; index++ {
+			if err := keys.sweepAt(from, to, password, token, index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sweepAt transfers the balance of the address index of the token, and pays
+// exactly the fee that is subtracted from it. Balances that do not cover the
+// fee of their transfer are left behind.
+func (keys *keys) sweepAt(from, to wallet.Wallet, password string, token blockchain.Token, index uint32) error {
+	balance, err := from.BalanceAt(password, token, index)
+	if err != nil {
+		return fmt.Errorf("cannot get %s balance: %v", token.Name, err)
+	}
+	if balance.Amount == "0" {
+		return nil
+	}
+
+	address, err := to.GetAddress(password, token.Blockchain)
+	if err != nil {
+		return err
+	}
+	amount, cost, err := from.SpendableAmountAt(password, token, index, address, nil)
+	if err != nil {
+		fmt.Fprintf(keys.out, "Left %s %s at address %d: %v\n", balance.Amount, token.Name, index, err)
+		return nil
+	}
+	txHash, err := from.TransferWithCostAt(password, token, index, address, amount, cost)
+	if err != nil {
+		return fmt.Errorf("cannot transfer %s: %v", token.Name, err)
+	}
+	fmt.Fprintf(keys.out, "Moved %s %s to %s (%s)\n", amount, token.Name, address, txHash)
+	return nil
+}
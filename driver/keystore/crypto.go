@@ -0,0 +1,100 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// The scrypt parameters of new keystores. Decrypting a keystore uses the
+// parameters that it was encrypted with.
+const (
+	ScryptN = 1 << 18
+	ScryptR = 8
+	ScryptP = 1
+)
+
+var ErrInvalidPassphrase = fmt.Errorf("invalid keystore passphrase")
+
+type encryptedMnemonic struct {
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfParams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`
+	Ciphertext string       `json:"ciphertext"`
+}
+
+type scryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// encryptMnemonic encrypts the mnemonic with AES-256-GCM, using a key derived
+// from the passphrase by scrypt.
+func encryptMnemonic(mnemonic, passphrase string) (encryptedMnemonic, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return encryptedMnemonic{}, err
+	}
+	params := scryptParams{ScryptN, ScryptR, ScryptP, base64.StdEncoding.EncodeToString(salt)}
+	aead, err := params.aead(passphrase)
+	if err != nil {
+		return encryptedMnemonic{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return encryptedMnemonic{}, err
+	}
+	return encryptedMnemonic{
+		KDF:        "scrypt",
+		KDFParams:  params,
+		Cipher:     "aes-256-gcm",
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, []byte(mnemonic), nil)),
+	}, nil
+}
+
+func (crypto encryptedMnemonic) decrypt(passphrase string) (string, error) {
+	if crypto.KDF != "scrypt" || crypto.Cipher != "aes-256-gcm" {
+		return "", fmt.Errorf("unsupported keystore encryption %s with %s", crypto.Cipher, crypto.KDF)
+	}
+	aead, err := crypto.KDFParams.aead(passphrase)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(crypto.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("invalid keystore nonce")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(crypto.Ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid keystore ciphertext")
+	}
+	mnemonic, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidPassphrase
+	}
+	return string(mnemonic), nil
+}
+
+func (params scryptParams) aead(passphrase string) (cipher.AEAD, error) {
+	salt, err := base64.StdEncoding.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore salt")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
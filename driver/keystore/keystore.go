@@ -5,37 +5,219 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/renproject/swapperd/adapter/signer"
 	"github.com/renproject/swapperd/adapter/wallet"
+	"github.com/tyler-smith/go-bip39"
 )
 
-func Wallet(homeDir, network string) (wallet.Wallet, error) {
-	path := keystorePath(homeDir, network)
-	data, err := ioutil.ReadFile(path)
+// Version is the version of the encrypted keystore format. Keystores without
+// a version store the mnemonic in plaintext.
+const Version = 1
+
+var ErrInvalidMnemonic = fmt.Errorf("invalid mnemonic")
+
+// A keystoreFile is the encrypted keystore of a network. Only the mnemonic is
+// encrypted, the rest of the config can still be edited.
+type keystoreFile struct {
+	Version int               `json:"version"`
+	Crypto  encryptedMnemonic `json:"crypto"`
+	Config  wallet.Config     `json:"config"`
+}
+
+// Wallet loads the keystore of the network. An encrypted keystore is decrypted
+// with the passphrase. A plaintext keystore is encrypted with the passphrase,
+// when it is set, and written back in the encrypted format. The wallet signs
+// with the remote signer of the keystore, when it is set.
+func Wallet(homeDir, network, passphrase string) (wallet.Wallet, error) {
+	config, encrypted, err := loadConfig(homeDir, network, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !encrypted && passphrase != "" {
+		if err := writeKeystore(keystorePath(homeDir, network), config, passphrase); err != nil {
+			return nil, fmt.Errorf("cannot encrypt keystore: %v", err)
+		}
+	}
+	if len(config.ExtendedPublicKeys) > 0 {
+		return wallet.NewWatchOnly(config)
+	}
+	if config.Signer != "" {
+		return wallet.NewWithSigner(config, signer.NewRemote(config.Signer)), nil
+	}
+	return wallet.New(config), nil
+}
+
+// WatchOnly returns the watch-only keystore of the wallet of the password, in
+// the keystore of the network. It holds the extended public keys of the wallet
+// instead of the mnemonic.
+func WatchOnly(homeDir, network, passphrase, password string) ([]byte, error) {
+	config, _, err := loadConfig(homeDir, network, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := hasMnemonic(config, network); err != nil {
+		return nil, err
+	}
+	watchOnly, err := wallet.WatchOnlyConfig(config, password)
 	if err != nil {
 		return nil, err
 	}
-	config := wallet.Config{}
-	if err := json.Unmarshal(data, &config); err != nil {
+	return json.MarshalIndent(watchOnly, "", "    ")
+}
+
+// Mnemonic returns the mnemonic in the keystore of the network.
+func Mnemonic(homeDir, network, passphrase string) (string, error) {
+	config, _, err := loadConfig(homeDir, network, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if err := hasMnemonic(config, network); err != nil {
+		return "", err
+	}
+	return config.Mnemonic, nil
+}
+
+// WalletWithMnemonic returns the wallet of the keystore of the network with its
+// mnemonic replaced, without changing the keystore. It is used to move funds to
+// the new mnemonic before it replaces the current one.
+func WalletWithMnemonic(homeDir, network, mnemonic, passphrase string) (wallet.Wallet, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	config, _, err := loadConfig(homeDir, network, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := hasMnemonic(config, network); err != nil {
 		return nil, err
 	}
+	config.Mnemonic = mnemonic
 	return wallet.New(config), nil
 }
 
-func Generate(homeDir, network, mnemonic string) error {
+// Replace replaces the mnemonic in the keystore of the network, and keeps the
+// rest of its config. The previous keystore is moved to a backup file, and the
+// path of the backup is returned.
+func Replace(homeDir, network, mnemonic, passphrase string) (string, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return "", ErrInvalidMnemonic
+	}
+	config, _, err := loadConfig(homeDir, network, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if err := hasMnemonic(config, network); err != nil {
+		return "", err
+	}
+	config.Mnemonic = mnemonic
+
+	keystore := keystorePath(homeDir, network)
+	data, err := ioutil.ReadFile(keystore)
+	if err != nil {
+		return "", err
+	}
+	backup := path.Join(homeDir, fmt.Sprintf("%s-%d.json.bak", network, time.Now().Unix()))
+	if err := ioutil.WriteFile(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("cannot backup keystore: %v", err)
+	}
+	if passphrase != "" {
+		return backup, writeKeystore(keystore, config, passphrase)
+	}
+	data, err = json.Marshal(config)
+	if err != nil {
+		return backup, err
+	}
+	return backup, writeFile(keystore, data)
+}
+
+// loadConfig loads the config in the keystore of the network, and returns
+// whether the keystore is encrypted.
+func loadConfig(homeDir, network, passphrase string) (wallet.Config, bool, error) {
+	path := keystorePath(homeDir, network)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return wallet.Config{}, false, err
+	}
+	file := keystoreFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return wallet.Config{}, false, err
+	}
+
+	switch file.Version {
+	case 0:
+		config := wallet.Config{}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return wallet.Config{}, false, err
+		}
+		return config, false, nil
+	case Version:
+		if passphrase == "" {
+			return wallet.Config{}, true, fmt.Errorf("keystore %s is encrypted, a passphrase is required", path)
+		}
+		mnemonic, err := file.Crypto.decrypt(passphrase)
+		if err != nil {
+			return wallet.Config{}, true, err
+		}
+		file.Config.Mnemonic = mnemonic
+		return file.Config, true, nil
+	default:
+		return wallet.Config{}, false, fmt.Errorf("unsupported keystore version %d", file.Version)
+	}
+}
+
+// hasMnemonic returns an error if the keys of the keystore are not derived
+// from its mnemonic.
+func hasMnemonic(config wallet.Config, network string) error {
+	if config.Signer != "" {
+		return fmt.Errorf("the keys of the %s keystore are held by the signer at %s", network, config.Signer)
+	}
+	if len(config.ExtendedPublicKeys) > 0 {
+		return fmt.Errorf("the %s keystore is watch-only", network)
+	}
+	return nil
+}
+
+// Encrypted returns true if the keystore of the network is encrypted.
+func Encrypted(homeDir, network string) (bool, error) {
+	data, err := ioutil.ReadFile(keystorePath(homeDir, network))
+	if err != nil {
+		return false, err
+	}
+	file := keystoreFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return false, err
+	}
+	return file.Version != 0, nil
+}
+
+// Exists returns true if the keystore of the network exists.
+func Exists(homeDir, network string) bool {
+	_, err := os.Stat(keystorePath(homeDir, network))
+	return err == nil
+}
+
+// Generate writes the keystore of the network. The mnemonic is encrypted with
+// the passphrase, unless the passphrase is empty.
+func Generate(homeDir, network, mnemonic, passphrase string) error {
 	network = strings.ToLower(network)
 	path := keystorePath(homeDir, network)
 	config, err := generateConfig(network, mnemonic)
 	if err != nil {
 		return err
 	}
+	if passphrase != "" {
+		return writeKeystore(path, config, passphrase)
+	}
 	data, err := json.Marshal(config)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(path, data, 0644)
+	return writeFile(path, data)
 }
 
 func generateConfig(network, mnemonic string) (wallet.Config, error) {
@@ -52,6 +234,29 @@ func generateConfig(network, mnemonic string) (wallet.Config, error) {
 	return config, nil
 }
 
+func writeKeystore(path string, config wallet.Config, passphrase string) error {
+	crypto, err := encryptMnemonic(config.Mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	config.Mnemonic = ""
+	data, err := json.MarshalIndent(keystoreFile{Version, crypto, config}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// writeFile replaces the file, so that the keystore is never left half
+// written. The file can only be read by its owner.
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 func keystorePath(homeDir, network string) string {
 	return path.Join(homeDir, fmt.Sprintf("%s.json", network))
 }
@@ -0,0 +1,71 @@
+package keystore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// The environment variables that the keystore passphrase is read from. The
+// file descriptor is read up to the first new line.
+const (
+	PassphraseEnv   = "SWAPPERD_PASSPHRASE"
+	PassphraseFDEnv = "SWAPPERD_PASSPHRASE_FD"
+)
+
+var (
+	passphraseOnce = new(sync.Once)
+	passphrase     string
+	passphraseErr  error
+)
+
+// Passphrase returns the passphrase of the keystores. It is read from the
+// environment, from a file descriptor, or prompted for when stdin is a
+// terminal, in that order. The passphrase is empty when none of them is
+// available. It is only read once, and shared by all networks.
+func Passphrase() (string, error) {
+	passphraseOnce.Do(func() {
+		passphrase, passphraseErr = readPassphrase()
+	})
+	return passphrase, passphraseErr
+}
+
+func readPassphrase() (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if fdString := os.Getenv(PassphraseFDEnv); fdString != "" {
+		fd, err := strconv.Atoi(fdString)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %v", PassphraseFDEnv, err)
+		}
+		file := os.NewFile(uintptr(fd), "passphrase")
+		if file == nil {
+			return "", fmt.Errorf("invalid %s: %d", PassphraseFDEnv, fd)
+		}
+		defer file.Close()
+		line, err := bufio.NewReader(file).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("cannot read passphrase: %v", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, "Keystore passphrase: ")
+		data, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("cannot read passphrase: %v", err)
+		}
+		return string(data), nil
+	}
+	return "", nil
+}
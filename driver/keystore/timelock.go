@@ -0,0 +1,34 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/renproject/swapperd/foundation/blockchain"
+	"github.com/renproject/swapperd/foundation/swap"
+)
+
+// TimeLockPolicies loads the timelock policies of the network, and returns an
+// error if the policy of any pair of the registered tokens is not safe. The
+// default policy is used for all token pairs when the file does not exist.
+func TimeLockPolicies(homeDir, network string) (swap.TimeLockPolicies, error) {
+	policies := swap.TimeLockPolicies{}
+	data, err := ioutil.ReadFile(timeLockPoliciesPath(homeDir, network))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return policies, nil
+		}
+		return policies, err
+	}
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return policies, err
+	}
+	return policies, policies.Validate(blockchain.Tokens())
+}
+
+func timeLockPoliciesPath(homeDir, network string) string {
+	return path.Join(homeDir, fmt.Sprintf("%s-timelocks.json", network))
+}
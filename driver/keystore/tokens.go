@@ -0,0 +1,31 @@
+package keystore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+// Tokens loads the entries of the token registry file. Only the default tokens
+// are supported when the file does not exist.
+func Tokens(homeDir string) ([]blockchain.TokenEntry, error) {
+	entries := []blockchain.TokenEntry{}
+	data, err := ioutil.ReadFile(tokensPath(homeDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return entries, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return entries, err
+	}
+	return entries, nil
+}
+
+func tokensPath(homeDir string) string {
+	return path.Join(homeDir, "tokens.json")
+}
@@ -0,0 +1,32 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/renproject/swapperd/adapter/webhook"
+)
+
+// WebhookConfig loads the daemon-wide webhook config of the network. Webhooks
+// are disabled when the config file does not exist.
+func WebhookConfig(homeDir, network string) (webhook.Config, error) {
+	config := webhook.Config{}
+	data, err := ioutil.ReadFile(webhookConfigPath(homeDir, network))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+func webhookConfigPath(homeDir, network string) string {
+	return path.Join(homeDir, fmt.Sprintf("%s-webhook.json", network))
+}
@@ -1,11 +1,24 @@
 package leveldb
 
 import (
+	"os"
 	"path"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 )
 
 func NewStore(homeDir, network string) (*leveldb.DB, error) {
 	return leveldb.OpenFile(path.Join(homeDir, "db", network), nil)
 }
+
+// NewReadOnlyStore opens the store of the network without writing to it. It
+// fails while the store is open elsewhere, and returns an error that satisfies
+// os.IsNotExist if the store has never been created.
+func NewReadOnlyStore(homeDir, network string) (*leveldb.DB, error) {
+	dir := path.Join(homeDir, "db", network)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	return leveldb.OpenFile(dir, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+}
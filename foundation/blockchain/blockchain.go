@@ -9,18 +9,68 @@ func NewErrUnsupportedBlockchain(blockchain BlockchainName) error {
 type BlockchainName string
 
 var (
-	Bitcoin  = BlockchainName("bitcoin")
-	Ethereum = BlockchainName("ethereum")
-	ERC20    = BlockchainName("erc20")
+	Bitcoin     = BlockchainName("bitcoin")
+	Ethereum    = BlockchainName("ethereum")
+	ERC20       = BlockchainName("erc20")
+	Litecoin    = BlockchainName("litecoin")
+	BitcoinCash = BlockchainName("bitcoincash")
 )
 
+// IsUTXO returns true if the blockchain is a bitcoin-like UTXO blockchain that
+// is supported by the generic UTXO binder.
+func (name BlockchainName) IsUTXO() bool {
+	return name == Litecoin || name == BitcoinCash
+}
+
+// HasAddressIndices returns true if swaps on the blockchain receive funds at
+// their own address index. Ethereum accounts pay for the gas of their
+// transactions, so they only use the first address.
+func (name BlockchainName) HasAddressIndices() bool {
+	return name == Bitcoin || name.IsUTXO()
+}
+
+// ConfirmationTime returns the expected time, in seconds, for a transaction on
+// the blockchain to be confirmed with enough blocks to be considered final.
+func (name BlockchainName) ConfirmationTime() int64 {
+	switch name {
+	case Bitcoin, BitcoinCash:
+		return 6 * 10 * 60
+	case Litecoin:
+		return 6 * 150
+	case Ethereum, ERC20:
+		return 12 * 15
+	default:
+		return 0
+	}
+}
+
+// DefaultConfirmations returns the number of confirmations a swap transaction
+// on the blockchain needs before it is trusted, when no other number has been
+// configured.
+func (name BlockchainName) DefaultConfirmations() int64 {
+	switch name {
+	case Bitcoin, BitcoinCash:
+		return 2
+	case Litecoin:
+		return 6
+	case Ethereum, ERC20:
+		return 12
+	default:
+		return 1
+	}
+}
+
 type Blockchain struct {
 	Name    BlockchainName `json:"name"`
 	Address string         `json:"address"`
 }
 
+// A Balance is the amount of a token at an address. Received is the amount that
+// swaps have received at the other addresses of the wallet, which cannot be
+// spent from the address until it is moved there.
 type Balance struct {
 	Address  string `json:"address"`
 	Decimals int    `json:"decimals"`
 	Amount   string `json:"balance"`
+	Received string `json:"received,omitempty"`
 }
@@ -35,6 +35,14 @@ var (
 	BitcoinTransactionCost = map[TokenName]*big.Int{
 		BTC: big.NewInt(10000),
 	}
+
+	LitecoinTransactionCost = map[TokenName]*big.Int{
+		LTC: big.NewInt(100000),
+	}
+
+	BitcoinCashTransactionCost = map[TokenName]*big.Int{
+		BCH: big.NewInt(1000),
+	}
 )
 
 func (token Token) TransactionCost(amount *big.Int) (Cost, error) {
@@ -45,6 +53,10 @@ func (token Token) TransactionCost(amount *big.Int) (Cost, error) {
 		return EthereumTransactionCost, nil
 	case Bitcoin:
 		return BitcoinTransactionCost, nil
+	case Litecoin:
+		return LitecoinTransactionCost, nil
+	case BitcoinCash:
+		return BitcoinCashTransactionCost, nil
 	default:
 		return nil, NewErrUnsupportedToken(token.Name)
 	}
@@ -56,6 +68,10 @@ func (token Token) BlockchainTxFees() (*big.Int, error) {
 		return big.NewInt(12000000000), nil
 	case Bitcoin:
 		return big.NewInt(10000), nil
+	case Litecoin:
+		return big.NewInt(100000), nil
+	case BitcoinCash:
+		return big.NewInt(1000), nil
 	default:
 		return nil, NewErrUnsupportedToken(token.Name)
 	}
@@ -64,7 +80,7 @@ func (token Token) BlockchainTxFees() (*big.Int, error) {
 func erc20TransactionCost(token Token, amount *big.Int) Cost {
 	switch token {
 	case TokenDGX:
-		cost := EthereumTransactionCost
+		cost := Cost{ETH: EthereumTransactionCost[ETH]}
 		cost[DGX] = calculateFeesFromBips(amount, 13)
 		return cost
 	default:
@@ -75,3 +91,18 @@ func erc20TransactionCost(token Token, amount *big.Int) Cost {
 func calculateFeesFromBips(value *big.Int, bips int64) *big.Int {
 	return new(big.Int).Div(new(big.Int).Mul(value, big.NewInt(bips)), new(big.Int).Sub(big.NewInt(10000), big.NewInt(bips)))
 }
+
+// BlockchainFeeRate returns the default fee rate (in satoshi per vbyte) of the
+// transactions of a bitcoin-like blockchain.
+func (token Token) BlockchainFeeRate() (int64, error) {
+	switch token.Blockchain {
+	case Bitcoin:
+		return 30, nil
+	case Litecoin:
+		return 300, nil
+	case BitcoinCash:
+		return 3, nil
+	default:
+		return 0, NewErrUnsupportedToken(token.Name)
+	}
+}
@@ -0,0 +1,265 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// EthereumTransferGas is the gas used by an ether transfer.
+	EthereumTransferGas = int64(21000)
+	// ERC20TransferGas is the gas limit of an ERC20 transfer.
+	ERC20TransferGas = int64(100000)
+)
+
+// A FeeEstimator estimates the fees of transactions.
+type FeeEstimator interface {
+	// TxFee returns the fee used by the swap transactions of the token. It is
+	// the gas price (in wei) for ethereum and erc20 tokens, and the default
+	// fee (in satoshi) for bitcoin-like blockchains.
+	TxFee(token Token) (*big.Int, error)
+
+	// FeeRate returns the fee rate (in satoshi per vbyte) of the transactions
+	// of a bitcoin-like blockchain. The fees of these transactions depend on
+	// their size, so they are sized from the transactions that pay them.
+	FeeRate(token Token) (int64, error)
+
+	// TransactionCost returns the cost of transferring the amount of the
+	// token. Bitcoin-like blockchains return their default cost.
+	TransactionCost(token Token, amount *big.Int) (Cost, error)
+
+	// BatchTransactionCost returns the cost of transferring the amounts of the
+	// token to several recipients. Bitcoin-like blockchains pay every
+	// recipient in one transaction, and ethereum pays each recipient in its
+	// own transaction.
+	BatchTransactionCost(token Token, amounts []*big.Int) (Cost, error)
+}
+
+// A GasPriceSource suggests the gas price of ethereum transactions (in wei).
+type GasPriceSource interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// A FeeRateSource suggests the fee rate of bitcoin transactions (in satoshi per
+// vbyte).
+type FeeRateSource interface {
+	FeeRate(ctx context.Context) (int64, error)
+}
+
+type staticFeeEstimator struct {
+}
+
+// NewStaticFeeEstimator returns a FeeEstimator that always returns the default
+// fees.
+func NewStaticFeeEstimator() FeeEstimator {
+	return staticFeeEstimator{}
+}
+
+func (staticFeeEstimator) TxFee(token Token) (*big.Int, error) {
+	return token.BlockchainTxFees()
+}
+
+func (staticFeeEstimator) FeeRate(token Token) (int64, error) {
+	return token.BlockchainFeeRate()
+}
+
+func (staticFeeEstimator) TransactionCost(token Token, amount *big.Int) (Cost, error) {
+	cost, err := token.TransactionCost(amount)
+	if err != nil {
+		return nil, err
+	}
+	return copyCost(cost), nil
+}
+
+func (estimator staticFeeEstimator) BatchTransactionCost(token Token, amounts []*big.Int) (Cost, error) {
+	return batchTransactionCost(estimator, token, amounts)
+}
+
+type feeEstimator struct {
+	gasPrice GasPriceSource
+	feeRate  FeeRateSource
+	fallback FeeEstimator
+	timeout  time.Duration
+}
+
+// NewFeeEstimator returns a FeeEstimator that queries the current gas price and
+// fee rate, and uses the default fees when they are not available.
+func NewFeeEstimator(gasPrice GasPriceSource, feeRate FeeRateSource) FeeEstimator {
+	return &feeEstimator{
+		gasPrice: gasPrice,
+		feeRate:  feeRate,
+		fallback: NewStaticFeeEstimator(),
+		timeout:  10 * time.Second,
+	}
+}
+
+func (estimator *feeEstimator) TxFee(token Token) (*big.Int, error) {
+	switch token.Blockchain {
+	case Ethereum, ERC20:
+		gasPrice, err := estimator.suggestGasPrice()
+		if err != nil {
+			return estimator.fallback.TxFee(token)
+		}
+		return gasPrice, nil
+	case Bitcoin, Litecoin, BitcoinCash:
+		return estimator.fallback.TxFee(token)
+	default:
+		return nil, NewErrUnsupportedToken(token.Name)
+	}
+}
+
+func (estimator *feeEstimator) FeeRate(token Token) (int64, error) {
+	switch token.Blockchain {
+	case Bitcoin:
+		feeRate, err := estimator.suggestFeeRate()
+		if err != nil {
+			return estimator.fallback.FeeRate(token)
+		}
+		return feeRate, nil
+	default:
+		return estimator.fallback.FeeRate(token)
+	}
+}
+
+func (estimator *feeEstimator) TransactionCost(token Token, amount *big.Int) (Cost, error) {
+	switch token.Blockchain {
+	case Ethereum:
+		gasPrice, err := estimator.suggestGasPrice()
+		if err != nil {
+			return estimator.fallback.TransactionCost(token, amount)
+		}
+		return Cost{ETH: new(big.Int).Mul(gasPrice, big.NewInt(EthereumTransferGas))}, nil
+	case ERC20:
+		gasPrice, err := estimator.suggestGasPrice()
+		if err != nil {
+			return estimator.fallback.TransactionCost(token, amount)
+		}
+		cost := Cost{ETH: new(big.Int).Mul(gasPrice, big.NewInt(ERC20TransferGas))}
+		if token == TokenDGX {
+			cost[DGX] = calculateFeesFromBips(amount, 13)
+		}
+		return cost, nil
+	case Bitcoin, Litecoin, BitcoinCash:
+		return estimator.fallback.TransactionCost(token, amount)
+	default:
+		return nil, NewErrUnsupportedToken(token.Name)
+	}
+}
+
+func (estimator *feeEstimator) BatchTransactionCost(token Token, amounts []*big.Int) (Cost, error) {
+	return batchTransactionCost(estimator, token, amounts)
+}
+
+// batchTransactionCost returns the sum of the costs of transferring each
+// amount on ethereum, and the cost of transferring the total amount on other
+// blockchains.
+func batchTransactionCost(estimator FeeEstimator, token Token, amounts []*big.Int) (Cost, error) {
+	if token.Blockchain != Ethereum && token.Blockchain != ERC20 {
+		total := big.NewInt(0)
+		for _, amount := range amounts {
+			total.Add(total, amount)
+		}
+		return estimator.TransactionCost(token, total)
+	}
+	batchCost := Cost{}
+	for _, amount := range amounts {
+		cost, err := estimator.TransactionCost(token, amount)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range cost {
+			if batchCost[name] == nil {
+				batchCost[name] = big.NewInt(0)
+			}
+			batchCost[name].Add(batchCost[name], value)
+		}
+	}
+	return batchCost, nil
+}
+
+func (estimator *feeEstimator) suggestGasPrice() (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), estimator.timeout)
+	defer cancel()
+	gasPrice, err := estimator.gasPrice.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if gasPrice == nil || gasPrice.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid gas price: %v", gasPrice)
+	}
+	return gasPrice, nil
+}
+
+func (estimator *feeEstimator) suggestFeeRate() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), estimator.timeout)
+	defer cancel()
+	feeRate, err := estimator.feeRate.FeeRate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if feeRate <= 0 {
+		return 0, fmt.Errorf("invalid fee rate: %d", feeRate)
+	}
+	return feeRate, nil
+}
+
+type esploraFeeRateSource struct {
+	url    string
+	target int
+}
+
+// NewEsploraFeeRateSource returns a FeeRateSource that reads the fee rate
+// required to confirm within the target number of blocks from an Esplora
+// server, such as https://blockstream.info/api.
+func NewEsploraFeeRateSource(url string, target int) FeeRateSource {
+	return &esploraFeeRateSource{url, target}
+}
+
+func (source *esploraFeeRateSource) FeeRate(ctx context.Context) (int64, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/fee-estimates", source.url), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code=%v from %s", resp.StatusCode, source.url)
+	}
+
+	// Estimates are keyed by the number of blocks to confirm within.
+	estimates := map[string]float64{}
+	if err := json.NewDecoder(resp.Body).Decode(&estimates); err != nil {
+		return 0, err
+	}
+	bestTarget, feeRate := 0, 0.0
+	for key, rate := range estimates {
+		target, err := strconv.Atoi(key)
+		if err != nil || target > source.target {
+			continue
+		}
+		if target > bestTarget {
+			bestTarget, feeRate = target, rate
+		}
+	}
+	if bestTarget == 0 {
+		return 0, fmt.Errorf("no fee estimate for %d blocks", source.target)
+	}
+	return int64(math.Ceil(feeRate)), nil
+}
+
+func copyCost(cost Cost) Cost {
+	copied := Cost{}
+	for token, amount := range cost {
+		copied[token] = new(big.Int).Set(amount)
+	}
+	return copied
+}
@@ -0,0 +1,133 @@
+package blockchain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/foundation/blockchain"
+)
+
+type stubGasPriceSource struct {
+	gasPrice *big.Int
+	err      error
+}
+
+func (source stubGasPriceSource) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return source.gasPrice, source.err
+}
+
+type stubFeeRateSource struct {
+	feeRate int64
+	err     error
+}
+
+func (source stubFeeRateSource) FeeRate(ctx context.Context) (int64, error) {
+	return source.feeRate, source.err
+}
+
+var _ = Describe("Fee Estimator", func() {
+	errUnavailable := errors.New("unavailable")
+
+	Context("when the backends are available", func() {
+		estimator := NewFeeEstimator(stubGasPriceSource{big.NewInt(20000000000), nil}, stubFeeRateSource{15, nil})
+
+		It("should use the suggested gas price for ethereum swaps", func() {
+			fee, err := estimator.TxFee(TokenWBTC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fee.Int64()).Should(Equal(int64(20000000000)))
+		})
+
+		It("should use the suggested fee rate for bitcoin", func() {
+			feeRate, err := estimator.FeeRate(TokenBTC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(feeRate).Should(Equal(int64(15)))
+
+			feeRate, err = estimator.FeeRate(TokenLTC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(feeRate).Should(Equal(int64(300)))
+
+			_, err = estimator.FeeRate(TokenETH)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should estimate the cost of transfers", func() {
+			cost, err := estimator.TransactionCost(TokenETH, big.NewInt(1))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(cost[ETH].Int64()).Should(Equal(20000000000 * EthereumTransferGas))
+
+			cost, err = estimator.TransactionCost(TokenDGX, big.NewInt(1000000000))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(cost[ETH].Int64()).Should(Equal(20000000000 * ERC20TransferGas))
+			Expect(cost[DGX].Int64()).Should(Equal(int64(1301692)))
+		})
+
+		It("should estimate the cost of batch transfers", func() {
+			amounts := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+			cost, err := estimator.BatchTransactionCost(TokenETH, amounts)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(cost[ETH].Int64()).Should(Equal(3 * 20000000000 * EthereumTransferGas))
+		})
+	})
+
+	Context("when the backends are unavailable", func() {
+		estimator := NewFeeEstimator(stubGasPriceSource{nil, errUnavailable}, stubFeeRateSource{0, errUnavailable})
+		static := NewStaticFeeEstimator()
+
+		It("should fallback to the static fees", func() {
+			for _, token := range []Token{TokenETH, TokenWBTC, TokenBTC} {
+				fee, err := estimator.TxFee(token)
+				Expect(err).ShouldNot(HaveOccurred())
+				staticFee, err := static.TxFee(token)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(fee.Cmp(staticFee)).Should(Equal(0))
+
+				cost, err := estimator.TransactionCost(token, big.NewInt(1))
+				Expect(err).ShouldNot(HaveOccurred())
+				staticCost, err := static.TransactionCost(token, big.NewInt(1))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(cost).Should(Equal(staticCost))
+			}
+
+			feeRate, err := estimator.FeeRate(TokenBTC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(static.FeeRate(TokenBTC)).Should(Equal(feeRate))
+		})
+
+		It("should fallback to the static fees when the estimate is not positive", func() {
+			estimator := NewFeeEstimator(stubGasPriceSource{big.NewInt(0), nil}, stubFeeRateSource{0, nil})
+			feeRate, err := estimator.FeeRate(TokenBTC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(feeRate).Should(Equal(int64(30)))
+		})
+	})
+
+	Context("when reading fee rates from esplora", func() {
+		It("should use the estimate of the closest target", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).Should(Equal("/fee-estimates"))
+				fmt.Fprint(w, `{"1": 30.2, "2": 20.5, "6": 5.1, "144": 1.0}`)
+			}))
+			defer server.Close()
+
+			feeRate, err := NewEsploraFeeRateSource(server.URL, 3).FeeRate(context.Background())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(feeRate).Should(Equal(int64(21)))
+		})
+
+		It("should return an error when the server fails", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			_, err := NewEsploraFeeRateSource(server.URL, 3).FeeRate(context.Background())
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 // ErrUnsupportedToken is returned when the token is not supported by swapperd.
@@ -30,6 +31,8 @@ var (
 	USDC = TokenName("USDC")
 	GUSD = TokenName("GUSD")
 	TUSD = TokenName("TUSD")
+	LTC  = TokenName("LTC")
+	BCH  = TokenName("BCH")
 )
 
 // Token represents the token we are trading.
@@ -52,11 +55,15 @@ var (
 	TokenUSDC = Token{USDC, 6, ERC20}
 	TokenGUSD = Token{GUSD, 2, ERC20}
 	TokenPAX  = Token{PAX, 18, ERC20}
+	TokenLTC  = Token{LTC, 8, Litecoin}
+	TokenBCH  = Token{BCH, 8, BitcoinCash}
 )
 
+// SupportedTokens are the tokens that are supported without a token registry
+// file.
 var SupportedTokens = []Token{
 	TokenBTC, TokenETH, TokenWBTC, TokenREN, TokenDGX, TokenZRX, TokenOMG,
-	TokenTUSD, TokenDAI, TokenUSDC, TokenGUSD, TokenPAX,
+	TokenTUSD, TokenDAI, TokenUSDC, TokenGUSD, TokenPAX, TokenLTC, TokenBCH,
 }
 
 func (token Token) String() string {
@@ -67,34 +74,187 @@ func (Token) Generate(rand *rand.Rand, size int) reflect.Value {
 	return reflect.ValueOf(SupportedTokens[rand.Int()%len(SupportedTokens)])
 }
 
+// PatchToken returns the registered token with the given name or alias.
 func PatchToken(token string) (Token, error) {
-	token = strings.TrimSpace(strings.ToLower(token))
-	switch token {
-	case "bitcoin", "btc", "xbt":
-		return TokenBTC, nil
-	case "wrappedbtc", "wbtc", "wrappedbitcoin":
-		return TokenWBTC, nil
-	case "ethereum", "eth", "ether":
-		return TokenETH, nil
-	case "ren", "republictoken", "republic token":
-		return TokenREN, nil
-	case "tusd", "trueusd", "true-usd":
-		return TokenTUSD, nil
-	case "digix-gold-token", "dgx", "dgt":
-		return TokenDGX, nil
-	case "zerox", "zrx", "0x":
-		return TokenZRX, nil
-	case "omisego", "omg", "omise-go":
-		return TokenOMG, nil
-	case "usdc", "usd-coin", "usdcoin":
-		return TokenUSDC, nil
-	case "dai", "maker-dai", "makerdai":
-		return TokenDAI, nil
-	case "gusd", "gemini-dollar", "geminidollar":
-		return TokenGUSD, nil
-	case "pax", "paxosstandardtoken", "paxos-standard-token":
-		return TokenPAX, nil
-	default:
+	return registry.patch(token)
+}
+
+// Tokens returns the tokens in the token registry.
+func Tokens() []Token {
+	return registry.tokens()
+}
+
+// RegisteredTokens returns the entries of the token registry.
+func RegisteredTokens() []TokenEntry {
+	return registry.tokenEntries()
+}
+
+// RegisterTokens adds the entries to the token registry, replacing the
+// registered tokens with the same name.
+func RegisterTokens(entries []TokenEntry) error {
+	return registry.register(entries)
+}
+
+// TokenContractAddresses returns the contract addresses of the token on the
+// ethereum network, if they are in the token registry.
+func TokenContractAddresses(token TokenName, network string) (TokenContracts, bool) {
+	return registry.contracts(token, network)
+}
+
+// A TokenEntry describes a token in the token registry.
+type TokenEntry struct {
+	Token
+	Aliases []string `json:"aliases"`
+
+	// Contracts are the addresses of the token contracts, keyed by the name of
+	// the ethereum network. Tokens without an address for the network use the
+	// addresses known to swapperd.
+	Contracts map[string]TokenContracts `json:"contracts,omitempty"`
+}
+
+// TokenContracts are the addresses of the contracts of an ERC20 token.
+type TokenContracts struct {
+	Token   string `json:"token"`
+	Swapper string `json:"swapper,omitempty"`
+}
+
+var DefaultTokenEntries = []TokenEntry{
+	{Token: TokenBTC, Aliases: []string{"bitcoin", "btc", "xbt"}},
+	{Token: TokenETH, Aliases: []string{"ethereum", "eth", "ether"}},
+	{Token: TokenWBTC, Aliases: []string{"wrappedbtc", "wbtc", "wrappedbitcoin"}},
+	{Token: TokenREN, Aliases: []string{"ren", "republictoken", "republic token"}},
+	{Token: TokenDGX, Aliases: []string{"digix-gold-token", "dgx", "dgt"}},
+	{Token: TokenZRX, Aliases: []string{"zerox", "zrx", "0x"}},
+	{Token: TokenOMG, Aliases: []string{"omisego", "omg", "omise-go"}},
+	{Token: TokenTUSD, Aliases: []string{"tusd", "trueusd", "true-usd"}},
+	{Token: TokenDAI, Aliases: []string{"dai", "maker-dai", "makerdai"}},
+	{Token: TokenUSDC, Aliases: []string{"usdc", "usd-coin", "usdcoin"}},
+	{Token: TokenGUSD, Aliases: []string{"gusd", "gemini-dollar", "geminidollar"}},
+	{Token: TokenPAX, Aliases: []string{"pax", "paxosstandardtoken", "paxos-standard-token"}},
+	{Token: TokenLTC, Aliases: []string{"litecoin", "ltc"}},
+	{Token: TokenBCH, Aliases: []string{"bitcoincash", "bitcoin-cash", "bch"}},
+}
+
+var registry = newTokenRegistry(DefaultTokenEntries)
+
+type tokenRegistry struct {
+	mu      *sync.RWMutex
+	order   []TokenName
+	entries map[TokenName]TokenEntry
+	aliases map[string]TokenName
+}
+
+func newTokenRegistry(entries []TokenEntry) *tokenRegistry {
+	registry := &tokenRegistry{
+		mu:      new(sync.RWMutex),
+		order:   []TokenName{},
+		entries: map[TokenName]TokenEntry{},
+		aliases: map[string]TokenName{},
+	}
+	if err := registry.register(entries); err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+func (registry *tokenRegistry) patch(token string) (Token, error) {
+	token = normalizeAlias(token)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	name, ok := registry.aliases[token]
+	if !ok {
 		return Token{}, NewErrUnsupportedToken(TokenName(token))
 	}
+	return registry.entries[name].Token, nil
+}
+
+func (registry *tokenRegistry) tokens() []Token {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	tokens := make([]Token, 0, len(registry.order))
+	for _, name := range registry.order {
+		tokens = append(tokens, registry.entries[name].Token)
+	}
+	return tokens
+}
+
+func (registry *tokenRegistry) tokenEntries() []TokenEntry {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	entries := make([]TokenEntry, 0, len(registry.order))
+	for _, name := range registry.order {
+		entries = append(entries, registry.entries[name])
+	}
+	return entries
+}
+
+func (registry *tokenRegistry) contracts(token TokenName, network string) (TokenContracts, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	contracts, ok := registry.entries[token].Contracts[network]
+	return contracts, ok && contracts.Token != ""
+}
+
+func (registry *tokenRegistry) register(entries []TokenEntry) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	// Entries are validated before any of them are registered, so that an
+	// invalid registry file does not leave the registry half updated.
+	aliases := map[string]TokenName{}
+	for alias, name := range registry.aliases {
+		aliases[alias] = name
+	}
+	for _, entry := range entries {
+		if err := validateTokenEntry(entry); err != nil {
+			return err
+		}
+		for alias, name := range aliases {
+			if name == entry.Name {
+				delete(aliases, alias)
+			}
+		}
+	}
+	for _, entry := range entries {
+		for _, alias := range append([]string{string(entry.Name)}, entry.Aliases...) {
+			alias = normalizeAlias(alias)
+			if name, ok := aliases[alias]; ok && name != entry.Name {
+				return fmt.Errorf("token alias %s is used by both %s and %s", alias, name, entry.Name)
+			}
+			aliases[alias] = entry.Name
+		}
+	}
+
+	for _, entry := range entries {
+		if _, ok := registry.entries[entry.Name]; !ok {
+			registry.order = append(registry.order, entry.Name)
+		}
+		registry.entries[entry.Name] = entry
+	}
+	registry.aliases = aliases
+	return nil
+}
+
+func validateTokenEntry(entry TokenEntry) error {
+	if entry.Name == "" {
+		return fmt.Errorf("token name cannot be empty")
+	}
+	if entry.Decimals < 0 {
+		return fmt.Errorf("invalid decimals for token %s: %d", entry.Name, entry.Decimals)
+	}
+	switch entry.Blockchain {
+	case Bitcoin, Ethereum, ERC20, Litecoin, BitcoinCash:
+		return nil
+	default:
+		return fmt.Errorf("invalid blockchain for token %s: %v", entry.Name, NewErrUnsupportedBlockchain(entry.Blockchain))
+	}
+}
+
+func normalizeAlias(alias string) string {
+	return strings.TrimSpace(strings.ToLower(alias))
 }
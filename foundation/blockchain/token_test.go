@@ -0,0 +1,78 @@
+package blockchain_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/foundation/blockchain"
+)
+
+var _ = Describe("Token Registry", func() {
+	Context("when patching token names", func() {
+		It("should resolve the default tokens by their aliases", func() {
+			for _, entry := range DefaultTokenEntries {
+				for _, alias := range entry.Aliases {
+					token, err := PatchToken(alias)
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(token).Should(Equal(entry.Token))
+				}
+			}
+
+			token, err := PatchToken("  Bitcoin ")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(token).Should(Equal(TokenBTC))
+		})
+
+		It("should return an error for unknown tokens", func() {
+			_, err := PatchToken("unknown-token")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when registering tokens", func() {
+		It("should add new ERC20 tokens with their contract addresses", func() {
+			entry := TokenEntry{
+				Token:   Token{"LINK", 18, ERC20},
+				Aliases: []string{"chainlink"},
+				Contracts: map[string]TokenContracts{
+					"kovan": {Token: "0xa36085F69e2889c224210F603D836748e7dC0088"},
+				},
+			}
+			Expect(RegisterTokens([]TokenEntry{entry})).ShouldNot(HaveOccurred())
+
+			token, err := PatchToken("ChainLink")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(token).Should(Equal(entry.Token))
+			Expect(Tokens()).Should(ContainElement(entry.Token))
+
+			contracts, ok := TokenContractAddresses("LINK", "kovan")
+			Expect(ok).Should(BeTrue())
+			Expect(contracts.Token).Should(Equal("0xa36085F69e2889c224210F603D836748e7dC0088"))
+			_, ok = TokenContractAddresses("LINK", "mainnet")
+			Expect(ok).Should(BeFalse())
+		})
+
+		It("should replace registered tokens with the same name", func() {
+			defer RegisterTokens(DefaultTokenEntries)
+			entry := TokenEntry{Token: Token{"GUSD", 2, ERC20}, Aliases: []string{"gemini"}}
+			Expect(RegisterTokens([]TokenEntry{entry})).ShouldNot(HaveOccurred())
+
+			token, err := PatchToken("gemini")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(token).Should(Equal(TokenGUSD))
+			_, err = PatchToken("gemini-dollar")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should reject aliases that are used by other tokens", func() {
+			entry := TokenEntry{Token: Token{"FAKE", 8, ERC20}, Aliases: []string{"btc"}}
+			Expect(RegisterTokens([]TokenEntry{entry})).Should(HaveOccurred())
+			_, err := PatchToken("fake")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should reject tokens on unknown blockchains", func() {
+			entry := TokenEntry{Token: Token{"DOGE", 8, BlockchainName("dogecoin")}}
+			Expect(RegisterTokens([]TokenEntry{entry})).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package swap
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+)
+
+const (
+	// StallTimeout is the number of seconds that a transaction can remain
+	// unconfirmed before its fee is bumped.
+	StallTimeout = int64(15 * 60)
+	// UrgentStallTimeout replaces the StallTimeout when the timelock is less
+	// than an ExpiryUnit away.
+	UrgentStallTimeout = int64(5 * 60)
+	// MaxFeePercent bounds the bumped fee, as a percentage of the original fee.
+	MaxFeePercent = int64(1000)
+)
+
+// A FeeBump tracks the unconfirmed transaction of one side of a swap, so that it
+// can be replaced by a transaction that pays a higher fee. It is shared between
+// the swapper, which decides when to bump the fee, and the contract binder,
+// which records the transactions it broadcasts. It is stored with the swap, so
+// that the transaction is still replaced after a restart.
+type FeeBump struct {
+	Bumps   int      `json:"bumps"`
+	Percent int64    `json:"percent"`
+	Fee     *big.Int `json:"fee,omitempty"`
+	Nonce   uint64   `json:"nonce,omitempty"`
+	Since   int64    `json:"since,omitempty"`
+
+	// TxHash is the hash of the unconfirmed transaction, and Replaced are the
+	// hashes of the transactions that it replaced. Any of them can still be
+	// confirmed.
+	TxHash   string   `json:"txHash,omitempty"`
+	Replaced []string `json:"replaced,omitempty"`
+
+	// Spent are the outputs that are spent by the unconfirmed transaction of a
+	// bitcoin-like blockchain. Its replacements spend them again.
+	Spent []SpentOutput `json:"spent,omitempty"`
+}
+
+// A SpentOutput is an output of a bitcoin-like transaction that is spent by an
+// unconfirmed transaction.
+type SpentOutput struct {
+	TxHash string `json:"txHash"`
+	Vout   uint32 `json:"vout"`
+	Amount int64  `json:"amount"`
+}
+
+// NewFeeBump returns a FeeBump without an unconfirmed transaction.
+func NewFeeBump() *FeeBump {
+	return &FeeBump{Percent: 100}
+}
+
+// Generate is used to create random values for testing. The values have no
+// unconfirmed transaction.
+func (*FeeBump) Generate(rand *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(NewFeeBump())
+}
+
+// Copy returns a deep copy of the fee bump, so that it can be stored while the
+// original is still being updated.
+func (bump *FeeBump) Copy() *FeeBump {
+	if bump == nil {
+		return nil
+	}
+	copied := *bump
+	if bump.Fee != nil {
+		copied.Fee = new(big.Int).Set(bump.Fee)
+	}
+	copied.Replaced = append([]string(nil), bump.Replaced...)
+	copied.Spent = append([]SpentOutput(nil), bump.Spent...)
+	return &copied
+}
+
+// Pending returns true if a transaction has been broadcast and has not been
+// confirmed.
+func (bump *FeeBump) Pending() bool {
+	return bump != nil && bump.Fee != nil
+}
+
+// Bumped returns true if the unconfirmed transaction pays less than the fee,
+// and is replaced by the next transaction.
+func (bump *FeeBump) Bumped(fee *big.Int) bool {
+	return bump.Pending() && bump.Fee.Cmp(fee) < 0
+}
+
+// Broadcast records the hash, fee and nonce of a broadcast transaction. The
+// nonce is only used by ethereum transactions. A transaction that is broadcast
+// while another one is pending replaces it.
+func (bump *FeeBump) Broadcast(txHash string, fee *big.Int, nonce uint64, now int64) {
+	if bump == nil {
+		return
+	}
+	if bump.Pending() {
+		if bump.TxHash != "" && bump.TxHash != txHash {
+			bump.Replaced = append(bump.Replaced, bump.TxHash)
+		}
+	} else {
+		bump.Since = now
+	}
+	bump.TxHash = txHash
+	bump.Fee = new(big.Int).Set(fee)
+	bump.Nonce = nonce
+}
+
+// TxHashes returns the hashes of the unconfirmed transaction, and of the
+// transactions that it replaced.
+func (bump *FeeBump) TxHashes() []string {
+	if !bump.Pending() || bump.TxHash == "" {
+		return nil
+	}
+	return append([]string{bump.TxHash}, bump.Replaced...)
+}
+
+// Confirmed forgets the unconfirmed transaction, so that the next transaction
+// is broadcast with the original fee.
+func (bump *FeeBump) Confirmed() {
+	if bump == nil {
+		return
+	}
+	*bump = *NewFeeBump()
+}
+
+// Reset forgets the unconfirmed transaction when the next transaction does not
+// replace it, because it was dropped by the blockchain. The fee stays bumped.
+func (bump *FeeBump) Reset() {
+	if bump == nil {
+		return
+	}
+	bump.Fee, bump.Nonce, bump.Since = nil, 0, 0
+	bump.TxHash, bump.Replaced, bump.Spent = "", nil, nil
+}
+
+// Stalled returns true if the unconfirmed transaction should be replaced.
+// Stalled transactions are detected sooner as the timelock approaches.
+func (bump *FeeBump) Stalled(now, timeLock int64) bool {
+	if !bump.Pending() {
+		return false
+	}
+	timeout := StallTimeout
+	if timeLock-now < ExpiryUnit {
+		timeout = UrgentStallTimeout
+	}
+	return now-bump.Since >= timeout
+}
+
+// Bump increases the fee of the next transaction by a quarter, or doubles it
+// when the timelock is less than an ExpiryUnit away.
+func (bump *FeeBump) Bump(now, timeLock int64) {
+	if bump == nil {
+		return
+	}
+	step := int64(25)
+	if timeLock-now < ExpiryUnit {
+		step = 100
+	}
+	bump.Bumps++
+	bump.Percent = bump.percent() * (100 + step) / 100
+	if bump.Percent > MaxFeePercent {
+		bump.Percent = MaxFeePercent
+	}
+	bump.Since = now
+}
+
+// Apply returns the fee increased by the bumps.
+func (bump *FeeBump) Apply(fee *big.Int) *big.Int {
+	if bump == nil || fee == nil {
+		return fee
+	}
+	return new(big.Int).Div(new(big.Int).Mul(fee, big.NewInt(bump.percent())), big.NewInt(100))
+}
+
+func (bump *FeeBump) percent() int64 {
+	if bump.Percent < 100 {
+		return 100
+	}
+	return bump.Percent
+}
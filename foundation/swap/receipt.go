@@ -9,20 +9,24 @@ import (
 
 // The SwapReceipt contains the swap details and the status.
 type SwapReceipt struct {
-	ID            SwapID               `json:"id"`
-	SendToken     blockchain.TokenName `json:"sendToken"`
-	ReceiveToken  blockchain.TokenName `json:"receiveToken"`
-	SendAmount    string               `json:"sendAmount"`
-	ReceiveAmount string               `json:"receiveAmount"`
-	SendCost      blockchain.CostBlob  `json:"sendCost"`
-	ReceiveCost   blockchain.CostBlob  `json:"receiveCost"`
-	Timestamp     int64                `json:"timestamp"`
-	TimeLock      int64                `json:"timeLock"`
-	Status        int                  `json:"status"`
-	Delay         bool                 `json:"delay"`
-	DelayInfo     json.RawMessage      `json:"delayInfo,omitempty"`
-	Active        bool                 `json:"active"`
-	PasswordHash  string               `json:"passwordHash,omitempty"`
+	ID                    SwapID               `json:"id"`
+	SendToken             blockchain.TokenName `json:"sendToken"`
+	ReceiveToken          blockchain.TokenName `json:"receiveToken"`
+	SendAmount            string               `json:"sendAmount"`
+	ReceiveAmount         string               `json:"receiveAmount"`
+	SendCost              blockchain.CostBlob  `json:"sendCost"`
+	ReceiveCost           blockchain.CostBlob  `json:"receiveCost"`
+	Timestamp             int64                `json:"timestamp"`
+	TimeLock              int64                `json:"timeLock"`
+	Status                int                  `json:"status"`
+	Confirmations         int64                `json:"confirmations,omitempty"`
+	RequiredConfirmations int64                `json:"requiredConfirmations,omitempty"`
+	Delay                 bool                 `json:"delay"`
+	DelayInfo             json.RawMessage      `json:"delayInfo,omitempty"`
+	Active                bool                 `json:"active"`
+	CancelError           string               `json:"cancelError,omitempty"`
+	NotifyURL             string               `json:"notifyUrl,omitempty"`
+	PasswordHash          string               `json:"passwordHash,omitempty"`
 }
 
 // NewSwapReceipt returns a SwapReceipt from a swapBlob.
@@ -41,10 +45,23 @@ func NewSwapReceipt(blob SwapBlob) SwapReceipt {
 		Delay:         blob.Delay,
 		DelayInfo:     blob.DelayInfo,
 		Active:        true,
+		NotifyURL:     blob.NotifyURL,
 		PasswordHash:  blob.PasswordHash,
 	}
 }
 
+// A StatusUpdate records the time at which a swap moved into a status.
+type StatusUpdate struct {
+	Status    int   `json:"status"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+// NewStatusUpdate returns a StatusUpdate for the given status and unix
+// timestamp.
+func NewStatusUpdate(status int, timestamp int64) StatusUpdate {
+	return StatusUpdate{status, timestamp}
+}
+
 type ReceiptUpdate struct {
 	ID     SwapID
 	Update func(receipt *SwapReceipt)
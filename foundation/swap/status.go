@@ -12,4 +12,21 @@ const (
 	RefundFailed
 	Cancelled
 	Expired
+	AuditConfirming
 )
+
+// Cancellable returns true if a swap with the status may not have been funded
+// on our side yet. The swaps that we initiate are funded as soon as they leave
+// the inactive status, while the swaps that we respond to are only funded after
+// the contract of the peer has been audited.
+func Cancellable(blob SwapBlob, status int) bool {
+	if blob.Delay || blob.ShouldInitiateFirst {
+		return status == Inactive
+	}
+	switch status {
+	case Inactive, AuditPending, AuditFailed, AuditConfirming, Audited:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,35 @@
+package swap_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/foundation/swap"
+)
+
+var _ = Describe("Swap Status", func() {
+	Context("when checking if a swap can be cancelled", func() {
+		It("should only cancel the swaps that we initiate before they are funded", func() {
+			blob := SwapBlob{ShouldInitiateFirst: true}
+			Expect(Cancellable(blob, Inactive)).Should(BeTrue())
+			for _, status := range []int{Initiated, AuditPending, Audited, AuditConfirming, Redeemed, Refunded, Cancelled, Expired} {
+				Expect(Cancellable(blob, status)).Should(BeFalse())
+			}
+		})
+
+		It("should cancel the swaps that we respond to until we fund them", func() {
+			blob := SwapBlob{}
+			for _, status := range []int{Inactive, AuditPending, AuditFailed, AuditConfirming, Audited} {
+				Expect(Cancellable(blob, status)).Should(BeTrue())
+			}
+			for _, status := range []int{Initiated, AuditedSecret, RefundFailed, Redeemed, Refunded, Cancelled, Expired} {
+				Expect(Cancellable(blob, status)).Should(BeFalse())
+			}
+		})
+
+		It("should only cancel delayed swaps while they wait for their details", func() {
+			blob := SwapBlob{Delay: true}
+			Expect(Cancellable(blob, Inactive)).Should(BeTrue())
+			Expect(Cancellable(blob, Cancelled)).Should(BeFalse())
+		})
+	})
+})
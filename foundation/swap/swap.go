@@ -12,6 +12,13 @@ import (
 
 const ExpiryUnit = int64(2 * 60 * 60)
 
+// Bitcoin swaps can lock their funds in a legacy P2SH script, or a native
+// segwit P2WSH script. Both parties must use the same script type.
+const (
+	BitcoinScriptP2SH  = "p2sh"
+	BitcoinScriptP2WSH = "p2wsh"
+)
+
 // TODO: Rename to ID
 // A SwapID uniquely identifies a Swap that is being executed.
 type SwapID string
@@ -36,6 +43,9 @@ type Swap struct {
 	WithdrawAddress string
 	FundingAddress  string
 	BrokerAddress   string
+	BitcoinScript   string
+	FeeBump         *FeeBump
+	Confirmations   int64
 }
 
 // A SwapBlob is used to encode a Swap for storage and transmission.
@@ -65,8 +75,37 @@ type SwapBlob struct {
 	BrokerSendTokenAddr    string `json:"brokerSendTokenAddr,omitempty"`
 	BrokerReceiveTokenAddr string `json:"brokerReceiveTokenAddr,omitempty"`
 
+	// BitcoinScript is the type of the bitcoin swap script, it defaults to
+	// BitcoinScriptP2SH.
+	BitcoinScript string `json:"bitcoinScript,omitempty"`
+
+	// NativeTimeLock and ForeignTimeLock are the timelocks of our contract and
+	// of the contract of the peer, and SafetyMargin is the time that must be
+	// left before the earlier of them when we fund our contract. They are set
+	// from the timelock policy of the token pair when the swap is created.
+	NativeTimeLock  int64 `json:"nativeTimeLock,omitempty"`
+	ForeignTimeLock int64 `json:"foreignTimeLock,omitempty"`
+	SafetyMargin    int64 `json:"safetyMargin,omitempty"`
+
+	// AddressIndex is the index of the address that receives the funds of the
+	// swap. It is allocated by the wallet, and is zero for the tokens that are
+	// always received at the same address.
+	AddressIndex uint32 `json:"addressIndex,omitempty"`
+
 	WithdrawAddress string `json:"withdrawAddress,omitempty"`
 	ResponseURL     string `json:"responseURL,omitempty"`
+	NotifyURL       string `json:"notifyUrl,omitempty"`
 	Password        string `json:"password,omitempty"`
 	PasswordHash    string `json:"passwordHash,omitempty"`
+
+	// ForceRefund is set when the swap has been asked to stop progressing and
+	// refund as soon as the timelock allows.
+	ForceRefund bool `json:"forceRefund,omitempty"`
+
+	// SendFeeBump and ReceiveFeeBump track the unconfirmed transactions of our
+	// side and of the peer's side of the swap, so that their fees are still
+	// bumped after a restart. They are never read from or written to the JSON
+	// of a swap, and are stored separately.
+	SendFeeBump    *FeeBump `json:"-"`
+	ReceiveFeeBump *FeeBump `json:"-"`
 }
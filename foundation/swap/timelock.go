@@ -0,0 +1,163 @@
+package swap
+
+import (
+	"fmt"
+
+	"github.com/renproject/swapperd/foundation/blockchain"
+)
+
+// DefaultTimeLockPolicy is used for the token pairs that do not have a policy,
+// and for the fields of a policy that are not set.
+var DefaultTimeLockPolicy = TimeLockPolicy{
+	Duration:     3 * ExpiryUnit,
+	SafetyMargin: 2 * ExpiryUnit,
+}
+
+// A TimeLockPolicy configures the timelocks of the swaps of a token pair. All
+// durations are in seconds.
+type TimeLockPolicy struct {
+	// Tokens is the token pair of the policy, in either order. It is empty for
+	// the default policy.
+	Tokens []blockchain.TokenName `json:"tokens,omitempty"`
+
+	// Duration is the time until the timelock of the swaps that we initiate.
+	Duration int64 `json:"duration,omitempty"`
+
+	// SafetyMargin is the minimum time that must be left before the earliest
+	// timelock of a swap when we fund it.
+	SafetyMargin int64 `json:"safetyMargin,omitempty"`
+
+	// Gap is the time by which the timelock of the responder's contract
+	// precedes the timelock of the initiator's contract. It must be agreed with
+	// the counterparty, and is zero by default.
+	Gap int64 `json:"gap,omitempty"`
+}
+
+// TimeLockPolicies are the timelock policies of the daemon.
+type TimeLockPolicies struct {
+	Default TimeLockPolicy   `json:"default"`
+	Pairs   []TimeLockPolicy `json:"pairs"`
+}
+
+// Policy returns the timelock policy of the token pair, and an error if the
+// policy is not safe for the confirmation times of their blockchains.
+func (policies TimeLockPolicies) Policy(sendToken, receiveToken blockchain.Token) (TimeLockPolicy, error) {
+	policy := policies.Pair(sendToken.Name, receiveToken.Name)
+	return policy, policy.validate(sendToken.Blockchain, receiveToken.Blockchain)
+}
+
+// Pair returns the timelock policy of the token pair, without checking that it
+// is safe.
+func (policies TimeLockPolicies) Pair(sendToken, receiveToken blockchain.TokenName) TimeLockPolicy {
+	policy := policies.Default.withDefaults(DefaultTimeLockPolicy)
+	for _, pair := range policies.Pairs {
+		if pair.matches(sendToken, receiveToken) {
+			policy = pair.withDefaults(policy)
+			break
+		}
+	}
+	policy.Tokens = []blockchain.TokenName{sendToken, receiveToken}
+	return policy
+}
+
+// Validate returns an error if a policy is not for a pair of the tokens, or if
+// the policy of any pair of the tokens is not safe.
+func (policies TimeLockPolicies) Validate(tokens []blockchain.Token) error {
+	known := map[blockchain.TokenName]bool{}
+	for _, token := range tokens {
+		known[token.Name] = true
+	}
+	for _, pair := range policies.Pairs {
+		if len(pair.Tokens) != 2 || !known[pair.Tokens[0]] || !known[pair.Tokens[1]] {
+			return fmt.Errorf("invalid timelock policy: %v is not a pair of supported tokens", pair.Tokens)
+		}
+	}
+	for i := range tokens {
+		for j := i + 1; j < len(tokens); j++ {
+			if _, err := policies.Policy(tokens[i], tokens[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TimeLocks returns the timelocks of the native and the foreign contracts of a
+// swap with the timelock.
+func (policy TimeLockPolicy) TimeLocks(timeLock int64, shouldInitiateFirst bool) (native, foreign int64) {
+	if shouldInitiateFirst {
+		return timeLock, timeLock - policy.Gap
+	}
+	return timeLock - policy.Gap, timeLock
+}
+
+// SetTimeLocks stores the timelocks of the contracts of the swap, and the
+// safety margin of the policy, on the swap. Later changes to the policy do not
+// affect the swap.
+func (policy TimeLockPolicy) SetTimeLocks(blob SwapBlob) SwapBlob {
+	blob.NativeTimeLock, blob.ForeignTimeLock = policy.TimeLocks(blob.TimeLock, blob.ShouldInitiateFirst)
+	blob.SafetyMargin = policy.SafetyMargin
+	return blob
+}
+
+// VerifyTimeLock returns an error if the earliest timelock of a swap with the
+// timelock is less than the safety margin away.
+func (policy TimeLockPolicy) VerifyTimeLock(now, timeLock int64) error {
+	if earliest := timeLock - policy.Gap; now+policy.SafetyMargin > earliest {
+		return NewErrUnsafeTimeLock(fmt.Errorf("%d seconds left before the timelock, %d seconds are required", earliest-now, policy.SafetyMargin))
+	}
+	return nil
+}
+
+func (policy TimeLockPolicy) matches(sendToken, receiveToken blockchain.TokenName) bool {
+	if len(policy.Tokens) != 2 {
+		return false
+	}
+	return (policy.Tokens[0] == sendToken && policy.Tokens[1] == receiveToken) ||
+		(policy.Tokens[0] == receiveToken && policy.Tokens[1] == sendToken)
+}
+
+func (policy TimeLockPolicy) withDefaults(defaults TimeLockPolicy) TimeLockPolicy {
+	if policy.Duration == 0 {
+		policy.Duration = defaults.Duration
+	}
+	if policy.SafetyMargin == 0 {
+		policy.SafetyMargin = defaults.SafetyMargin
+	}
+	if policy.Gap == 0 {
+		policy.Gap = defaults.Gap
+	}
+	return policy
+}
+
+// validate returns an error if both contracts cannot be funded and confirmed
+// within the safety margin, if the responder cannot redeem within the gap, or
+// if the swaps that we initiate would be rejected by the safety margin.
+func (policy TimeLockPolicy) validate(sendChain, receiveChain blockchain.BlockchainName) error {
+	if policy.Duration < 0 || policy.SafetyMargin < 0 || policy.Gap < 0 {
+		return NewErrUnsafeTimeLock(fmt.Errorf("negative durations in the policy of %v", policy.Tokens))
+	}
+	if confirmations := sendChain.ConfirmationTime() + receiveChain.ConfirmationTime(); policy.SafetyMargin < confirmations {
+		return NewErrUnsafeTimeLock(fmt.Errorf("safety margin of %v is %d seconds, confirmations take %d seconds", policy.Tokens, policy.SafetyMargin, confirmations))
+	}
+	if confirmations := maxInt64(sendChain.ConfirmationTime(), receiveChain.ConfirmationTime()); policy.Gap != 0 && policy.Gap < confirmations {
+		return NewErrUnsafeTimeLock(fmt.Errorf("gap of %v is %d seconds, confirmations take %d seconds", policy.Tokens, policy.Gap, confirmations))
+	}
+	if policy.Duration <= policy.SafetyMargin+policy.Gap {
+		return NewErrUnsafeTimeLock(fmt.Errorf("duration of %v is %d seconds, at least %d seconds are required", policy.Tokens, policy.Duration, policy.SafetyMargin+policy.Gap+1))
+	}
+	return nil
+}
+
+// NewErrUnsafeTimeLock returns an error for a swap with a timelock that is not
+// safe.
+func NewErrUnsafeTimeLock(err error) error {
+	return fmt.Errorf("unsafe timelock: %v", err)
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
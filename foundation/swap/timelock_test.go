@@ -0,0 +1,113 @@
+package swap_test
+
+import (
+	"github.com/renproject/swapperd/foundation/blockchain"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/swapperd/foundation/swap"
+)
+
+var _ = Describe("Timelock Policies", func() {
+	Context("when resolving the policy of a token pair", func() {
+		It("should use the default policy without a config", func() {
+			policy, err := TimeLockPolicies{}.Policy(blockchain.TokenBTC, blockchain.TokenETH)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(policy.Duration).Should(Equal(DefaultTimeLockPolicy.Duration))
+			Expect(policy.SafetyMargin).Should(Equal(DefaultTimeLockPolicy.SafetyMargin))
+			Expect(policy.Gap).Should(Equal(int64(0)))
+		})
+
+		It("should use the policy of the pair in either order", func() {
+			policies := TimeLockPolicies{
+				Default: TimeLockPolicy{Duration: 8 * 60 * 60},
+				Pairs: []TimeLockPolicy{
+					{Tokens: []blockchain.TokenName{blockchain.ETH, blockchain.DAI}, SafetyMargin: 30 * 60},
+				},
+			}
+			for _, pair := range [][2]blockchain.Token{{blockchain.TokenETH, blockchain.TokenDAI}, {blockchain.TokenDAI, blockchain.TokenETH}} {
+				policy, err := policies.Policy(pair[0], pair[1])
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(policy.Duration).Should(Equal(int64(8 * 60 * 60)))
+				Expect(policy.SafetyMargin).Should(Equal(int64(30 * 60)))
+			}
+
+			policy, err := policies.Policy(blockchain.TokenBTC, blockchain.TokenETH)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(policy.SafetyMargin).Should(Equal(DefaultTimeLockPolicy.SafetyMargin))
+		})
+
+		It("should reject policies that are unsafe for the blockchains", func() {
+			policies := TimeLockPolicies{
+				Pairs: []TimeLockPolicy{
+					{Tokens: []blockchain.TokenName{blockchain.BTC, blockchain.ETH}, SafetyMargin: 30 * 60},
+					{Tokens: []blockchain.TokenName{blockchain.BTC, blockchain.LTC}, Gap: 60},
+					{Tokens: []blockchain.TokenName{blockchain.BTC, blockchain.BCH}, Duration: 60 * 60},
+				},
+			}
+			_, err := policies.Policy(blockchain.TokenBTC, blockchain.TokenETH)
+			Expect(err).Should(HaveOccurred())
+			_, err = policies.Policy(blockchain.TokenLTC, blockchain.TokenBTC)
+			Expect(err).Should(HaveOccurred())
+			_, err = policies.Policy(blockchain.TokenBTC, blockchain.TokenBCH)
+			Expect(err).Should(HaveOccurred())
+
+			// The same margin is safe for faster blockchains.
+			_, err = TimeLockPolicies{Default: TimeLockPolicy{SafetyMargin: 30 * 60}}.Policy(blockchain.TokenETH, blockchain.TokenDAI)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("when validating the policies", func() {
+		tokens := []blockchain.Token{blockchain.TokenBTC, blockchain.TokenETH, blockchain.TokenLTC}
+
+		It("should accept safe policies", func() {
+			policies := TimeLockPolicies{
+				Pairs: []TimeLockPolicy{
+					{Tokens: []blockchain.TokenName{blockchain.ETH, blockchain.LTC}, SafetyMargin: 30 * 60},
+				},
+			}
+			Expect(policies.Validate(tokens)).ShouldNot(HaveOccurred())
+		})
+
+		It("should reject unsafe policies and policies of unknown pairs", func() {
+			unsafe := TimeLockPolicies{Default: TimeLockPolicy{SafetyMargin: 30 * 60}}
+			Expect(unsafe.Validate(tokens)).Should(HaveOccurred())
+
+			unknown := TimeLockPolicies{
+				Pairs: []TimeLockPolicy{
+					{Tokens: []blockchain.TokenName{blockchain.ETH, blockchain.BCH}},
+				},
+			}
+			Expect(unknown.Validate(tokens)).Should(HaveOccurred())
+		})
+	})
+
+	Context("when calculating timelocks", func() {
+		policy := TimeLockPolicy{Duration: 48 * 60 * 60, SafetyMargin: 4 * 60 * 60, Gap: 24 * 60 * 60}
+
+		It("should give the responder's contract the earlier timelock", func() {
+			native, foreign := policy.TimeLocks(100000, true)
+			Expect(native).Should(Equal(int64(100000)))
+			Expect(foreign).Should(Equal(int64(100000 - 24*60*60)))
+
+			native, foreign = policy.TimeLocks(100000, false)
+			Expect(native).Should(Equal(int64(100000 - 24*60*60)))
+			Expect(foreign).Should(Equal(int64(100000)))
+		})
+
+		It("should store the timelocks of the contracts on the swap", func() {
+			blob := policy.SetTimeLocks(SwapBlob{TimeLock: 100000})
+			Expect(blob.NativeTimeLock).Should(Equal(int64(100000 - 24*60*60)))
+			Expect(blob.ForeignTimeLock).Should(Equal(int64(100000)))
+			Expect(blob.SafetyMargin).Should(Equal(policy.SafetyMargin))
+		})
+
+		It("should reject timelocks within the safety margin of the earliest timelock", func() {
+			now := int64(1000000)
+			Expect(policy.VerifyTimeLock(now, now+policy.Duration)).ShouldNot(HaveOccurred())
+			Expect(policy.VerifyTimeLock(now, now+policy.Gap+policy.SafetyMargin)).ShouldNot(HaveOccurred())
+			Expect(policy.VerifyTimeLock(now, now+policy.Gap+policy.SafetyMargin-1)).Should(HaveOccurred())
+		})
+	})
+})
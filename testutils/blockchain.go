@@ -46,8 +46,23 @@ func (bc *MockBlockchain) Transfer(password string, token blockchain.Token, to s
 	return "", nil
 }
 
+func (bc *MockBlockchain) TransferWithCost(password string, token blockchain.Token, to string, amount *big.Int, cost blockchain.Cost) (string, error) {
+	return "", nil
+}
+
+func (bc *MockBlockchain) BatchTransfer(password string, token blockchain.Token, recipients []transfer.Recipient) ([]string, error) {
+	return make([]string, len(recipients)), nil
+}
+
+// Lookup returns an update that confirms the transfer.
 func (bc *MockBlockchain) Lookup(token blockchain.Token, txHash string) (transfer.UpdateReceipt, error) {
-	return transfer.UpdateReceipt{}, nil
+	return transfer.NewUpdateReceipt(txHash, func(receipt *transfer.TransferReceipt) {
+		receipt.Confirmations = token.Blockchain.DefaultConfirmations()
+	}), nil
+}
+
+func (bc *MockBlockchain) Confirmations(blockchainName blockchain.BlockchainName) int64 {
+	return blockchainName.DefaultConfirmations()
 }
 
 type FaultyBlockchain struct {
@@ -3,21 +3,35 @@ package testutils
 import (
 	"errors"
 	"sync"
+	"time"
 
+	"github.com/renproject/swapperd/adapter/auth"
 	"github.com/renproject/swapperd/core/wallet/transfer"
+	"github.com/renproject/swapperd/foundation/blockchain"
 	"github.com/renproject/swapperd/foundation/swap"
 )
 
 type MockStorage struct {
 	mu        *sync.RWMutex
 	receipts  map[swap.SwapID]swap.SwapReceipt
-	transfers map[string]transfer.TransferReceipt
+	swaps     map[swap.SwapID]swap.SwapBlob
+	history   map[swap.SwapID][]swap.StatusUpdate
+	transfers map[transfer.TransferID]transfer.TransferReceipt
+	tokens    map[string]auth.Token
+	indices   map[string]uint32
+	quotes    map[swap.SwapID]bool
 }
 
 func NewMockStorage() *MockStorage {
 	return &MockStorage{
-		mu:       new(sync.RWMutex),
-		receipts: map[swap.SwapID]swap.SwapReceipt{},
+		mu:        new(sync.RWMutex),
+		receipts:  map[swap.SwapID]swap.SwapReceipt{},
+		swaps:     map[swap.SwapID]swap.SwapBlob{},
+		history:   map[swap.SwapID][]swap.StatusUpdate{},
+		transfers: map[transfer.TransferID]transfer.TransferReceipt{},
+		tokens:    map[string]auth.Token{},
+		indices:   map[string]uint32{},
+		quotes:    map[swap.SwapID]bool{},
 	}
 }
 
@@ -25,7 +39,30 @@ func (store *MockStorage) Receipt(id swap.SwapID) (swap.SwapReceipt, error) {
 	store.mu.RLock()
 	defer store.mu.RUnlock()
 
-	return store.receipts[id], nil
+	receipt, ok := store.receipts[id]
+	if !ok {
+		return swap.SwapReceipt{}, errors.New("swap not found")
+	}
+	return receipt, nil
+}
+
+func (store *MockStorage) PutSwap(blob swap.SwapBlob) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.swaps[blob.ID] = blob
+	return nil
+}
+
+func (store *MockStorage) PendingSwap(id swap.SwapID) (swap.SwapBlob, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	blob, ok := store.swaps[id]
+	if !ok {
+		return swap.SwapBlob{}, errors.New("swap not found")
+	}
+	return blob, nil
 }
 
 func (store *MockStorage) Receipts() ([]swap.SwapReceipt, error) {
@@ -45,6 +82,7 @@ func (store *MockStorage) PutReceipt(receipt swap.SwapReceipt) error {
 	defer store.mu.Unlock()
 
 	store.receipts[receipt.ID] = receipt
+	store.history[receipt.ID] = []swap.StatusUpdate{swap.NewStatusUpdate(receipt.Status, receipt.Timestamp)}
 	return nil
 }
 
@@ -57,19 +95,54 @@ func (store *MockStorage) UpdateReceipt(update swap.ReceiptUpdate) error {
 		return errors.New("swap not found")
 	}
 
+	status := receipt.Status
 	update.Update(&receipt)
 	store.receipts[update.ID] = receipt
+	if receipt.Status != status {
+		store.history[update.ID] = append(store.history[update.ID], swap.NewStatusUpdate(receipt.Status, time.Now().Unix()))
+	}
 	return nil
 }
 
+func (store *MockStorage) SwapHistory(id swap.SwapID) ([]swap.StatusUpdate, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return append([]swap.StatusUpdate{}, store.history[id]...), nil
+}
+
 func (store *MockStorage) PutTransfer(receipt transfer.TransferReceipt) error {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
-	store.transfers[receipt.TxHash] = receipt
+	store.transfers[receipt.ID] = receipt
 	return nil
 }
 
+func (store *MockStorage) UpdateTransferReceipt(update transfer.UpdateReceipt) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	receipt, ok := store.transfers[update.ID]
+	if !ok {
+		return errors.New("transfer not found")
+	}
+	update.Update(&receipt)
+	store.transfers[update.ID] = receipt
+	return nil
+}
+
+func (store *MockStorage) Transfer(id transfer.TransferID) (transfer.TransferReceipt, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	receipt, ok := store.transfers[id]
+	if !ok {
+		return transfer.TransferReceipt{}, errors.New("transfer not found")
+	}
+	return receipt, nil
+}
+
 func (store *MockStorage) Transfers() ([]transfer.TransferReceipt, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
@@ -81,3 +154,76 @@ func (store *MockStorage) Transfers() ([]transfer.TransferReceipt, error) {
 
 	return transfers, nil
 }
+
+func (store *MockStorage) PutToken(token auth.Token) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.tokens[token.Hash] = token
+	return nil
+}
+
+func (store *MockStorage) Token(hash string) (auth.Token, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	token, ok := store.tokens[hash]
+	if !ok {
+		return auth.Token{}, errors.New("api token not found")
+	}
+	return token, nil
+}
+
+func (store *MockStorage) Tokens() ([]auth.Token, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	tokens := []auth.Token{}
+	for _, token := range store.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (store *MockStorage) DeleteToken(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for hash, token := range store.tokens {
+		if token.ID == id {
+			delete(store.tokens, hash)
+			return nil
+		}
+	}
+	return errors.New("api token not found")
+}
+
+func (store *MockStorage) AddressIndex(walletID string, chain blockchain.BlockchainName) (uint32, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.indices[walletID+string(chain)], nil
+}
+
+func (store *MockStorage) PutAddressIndex(walletID string, chain blockchain.BlockchainName, index uint32) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.indices[walletID+string(chain)] = index
+	return nil
+}
+
+func (store *MockStorage) PutAcceptedQuote(id swap.SwapID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.quotes[id] = true
+	return nil
+}
+
+func (store *MockStorage) QuoteAccepted(id swap.SwapID) (bool, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.quotes[id], nil
+}